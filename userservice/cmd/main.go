@@ -3,12 +3,14 @@ package main
 import (
 	"log"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq"
 
+	"users/internal/authz"
 	"users/internal/config"
 	"users/internal/handler"
 	"users/internal/middleware"
@@ -26,13 +28,33 @@ func main() {
 	}
 	defer db.Close()
 
+	// คำสั่ง `migrate up|down|status` (จัดการ Schema แล้วจบการทำงาน ไม่ start server)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(db, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	userService := service.NewUserService(userRepo, refreshTokenRepo, repository.NewUserTokenRepository(db), repository.NewIdentityRepository(db),
+		service.NewOutboxMailer(notificationRepo), cfg.FrontendURL, cfg.RequireEmailVerification)
 	userHandler := handler.NewUserHandler(userService)
+	oidcHandler := handler.NewOIDCHandler(userService, newOIDCProviders(cfg), cfg.FrontendURL)
+
+	// RBAC: สิทธิ์ที่แต่ละ Route ต้องการ (ดู internal/authz/policy.go)
+	policy := authz.DefaultPolicy()
+	// Ownership: ตรวจว่าเป็นเจ้าของ client/program/schedule ใน :id (และ client_id ใน Body) ก่อนเข้า Handler
+	owns := middleware.NewOwnershipGuard(policy, repository.NewOwnershipRepository(db))
 
 	// สร้าง Dependencies ใหม่
 	trainingRepo := repository.NewTrainingRepository(db)
-	trainingHandler := handler.NewTrainingHandler(trainingRepo)
+	scheduleBuffer := time.Duration(cfg.ScheduleBufferMinutes) * time.Minute
+	recordRebuilder := service.NewRecordRebuilder(repository.NewSessionRepository(db), repository.NewPersonalRecordRepository(db))
+	scheduleService := service.NewScheduleService(repository.NewScheduleRepository(db), recordRebuilder, scheduleBuffer)
+	trainingHandler := handler.NewTrainingHandler(trainingRepo, scheduleService, owns)
 
 	// --- Init Dashboard Components
 	dashboardRepo := repository.NewDashboardRepository(db)
@@ -41,11 +63,47 @@ func main() {
 	clientRepo := repository.NewClientRepository(db)
 	clientHandler := handler.NewClientHandler(clientRepo, userService)
 
-	sessionRepo := repository.NewSessionRepository(db)
-	sessionHandler := handler.NewSessionHandler(sessionRepo)
+	invitationRepo := repository.NewInvitationRepository(db)
+	invitationService := service.NewInvitationService(invitationRepo, clientRepo, userService)
+	invitationHandler := handler.NewInvitationHandler(invitationService, cfg.FrontendURL)
 
 	programRepo := repository.NewProgramRepository(db)
-	programHandler := handler.NewProgramHandler(programRepo)
+	programHandler := handler.NewProgramHandler(programRepo, service.NewProgramService(programRepo, repository.NewPersonalRecordRepository(db)), owns)
+
+	exerciseRepo := repository.NewExerciseRepository(db)
+	exerciseHandler := handler.NewExerciseHandler(exerciseRepo, policy)
+	searchHandler := handler.NewSearchHandler(service.NewSearchService(repository.NewSearchRepository(db)), policy)
+
+	sessionRepo := repository.NewSessionRepository(db)
+	recordRepo := repository.NewPersonalRecordRepository(db)
+	sessionService := service.NewSessionService(sessionRepo, exerciseRepo, recordRepo, programRepo)
+	sessionHandler := handler.NewSessionHandler(sessionRepo, sessionService, scheduleService, owns)
+	measurementRepo := repository.NewMeasurementRepository(db)
+	measurementHandler := handler.NewMeasurementHandler(measurementRepo, service.NewMeasurementService(measurementRepo))
+	analyticsHandler := handler.NewAnalyticsHandler(service.NewAnalyticsService(sessionRepo, cfg.CalendarTimezone), recordRepo)
+
+	assignmentService := service.NewAssignmentService(repository.NewAssignmentRepository(db))
+	assignmentHandler := handler.NewAssignmentHandler(assignmentService)
+	// ตรวจงานมอบหมายที่เลย due_date เป็นระยะ แล้วเปลี่ยนเป็น overdue
+	if cfg.AssignmentOverdueCheckMinutes > 0 {
+		stopOverdueChecker := service.StartOverdueChecker(assignmentService, time.Duration(cfg.AssignmentOverdueCheckMinutes)*time.Minute)
+		defer stopOverdueChecker()
+	}
+
+	// การแจ้งเตือน: Dispatcher ส่ง outbox และ Scanner เพิ่มการเตือนนัด/งานที่ใกล้ถึงเวลา
+	notificationService, err := newNotificationService(cfg, notificationRepo)
+	if err != nil {
+		log.Fatalf("Failed to set up notifications: %v", err)
+	}
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	if cfg.NotifyDispatchSeconds > 0 && cfg.ReminderScanMinutes > 0 {
+		stopNotifications := service.StartNotificationWorkers(notificationService,
+			time.Duration(cfg.NotifyDispatchSeconds)*time.Second, time.Duration(cfg.ReminderScanMinutes)*time.Minute)
+		defer stopNotifications()
+	}
+
+	calendarService := service.NewCalendarService(repository.NewCalendarFeedRepository(db), trainingRepo, cfg.UIDDomain(), cfg.CalendarTimezone)
+	calendarHandler := handler.NewCalendarHandler(calendarService, cfg.PublicURL)
 
 	r := gin.Default()
 	// ----------------------------------------------------
@@ -76,49 +134,139 @@ func main() {
 	{
 		authRoutes.POST("/register", userHandler.Register)
 		authRoutes.POST("/login", userHandler.Login)
+		authRoutes.POST("/refresh", userHandler.Refresh)
 		authRoutes.POST("/logout", userHandler.Logout)
-		authRoutes.GET("/google/login", userHandler.GoogleLogin)
-		authRoutes.GET("/google/callback", userHandler.GoogleCallback)
+		authRoutes.POST("/verify-email", userHandler.VerifyEmail)
+		authRoutes.POST("/verify-email/resend", userHandler.ResendVerification)
+		authRoutes.POST("/forgot-password", userHandler.ForgotPassword)
+		authRoutes.POST("/reset-password", userHandler.ResetPassword)
+
+		// Login ผ่านผู้ให้บริการ OIDC (/auth/google/* คือ route เดิมของ Google)
+		authRoutes.GET("/oidc/:provider/login", oidcHandler.Login)
+		authRoutes.GET("/oidc/:provider/callback", oidcHandler.Callback)
+		authRoutes.GET("/google/login", handler.OIDCProviderAlias("google"), oidcHandler.Login)
+		authRoutes.GET("/google/callback", handler.OIDCProviderAlias("google"), oidcHandler.Callback)
+
+		// Client Portal: รับคำเชิญและสมัครบัญชี role = client
+		authRoutes.GET("/invitations/:token", invitationHandler.GetInvitation)
+		authRoutes.POST("/invitations/:token/accept", invitationHandler.AcceptInvitation)
+	}
+
+	// ICS Subscription Feed (Public: ยืนยันตัวตนด้วย token ในลิงก์)
+	r.GET("/calendar/:token", calendarHandler.ServeFeed)
+
+	can := func(perms ...authz.Permission) gin.HandlerFunc {
+		return middleware.RequirePermission(policy, perms...)
 	}
 
 	apiV1 := r.Group("/api/v1")
 	apiV1.Use(middleware.JWTCookieAuth())
 	{
-		apiV1.DELETE("/users/:id", userHandler.DeleteUser)
-		apiV1.PUT("/users/:id", userHandler.UpdateUser)
+		apiV1.DELETE("/users/:id", can(authz.PermUsersManage), userHandler.DeleteUser)
+		apiV1.PUT("/users/:id", middleware.RequireSelfOrPermission(policy, "id", authz.PermUsersManage), userHandler.UpdateUser)
 		apiV1.GET("/auth/me", userHandler.CheckAuth)
-		apiV1.GET("/users", userHandler.GetAllUsers)
-		apiV1.GET("/users/:id", userHandler.GetUserByID)
+		apiV1.GET("/users", can(authz.PermUsersRead), userHandler.GetAllUsers)
+		apiV1.GET("/users/:id", middleware.RequireSelfOrPermission(policy, "id", authz.PermUsersRead), userHandler.GetUserByID)
 		// Training Routes (เพิ่มใหม่)
 
-		apiV1.GET("/schedules", trainingHandler.GetSchedules)
-		apiV1.POST("/schedules", trainingHandler.CreateSchedule)
-		apiV1.PUT("/schedules/:id", trainingHandler.UpdateSchedule)
-		apiV1.DELETE("/schedules/:id", trainingHandler.DeleteSchedule)
+		apiV1.GET("/schedules", can(authz.PermSchedulesRead), trainingHandler.GetSchedules)
+		apiV1.POST("/schedules", can(authz.PermSchedulesWrite), trainingHandler.CreateSchedule)
+		apiV1.POST("/schedules/series", can(authz.PermSchedulesWrite), trainingHandler.CreateScheduleSeries)
+		apiV1.GET("/schedules/series/:id", can(authz.PermSchedulesRead), owns.Require(authz.ResourceSeries, "id", authz.AccessTrainerOrClient), trainingHandler.GetScheduleSeries)
+		apiV1.PUT("/schedules/:id", can(authz.PermSchedulesWrite), owns.Require(authz.ResourceSchedule, "id", authz.AccessTrainer), trainingHandler.UpdateSchedule)
+		apiV1.DELETE("/schedules/:id", can(authz.PermSchedulesWrite), owns.Require(authz.ResourceSchedule, "id", authz.AccessTrainer), trainingHandler.DeleteSchedule)
+
+		// ลิงก์ Subscribe ปฏิทิน (ICS) ของผู้ใช้ที่ Login อยู่
+		apiV1.GET("/calendar/feed", can(authz.PermSchedulesRead), calendarHandler.GetFeed)
+		apiV1.POST("/calendar/feed", can(authz.PermSchedulesRead), calendarHandler.CreateFeed)
+		apiV1.DELETE("/calendar/feed", can(authz.PermSchedulesRead), calendarHandler.DeleteFeed)
+
+		// Inbox และการตั้งค่าการแจ้งเตือนของผู้ใช้ที่ Login อยู่ (ทุก Role)
+		apiV1.GET("/notifications", notificationHandler.GetNotifications)
+		apiV1.POST("/notifications/read-all", notificationHandler.MarkAllRead)
+		apiV1.POST("/notifications/:id/read", notificationHandler.MarkRead)
+		apiV1.GET("/notifications/preferences", notificationHandler.GetPreferences)
+		apiV1.PUT("/notifications/preferences", notificationHandler.UpdatePreferences)
+
+		apiV1.GET("/assignments", can(authz.PermAssignmentsRead), trainingHandler.GetAssignments)
+		apiV1.POST("/assignments", can(authz.PermAssignmentsWrite), trainingHandler.CreateAssignment)
+		apiV1.GET("/assignments/:id", can(authz.PermAssignmentsRead), owns.Require(authz.ResourceAssignment, "id", authz.AccessTrainerOrClient), assignmentHandler.GetAssignment)
+		apiV1.PUT("/assignments/:id", can(authz.PermAssignmentsWrite), owns.Require(authz.ResourceAssignment, "id", authz.AccessTrainer), trainingHandler.UpdateAssignment)
+		apiV1.DELETE("/assignments/:id", can(authz.PermAssignmentsWrite), owns.Require(authz.ResourceAssignment, "id", authz.AccessTrainer), trainingHandler.DeleteAssignment)
+		// ขั้นตอนงาน: pending -> in_progress -> submitted -> reviewed (overdue ตั้งโดยตัวตรวจอัตโนมัติ)
+		apiV1.POST("/assignments/:id/start", can(authz.PermAssignmentsSubmit), owns.Require(authz.ResourceAssignment, "id", authz.AccessTrainerOrClient), assignmentHandler.StartAssignment)
+		apiV1.POST("/assignments/:id/submit", can(authz.PermAssignmentsSubmit), owns.Require(authz.ResourceAssignment, "id", authz.AccessTrainerOrClient), assignmentHandler.SubmitAssignment)
+		apiV1.POST("/assignments/:id/review", can(authz.PermAssignmentsWrite), owns.Require(authz.ResourceAssignment, "id", authz.AccessTrainer), assignmentHandler.ReviewAssignment)
+
+		apiV1.GET("/dashboard/stats", can(authz.PermDashboardRead), dashboardHandler.GetDashboardStats)
+
+		// ค้นหาลูกค้า บันทึก โปรแกรม และท่าฝึก (เฉพาะชนิดที่ Role มีสิทธิ์อ่าน ตรวจใน Handler)
+		apiV1.GET("/search", searchHandler.Search)
+
+		apiV1.GET("/clients", can(authz.PermClientsRead), trainingHandler.GetClients)
+		apiV1.POST("/clients", can(authz.PermClientsWrite), trainingHandler.CreateClient)
 
-		apiV1.GET("/assignments", trainingHandler.GetAssignments)
-		apiV1.POST("/assignments", trainingHandler.CreateAssignment)
-		apiV1.PUT("/assignments/:id", trainingHandler.UpdateAssignment)
-		apiV1.DELETE("/assignments/:id", trainingHandler.DeleteAssignment)
+		apiV1.GET("/clients/:id/notes", can(authz.PermNotesRead), owns.Require(authz.ResourceClient, "id", authz.AccessTrainer), clientHandler.GetClientNotes)
+		apiV1.POST("/clients/:id/notes", can(authz.PermNotesWrite), owns.Require(authz.ResourceClient, "id", authz.AccessTrainer), clientHandler.CreateClientNote)
+		apiV1.POST("/clients/:id/invitations", can(authz.PermInvitesWrite), owns.Require(authz.ResourceClient, "id", authz.AccessTrainer), invitationHandler.CreateInvitation)
 
-		apiV1.GET("/dashboard/stats", dashboardHandler.GetDashboardStats)
+		apiV1.POST("/sessions", can(authz.PermSchedulesWrite), sessionHandler.CreateSession)
+		apiV1.GET("/clients/:id/sessions", can(authz.PermSessionsRead), owns.Require(authz.ResourceClient, "id", authz.AccessTrainerOrClient), sessionHandler.GetClientSessions)
+		apiV1.GET("/clients/:id/progress", can(authz.PermSessionsRead), owns.Require(authz.ResourceClient, "id", authz.AccessTrainerOrClient), analyticsHandler.GetClientProgress)
+		apiV1.GET("/clients/:id/records", can(authz.PermSessionsRead), owns.Require(authz.ResourceClient, "id", authz.AccessTrainerOrClient), analyticsHandler.GetClientRecords)
+		apiV1.POST("/sessions/:id/logs", can(authz.PermSessionsWrite), owns.Require(authz.ResourceSchedule, "id", authz.AccessTrainer), sessionHandler.CreateLog)
+		apiV1.GET("/sessions/:id/logs", can(authz.PermSessionsRead), owns.Require(authz.ResourceSchedule, "id", authz.AccessTrainerOrClient), sessionHandler.GetLogs)
+		apiV1.PUT("/sessions/:id/logs/:logId", can(authz.PermSessionsWrite), owns.Require(authz.ResourceSchedule, "id", authz.AccessTrainer), sessionHandler.UpdateLog)
+		apiV1.DELETE("/sessions/:id/logs/:logId", can(authz.PermSessionsWrite), owns.Require(authz.ResourceSchedule, "id", authz.AccessTrainer), sessionHandler.DeleteLog)
+		apiV1.PUT("/sessions/:id/logs/:logId/sets/:setId", can(authz.PermSessionsWrite), owns.Require(authz.ResourceSchedule, "id", authz.AccessTrainer), sessionHandler.UpdateLogSet)
+		apiV1.DELETE("/sessions/:id/logs/:logId/sets/:setId", can(authz.PermSessionsWrite), owns.Require(authz.ResourceSchedule, "id", authz.AccessTrainer), sessionHandler.DeleteLogSet)
 
-		apiV1.GET("/clients", trainingHandler.GetClients)
-		apiV1.POST("/clients", trainingHandler.CreateClient)
+		// --- Body Measurements ---
+		apiV1.GET("/measurement-types", can(authz.PermMeasurementsRead), measurementHandler.GetMeasurementTypes)
+		apiV1.POST("/measurement-types", can(authz.PermMeasurementsWrite), measurementHandler.CreateMeasurementType)
+		apiV1.DELETE("/measurement-types/:id", can(authz.PermMeasurementsWrite), measurementHandler.DeleteMeasurementType)
+		apiV1.GET("/clients/:id/measurements", can(authz.PermMeasurementsRead), owns.Require(authz.ResourceClient, "id", authz.AccessTrainerOrClient), measurementHandler.GetMeasurements)
+		apiV1.GET("/clients/:id/measurements/latest", can(authz.PermMeasurementsRead), owns.Require(authz.ResourceClient, "id", authz.AccessTrainerOrClient), measurementHandler.GetLatestMeasurements)
+		apiV1.GET("/clients/:id/measurements/series", can(authz.PermMeasurementsRead), owns.Require(authz.ResourceClient, "id", authz.AccessTrainerOrClient), measurementHandler.GetMeasurementSeries)
+		apiV1.POST("/clients/:id/measurements", can(authz.PermMeasurementsWrite), owns.Require(authz.ResourceClient, "id", authz.AccessTrainer), measurementHandler.CreateMeasurement)
+		apiV1.PUT("/clients/:id/measurements/:measurementId", can(authz.PermMeasurementsWrite), owns.Require(authz.ResourceClient, "id", authz.AccessTrainer), measurementHandler.UpdateMeasurement)
+		apiV1.DELETE("/clients/:id/measurements/:measurementId", can(authz.PermMeasurementsWrite), owns.Require(authz.ResourceClient, "id", authz.AccessTrainer), measurementHandler.DeleteMeasurement)
 
-		apiV1.GET("/clients/:id/notes", clientHandler.GetClientNotes)
-		apiV1.POST("/clients/:id/notes", clientHandler.CreateClientNote)
+		apiV1.GET("/programs", can(authz.PermProgramsRead), programHandler.GetPrograms)
+		apiV1.POST("/programs", can(authz.PermProgramsWrite), programHandler.CreateProgram)
+		apiV1.GET("/programs/:id", can(authz.PermProgramsRead), owns.Require(authz.ResourceProgram, "id", authz.AccessTrainerOrClient), programHandler.GetProgramDetail)
+		apiV1.POST("/programs/:id/exercises", can(authz.PermProgramsWrite), owns.Require(authz.ResourceProgram, "id", authz.AccessTrainer), programHandler.AddExercise)
+		apiV1.POST("/programs/:id/exercises/reorder", can(authz.PermProgramsWrite), owns.Require(authz.ResourceProgram, "id", authz.AccessTrainer), programHandler.ReorderExercises)
+		apiV1.PUT("/programs/:id/exercises/:exerciseRowId", can(authz.PermProgramsWrite), owns.Require(authz.ResourceProgram, "id", authz.AccessTrainer), programHandler.UpdateExercise)
+		apiV1.DELETE("/programs/:id/exercises/:exerciseRowId", can(authz.PermProgramsWrite), owns.Require(authz.ResourceProgram, "id", authz.AccessTrainer), programHandler.DeleteExercise)
+		apiV1.POST("/programs/:id/assign", can(authz.PermProgramsWrite), owns.Require(authz.ResourceProgram, "id", authz.AccessTrainer), programHandler.AssignProgram)
+		apiV1.GET("/programs/:id/assignments", can(authz.PermProgramsRead), owns.Require(authz.ResourceProgram, "id", authz.AccessTrainer), programHandler.GetProgramAssignments)
+		apiV1.PUT("/programs/:id", can(authz.PermProgramsWrite), owns.Require(authz.ResourceProgram, "id", authz.AccessTrainer), programHandler.UpdateProgram)
+		apiV1.DELETE("/programs/:id", can(authz.PermProgramsWrite), owns.Require(authz.ResourceProgram, "id", authz.AccessTrainer), programHandler.DeleteProgram)
 
-		apiV1.POST("/sessions", sessionHandler.CreateSession)
-		apiV1.GET("/clients/:id/sessions", sessionHandler.GetClientSessions)
-		apiV1.POST("/sessions/:id/logs", sessionHandler.CreateLog)
+		// โครงสร้างโปรแกรม: Phase -> Week -> Day
+		apiV1.POST("/programs/:id/phases", can(authz.PermProgramsWrite), owns.Require(authz.ResourceProgram, "id", authz.AccessTrainer), programHandler.CreatePhase)
+		apiV1.POST("/programs/:id/weeks", can(authz.PermProgramsWrite), owns.Require(authz.ResourceProgram, "id", authz.AccessTrainer), programHandler.CreateWeek)
+		apiV1.PUT("/programs/:id/weeks/:weekId", can(authz.PermProgramsWrite), owns.Require(authz.ResourceProgram, "id", authz.AccessTrainer), programHandler.UpdateWeek)
+		apiV1.DELETE("/programs/:id/weeks/:weekId", can(authz.PermProgramsWrite), owns.Require(authz.ResourceProgram, "id", authz.AccessTrainer), programHandler.DeleteWeek)
+		apiV1.POST("/programs/:id/weeks/:weekId/move", can(authz.PermProgramsWrite), owns.Require(authz.ResourceProgram, "id", authz.AccessTrainer), programHandler.MoveWeek)
+		apiV1.POST("/programs/:id/weeks/:weekId/duplicate", can(authz.PermProgramsWrite), owns.Require(authz.ResourceProgram, "id", authz.AccessTrainer), programHandler.DuplicateWeek)
+		apiV1.POST("/programs/:id/weeks/:weekId/days", can(authz.PermProgramsWrite), owns.Require(authz.ResourceProgram, "id", authz.AccessTrainer), programHandler.CreateDay)
+		apiV1.DELETE("/programs/:id/days/:dayId", can(authz.PermProgramsWrite), owns.Require(authz.ResourceProgram, "id", authz.AccessTrainer), programHandler.DeleteDay)
+		apiV1.POST("/programs/:id/days/:dayId/move", can(authz.PermProgramsWrite), owns.Require(authz.ResourceProgram, "id", authz.AccessTrainer), programHandler.MoveDay)
+		apiV1.POST("/programs/:id/days/:dayId/duplicate", can(authz.PermProgramsWrite), owns.Require(authz.ResourceProgram, "id", authz.AccessTrainer), programHandler.DuplicateDay)
+		apiV1.POST("/programs/:id/days/:dayId/blocks", can(authz.PermProgramsWrite), owns.Require(authz.ResourceProgram, "id", authz.AccessTrainer), programHandler.CreateBlock)
+		apiV1.PUT("/programs/:id/blocks/:blockId", can(authz.PermProgramsWrite), owns.Require(authz.ResourceProgram, "id", authz.AccessTrainer), programHandler.UpdateBlock)
+		apiV1.DELETE("/programs/:id/blocks/:blockId", can(authz.PermProgramsWrite), owns.Require(authz.ResourceProgram, "id", authz.AccessTrainer), programHandler.DeleteBlock)
 
-		apiV1.GET("/programs", programHandler.GetPrograms)
-		apiV1.POST("/programs", programHandler.CreateProgram)
-		apiV1.GET("/programs/:id", programHandler.GetProgramDetail)
-		apiV1.POST("/programs/:id/exercises", programHandler.AddExercise)
-		apiV1.PUT("/programs/:id", programHandler.UpdateProgram)
-		apiV1.DELETE("/programs/:id", programHandler.DeleteProgram)
+		// Exercise Library (คลังท่าฝึก)
+		apiV1.GET("/exercises", can(authz.PermExercisesRead), exerciseHandler.GetExercises)
+		apiV1.GET("/exercises/categories", can(authz.PermExercisesRead), exerciseHandler.GetCategories)
+		apiV1.GET("/exercises/muscle-groups", can(authz.PermExercisesRead), exerciseHandler.GetMuscleGroups)
+		apiV1.GET("/exercises/:id", can(authz.PermExercisesRead), exerciseHandler.GetExercise)
+		apiV1.POST("/exercises", can(authz.PermExercisesWrite), exerciseHandler.CreateExercise)
+		apiV1.PUT("/exercises/:id", can(authz.PermExercisesWrite), exerciseHandler.UpdateExercise)
+		apiV1.DELETE("/exercises/:id", can(authz.PermExercisesWrite), exerciseHandler.DeleteExercise)
 
 	}
 
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"users/internal/config"
+	"users/internal/notify"
+	"users/internal/repository"
+	"users/internal/service"
+)
+
+// newNotificationService สร้าง Renderer และช่องทางตาม Config (Inbox ในแอปเปิดเสมอ; SMTP/SMS เปิดเมื่อตั้งค่าไว้)
+func newNotificationService(cfg config.Config, repo repository.NotificationRepository) (service.NotificationService, error) {
+	loc, err := time.LoadLocation(cfg.CalendarTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CALENDAR_TIMEZONE: %w", err)
+	}
+	renderer, err := notify.NewRenderer(cfg.NotifyDefaultLocale, loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NOTIFY_DEFAULT_LOCALE: %w", err)
+	}
+
+	channels := []notify.Channel{notify.NewInAppChannel(repo)}
+	if cfg.SMTPHost != "" {
+		smtpChannel, err := notify.NewSMTPChannel(notify.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		})
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, smtpChannel)
+	} else {
+		log.Println("WARNING: SMTP_HOST is not set. Email notifications will be skipped.")
+	}
+	if cfg.SMSAPIURL != "" {
+		channels = append(channels, notify.NewSMSChannel(notify.SMSConfig{URL: cfg.SMSAPIURL, APIKey: cfg.SMSAPIKey, Sender: cfg.SMSSender}))
+	}
+
+	reminders := service.ReminderWindows{
+		Schedule:   time.Duration(cfg.ScheduleReminderHours) * time.Hour,
+		Assignment: time.Duration(cfg.AssignmentReminderHours) * time.Hour,
+	}
+	return service.NewNotificationService(repo, renderer, channels, cfg.NotifyDefaultLocale, reminders), nil
+}
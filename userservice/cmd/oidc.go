@@ -0,0 +1,27 @@
+package main
+
+import (
+	"log"
+
+	"users/internal/config"
+	"users/internal/oidc"
+)
+
+// newOIDCProviders สร้างผู้ให้บริการตาม Config (ค้นหา endpoint ตอนใช้งานครั้งแรก)
+func newOIDCProviders(cfg config.Config) []*oidc.Provider {
+	if len(cfg.OIDCProviders) == 0 {
+		log.Println("WARNING: no OIDC providers are configured (GOOGLE_CLIENT_ID, OIDC_PROVIDERS). External login will not work.")
+	}
+	providers := make([]*oidc.Provider, 0, len(cfg.OIDCProviders))
+	for _, p := range cfg.OIDCProviders {
+		providers = append(providers, oidc.NewProvider(oidc.Config{
+			Name:         p.Name,
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+		}))
+	}
+	return providers
+}
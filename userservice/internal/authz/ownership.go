@@ -0,0 +1,46 @@
+package authz
+
+import "errors"
+
+// Resource ชนิดของข้อมูลที่ต้องตรวจความเป็นเจ้าของ
+type Resource string
+
+const (
+	ResourceClient     Resource = "client"
+	ResourceProgram    Resource = "program"
+	ResourceSchedule   Resource = "schedule"
+	ResourceSeries     Resource = "schedule series"
+	ResourceAssignment Resource = "assignment"
+)
+
+// Access ระดับการเข้าถึงที่ Route ต้องการ
+type Access int
+
+const (
+	// AccessTrainer เฉพาะเทรนเนอร์เจ้าของข้อมูล
+	AccessTrainer Access = iota
+	// AccessTrainerOrClient เทรนเนอร์เจ้าของ หรือลูกค้าที่บัญชีเชื่อมกับข้อมูลนั้น
+	AccessTrainerOrClient
+)
+
+// ErrResourceNotFound คืนจาก OwnerResolver เมื่อไม่พบข้อมูล
+var ErrResourceNotFound = errors.New("resource not found")
+
+// Owner เจ้าของข้อมูล: เทรนเนอร์ และบัญชีลูกค้าที่เชื่อมอยู่ (ถ้ามี)
+type Owner struct {
+	TrainerID    int
+	ClientUserID *int
+}
+
+// OwnerResolver หาเจ้าของของข้อมูลจาก ID (implement ใน repository)
+type OwnerResolver interface {
+	ResolveOwner(resource Resource, id int) (*Owner, error)
+}
+
+// Allows ตรวจว่า userID เข้าถึงข้อมูลนี้ได้ตามระดับ access หรือไม่
+func (o *Owner) Allows(userID int, access Access) bool {
+	if o.TrainerID == userID {
+		return true
+	}
+	return access == AccessTrainerOrClient && o.ClientUserID != nil && *o.ClientUserID == userID
+}
@@ -0,0 +1,125 @@
+// Package authz กำหนดสิทธิ์ (Permission) ของแต่ละ Role แบบ declarative
+// เพิ่ม Role ใหม่ได้ด้วย Policy.Grant โดยไม่ต้องแก้ Handler
+package authz
+
+import "sync"
+
+type Permission string
+
+const (
+	// สิทธิ์พิเศษ: ทำได้ทุกอย่าง (ใช้กับ admin)
+	PermAll Permission = "*"
+
+	PermUsersRead   Permission = "users:read"
+	PermUsersManage Permission = "users:manage"
+
+	PermClientsRead  Permission = "clients:read"
+	PermClientsWrite Permission = "clients:write"
+	PermNotesRead    Permission = "notes:read"
+	PermNotesWrite   Permission = "notes:write"
+	PermInvitesWrite Permission = "invitations:write"
+
+	PermProgramsRead  Permission = "programs:read"
+	PermProgramsWrite Permission = "programs:write"
+
+	PermSchedulesRead  Permission = "schedules:read"
+	PermSchedulesWrite Permission = "schedules:write"
+
+	PermAssignmentsRead  Permission = "assignments:read"
+	PermAssignmentsWrite Permission = "assignments:write"
+	// ลูกค้าเริ่ม/ส่งงานที่ได้รับมอบหมาย
+	PermAssignmentsSubmit Permission = "assignments:submit"
+
+	PermSessionsRead  Permission = "sessions:read"
+	PermSessionsWrite Permission = "sessions:write"
+
+	PermMeasurementsRead  Permission = "measurements:read"
+	PermMeasurementsWrite Permission = "measurements:write"
+
+	PermExercisesRead         Permission = "exercises:read"
+	PermExercisesWrite        Permission = "exercises:write"
+	PermExercisesManageGlobal Permission = "exercises:manage_global"
+
+	PermDashboardRead Permission = "dashboard:read"
+
+	// เข้าถึงข้อมูลของเทรนเนอร์คนอื่นได้ (ข้ามการตรวจความเป็นเจ้าของ)
+	PermOwnershipBypass Permission = "ownership:bypass"
+)
+
+const (
+	RoleAdmin   = "admin"
+	RoleTrainer = "trainer"
+	RoleClient  = "client"
+)
+
+// Policy เก็บ mapping Role -> Permissions
+type Policy struct {
+	mu    sync.RWMutex
+	roles map[string]map[Permission]struct{}
+}
+
+func NewPolicy() *Policy {
+	return &Policy{roles: map[string]map[Permission]struct{}{}}
+}
+
+// DefaultPolicy สิทธิ์เริ่มต้นของระบบ (admin, trainer, client)
+func DefaultPolicy() *Policy {
+	p := NewPolicy()
+
+	p.Grant(RoleAdmin, PermAll)
+
+	p.Grant(RoleTrainer,
+		PermClientsRead, PermClientsWrite,
+		PermNotesRead, PermNotesWrite,
+		PermInvitesWrite,
+		PermProgramsRead, PermProgramsWrite,
+		PermSchedulesRead, PermSchedulesWrite,
+		PermAssignmentsRead, PermAssignmentsWrite,
+		PermSessionsRead, PermSessionsWrite,
+		PermMeasurementsRead, PermMeasurementsWrite,
+		PermExercisesRead, PermExercisesWrite,
+		PermDashboardRead,
+	)
+
+	p.Grant(RoleClient,
+		PermProgramsRead,
+		PermSchedulesRead,
+		PermAssignmentsRead, PermAssignmentsSubmit,
+		PermSessionsRead,
+		PermMeasurementsRead,
+		PermExercisesRead,
+	)
+
+	return p
+}
+
+// Grant เพิ่มสิทธิ์ให้ Role (ถ้า Role ยังไม่มีจะถูกสร้างใหม่)
+func (p *Policy) Grant(role string, perms ...Permission) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	set, ok := p.roles[role]
+	if !ok {
+		set = map[Permission]struct{}{}
+		p.roles[role] = set
+	}
+	for _, perm := range perms {
+		set[perm] = struct{}{}
+	}
+}
+
+// Can ตรวจว่า Role นี้มีสิทธิ์ perm หรือไม่ (Role ที่ไม่รู้จักจะไม่มีสิทธิ์ใดๆ)
+func (p *Policy) Can(role string, perm Permission) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	set, ok := p.roles[role]
+	if !ok {
+		return false
+	}
+	if _, ok := set[PermAll]; ok {
+		return true
+	}
+	_, ok = set[perm]
+	return ok
+}
@@ -1,7 +1,11 @@
 package config
 
 import (
+	"log"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 )
 
 type Config struct {
@@ -12,6 +16,58 @@ type Config struct {
 	DBName     string
 	APIToken   string
 	APIPORT    string
+
+	// URL ของ Frontend (ใช้สร้างลิงก์คำเชิญ ฯลฯ)
+	FrontendURL string
+
+	// ต้องยืนยันอีเมลก่อน Login ด้วยรหัสผ่าน
+	RequireEmailVerification bool
+
+	// ผู้ให้บริการ OIDC (Login ที่ /auth/oidc/:name/login) ดู loadOIDCProviders
+	OIDCProviders []OIDCProvider
+
+	// URL ของ API ที่เข้าถึงได้จากภายนอก (ใช้สร้างลิงก์ Subscribe ปฏิทิน)
+	PublicURL string
+	// โดเมนที่ใช้ใน UID ของนัดใน ICS (ค่าว่าง = host ของ PublicURL) ห้ามเปลี่ยนหลังเปิดใช้
+	CalendarUIDDomain string
+	// Time Zone เริ่มต้นของ ICS feed (IANA)
+	CalendarTimezone string
+
+	// เวลาพักขั้นต่ำระหว่างนัด (นาที) ที่ใช้ตรวจนัดชนกัน
+	ScheduleBufferMinutes int
+
+	// รอบการตรวจงานมอบหมายที่เลยกำหนด (นาที, 0 = ปิด)
+	AssignmentOverdueCheckMinutes int
+
+	// การแจ้งเตือน: ภาษาเริ่มต้น (th/en), รอบส่ง outbox (วินาที, 0 = ปิด), รอบหาการเตือน (นาที)
+	// และเวลาเตือนล่วงหน้าก่อนนัด/ก่อนงานครบกำหนด (ชั่วโมง)
+	NotifyDefaultLocale     string
+	NotifyDispatchSeconds   int
+	ReminderScanMinutes     int
+	ScheduleReminderHours   int
+	AssignmentReminderHours int
+
+	// SMTP (ว่าง = ปิดช่องทางอีเมล) ทดสอบในเครื่องได้ด้วย MailHog: SMTP_HOST=localhost SMTP_PORT=1025
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	// ผู้ให้บริการ SMS แบบ HTTP (ว่าง = ปิดช่องทาง SMS)
+	SMSAPIURL string
+	SMSAPIKey string
+	SMSSender string
+}
+
+// OIDCProvider ผู้ให้บริการ OpenID Connect หนึ่งราย (endpoint ค้นหาจาก Issuer อัตโนมัติ)
+type OIDCProvider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
 }
 
 func LoadConfig() Config {
@@ -23,6 +79,35 @@ func LoadConfig() Config {
 		DBName:     getEnv("DB_NAME", "postgres"),
 		APIToken:   getEnv("API_TOKEN", "fjwfji3399"),
 		APIPORT:    getEnv("API_PORT", "80"),
+
+		FrontendURL: getEnv("FRONTEND_URL", "http://localhost:3000"),
+
+		RequireEmailVerification: getEnvBool("REQUIRE_EMAIL_VERIFICATION", false),
+		OIDCProviders:            loadOIDCProviders(),
+
+		PublicURL:         getEnv("PUBLIC_URL", "http://localhost:8080"),
+		CalendarUIDDomain: getEnv("CALENDAR_UID_DOMAIN", ""),
+		CalendarTimezone:  getEnv("CALENDAR_TIMEZONE", "Asia/Bangkok"),
+
+		ScheduleBufferMinutes: getEnvInt("SCHEDULE_BUFFER_MINUTES", 0),
+
+		AssignmentOverdueCheckMinutes: getEnvInt("ASSIGNMENT_OVERDUE_CHECK_MINUTES", 5),
+
+		NotifyDefaultLocale:     getEnv("NOTIFY_DEFAULT_LOCALE", "th"),
+		NotifyDispatchSeconds:   getEnvInt("NOTIFY_DISPATCH_SECONDS", 15),
+		ReminderScanMinutes:     getEnvInt("REMINDER_SCAN_MINUTES", 10),
+		ScheduleReminderHours:   getEnvInt("SCHEDULE_REMINDER_HOURS", 24),
+		AssignmentReminderHours: getEnvInt("ASSIGNMENT_REMINDER_HOURS", 24),
+
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnvInt("SMTP_PORT", 587),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:     getEnv("SMTP_FROM", "Fitness <no-reply@localhost>"),
+
+		SMSAPIURL: getEnv("SMS_API_URL", ""),
+		SMSAPIKey: getEnv("SMS_API_KEY", ""),
+		SMSSender: getEnv("SMS_SENDER", "Fitness"),
 	}
 }
 
@@ -33,3 +118,78 @@ func getEnv(key, fallback string) string {
 	}
 	return v
 }
+
+// UIDDomain โดเมนสำหรับ UID ของนัดใน ICS
+func (c Config) UIDDomain() string {
+	if c.CalendarUIDDomain != "" {
+		return c.CalendarUIDDomain
+	}
+	if u, err := url.Parse(c.PublicURL); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return "localhost"
+}
+
+func getEnvInt(key string, fallback int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		log.Printf("WARNING: %s must be a non-negative integer. Using default %d.", key, fallback)
+		return fallback
+	}
+	return n
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Printf("WARNING: %s must be a boolean. Using default %t.", key, fallback)
+		return fallback
+	}
+	return b
+}
+
+// loadOIDCProviders Google (GOOGLE_CLIENT_ID, GOOGLE_CLIENT_SECRET, GOOGLE_REDIRECT_URL และ GOOGLE_ISSUER สำหรับชี้ไป mock issuer)
+// และรายอื่นจาก OIDC_PROVIDERS=name1,name2 โดยแต่ละรายตั้ง OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET,
+// _REDIRECT_URL และ _SCOPES (คั่นด้วยช่องว่าง, ว่าง = openid email profile)
+func loadOIDCProviders() []OIDCProvider {
+	var providers []OIDCProvider
+	if clientID := os.Getenv("GOOGLE_CLIENT_ID"); clientID != "" {
+		providers = append(providers, OIDCProvider{
+			Name:         "google",
+			Issuer:       getEnv("GOOGLE_ISSUER", "https://accounts.google.com"),
+			ClientID:     clientID,
+			ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
+			RedirectURL:  os.Getenv("GOOGLE_REDIRECT_URL"),
+		})
+	}
+
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		p := OIDCProvider{
+			Name:         name,
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+		}
+		if p.Issuer == "" || p.ClientID == "" || p.RedirectURL == "" {
+			log.Printf("WARNING: %sISSUER, %sCLIENT_ID and %sREDIRECT_URL are required. Skipping OIDC provider %q.", prefix, prefix, prefix, name)
+			continue
+		}
+		providers = append(providers, p)
+	}
+	return providers
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	"users/internal/models"
+	"users/internal/repository"
+	"users/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyticsHandler struct {
+	service    service.AnalyticsService
+	recordRepo repository.PersonalRecordRepository
+}
+
+func NewAnalyticsHandler(analyticsService service.AnalyticsService, recordRepo repository.PersonalRecordRepository) *AnalyticsHandler {
+	return &AnalyticsHandler{service: analyticsService, recordRepo: recordRepo}
+}
+
+// GET /api/v1/clients/:id/progress?exercise_id=1&formula=epley|brzycki&bucket=week|month&from=2024-01-01&to=2024-03-31&tz=Asia/Bangkok
+// คืนค่า 1RM ประมาณการ, Volume รวม, เซตที่ดีที่สุด และ RPE เฉลี่ย ของแต่ละช่วงเวลา
+func (h *AnalyticsHandler) GetClientProgress(c *gin.Context) {
+	clientID, _ := strconv.Atoi(c.Param("id"))
+	exerciseID, err := strconv.Atoi(c.Query("exercise_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "exercise_id is required"})
+		return
+	}
+
+	q := service.ProgressQuery{
+		ExerciseID: exerciseID,
+		Formula:    c.Query("formula"),
+		Bucket:     c.Query("bucket"),
+		Timezone:   c.Query("tz"),
+	}
+	// วันที่ YYYY-MM-DD เริ่ม/จบตามเวลาท้องถิ่นเดียวกับที่ใช้แบ่งสัปดาห์/เดือน
+	loc, err := h.service.Location(q.Timezone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if q.From, err = parseDateParam(c.Query("from"), false, loc); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be YYYY-MM-DD or RFC3339"})
+		return
+	}
+	if q.To, err = parseDateParam(c.Query("to"), true, loc); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must be YYYY-MM-DD or RFC3339"})
+		return
+	}
+
+	report, err := h.service.GetExerciseProgress(clientID, q)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidFormula) || errors.Is(err, service.ErrInvalidBucket) || errors.Is(err, service.ErrInvalidTimezone) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get progress"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GET /api/v1/clients/:id/records?exercise_id=1&type=max_weight|e1rm|reps_at_weight (ประวัติ PR ล่าสุดก่อน)
+func (h *AnalyticsHandler) GetClientRecords(c *gin.Context) {
+	clientID, _ := strconv.Atoi(c.Param("id"))
+
+	var exerciseID *int
+	if v := c.Query("exercise_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exercise_id"})
+			return
+		}
+		exerciseID = &id
+	}
+
+	recordType := c.Query("type")
+	switch recordType {
+	case "", models.RecordMaxWeight, models.RecordE1RM, models.RecordRepsAtWeight:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be one of: max_weight, e1rm, reps_at_weight"})
+		return
+	}
+
+	records, err := h.recordRepo.GetRecords(clientID, exerciseID, recordType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch personal records"})
+		return
+	}
+	c.JSON(http.StatusOK, records)
+}
+
+// parseDateParam รับได้ทั้ง YYYY-MM-DD และ RFC3339 (ค่าว่าง = nil)
+// วันที่แบบ YYYY-MM-DD เริ่มที่เที่ยงคืนตามเวลาของ loc
+// endOfDay=true: วันที่แบบ YYYY-MM-DD จะนับรวมทั้งวัน (คืนเวลาเริ่มของวันถัดไป)
+func parseDateParam(v string, endOfDay bool, loc *time.Location) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, v, loc)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDateParam(t *testing.T) {
+	bangkok, err := time.LoadLocation("Asia/Bangkok")
+	if err != nil {
+		t.Skipf("tzdata: %v", err)
+	}
+	tests := []struct {
+		v        string
+		endOfDay bool
+		want     string // UTC
+	}{
+		// เที่ยงคืนเวลาไทย = 17:00 UTC ของวันก่อนหน้า
+		{"2024-05-01", false, "2024-04-30T17:00:00Z"},
+		{"2024-05-31", true, "2024-05-31T17:00:00Z"},
+		// RFC3339 มี offset อยู่แล้ว ไม่ขึ้นกับ loc
+		{"2024-05-01T08:00:00+07:00", false, "2024-05-01T01:00:00Z"},
+	}
+	for _, tt := range tests {
+		got, err := parseDateParam(tt.v, tt.endOfDay, bangkok)
+		if err != nil {
+			t.Errorf("%s: %v", tt.v, err)
+			continue
+		}
+		if s := got.UTC().Format(time.RFC3339); s != tt.want {
+			t.Errorf("%s (endOfDay=%v) = %s, want %s", tt.v, tt.endOfDay, s, tt.want)
+		}
+	}
+	if got, err := parseDateParam("", false, bangkok); got != nil || err != nil {
+		t.Errorf("empty = %v, %v", got, err)
+	}
+	if _, err := parseDateParam("01/05/2024", false, bangkok); err == nil {
+		t.Error("invalid date accepted")
+	}
+}
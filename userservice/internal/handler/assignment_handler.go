@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"users/internal/repository"
+	"users/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AssignmentHandler ขั้นตอนของงานมอบหมาย: ลูกค้าเริ่ม/ส่งงาน, เทรนเนอร์ตรวจงาน
+// :id ผ่าน OwnershipGuard (ResourceAssignment) แล้ว
+type AssignmentHandler struct {
+	service service.AssignmentService
+}
+
+func NewAssignmentHandler(s service.AssignmentService) *AssignmentHandler {
+	return &AssignmentHandler{service: s}
+}
+
+// GET /api/v1/assignments/:id (พร้อมไฟล์แนบ)
+func (h *AssignmentHandler) GetAssignment(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	assignment, err := h.service.GetAssignment(id)
+	if err != nil {
+		respondAssignmentError(c, err, "Failed to get assignment")
+		return
+	}
+	c.JSON(http.StatusOK, assignment)
+}
+
+// POST /api/v1/assignments/:id/start
+func (h *AssignmentHandler) StartAssignment(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	assignment, err := h.service.StartAssignment(id)
+	if err != nil {
+		respondAssignmentError(c, err, "Failed to start assignment")
+		return
+	}
+	c.JSON(http.StatusOK, assignment)
+}
+
+// POST /api/v1/assignments/:id/submit
+// Body: {"notes": "ทำครบแล้ว", "attachments": [{"url": "https://...", "file_name": "form.mp4", "content_type": "video/mp4"}]}
+func (h *AssignmentHandler) SubmitAssignment(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req service.SubmitAssignmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	assignment, err := h.service.SubmitAssignment(id, req)
+	if err != nil {
+		respondAssignmentError(c, err, "Failed to submit assignment")
+		return
+	}
+	c.JSON(http.StatusOK, assignment)
+}
+
+// POST /api/v1/assignments/:id/review Body: {"feedback": "ฟอร์มดีขึ้นมาก", "request_revision": false}
+func (h *AssignmentHandler) ReviewAssignment(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req service.ReviewAssignmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	assignment, err := h.service.ReviewAssignment(id, req)
+	if err != nil {
+		respondAssignmentError(c, err, "Failed to review assignment")
+		return
+	}
+	c.JSON(http.StatusOK, assignment)
+}
+
+func respondAssignmentError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrAssignmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Assignment not found"})
+	case errors.Is(err, service.ErrInvalidAssignmentTransition), errors.Is(err, repository.ErrAssignmentStatusChanged):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidSubmission):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
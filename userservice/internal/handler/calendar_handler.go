@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"users/internal/repository"
+	"users/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CalendarHandler struct {
+	service   service.CalendarService
+	publicURL string
+}
+
+// publicURL คือ URL ของ API ที่แอปปฏิทินภายนอกเรียกถึงได้ (ใช้สร้างลิงก์ Subscribe)
+func NewCalendarHandler(s service.CalendarService, publicURL string) *CalendarHandler {
+	return &CalendarHandler{service: s, publicURL: strings.TrimRight(publicURL, "/")}
+}
+
+// POST /api/v1/calendar/feed (สร้างลิงก์ใหม่; ถ้ามีอยู่แล้ว ลิงก์เดิมจะใช้ไม่ได้ทันที)
+func (h *CalendarHandler) CreateFeed(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	feed, token, err := h.service.CreateFeedToken(int(userID.(float64)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create calendar feed"})
+		return
+	}
+
+	feedURL := h.publicURL + "/calendar/" + token + ".ics"
+	c.JSON(http.StatusCreated, gin.H{
+		"feed":       feed,
+		"token":      token,
+		"feed_url":   feedURL,
+		"webcal_url": webcalURL(feedURL),
+	})
+}
+
+// GET /api/v1/calendar/feed (สถานะลิงก์ปัจจุบัน; token ไม่ถูกเก็บไว้ จึงแสดงซ้ำไม่ได้)
+func (h *CalendarHandler) GetFeed(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	feed, err := h.service.GetFeed(int(userID.(float64)))
+	if err != nil {
+		respondCalendarError(c, err, "Failed to get calendar feed")
+		return
+	}
+	c.JSON(http.StatusOK, feed)
+}
+
+// DELETE /api/v1/calendar/feed (ยกเลิกลิงก์)
+func (h *CalendarHandler) DeleteFeed(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	if err := h.service.RevokeFeed(int(userID.(float64))); err != nil {
+		respondCalendarError(c, err, "Failed to revoke calendar feed")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Calendar feed revoked"})
+}
+
+// GET /calendar/:token(.ics)?tz=Asia/Bangkok (Public: แอปปฏิทินเรียกโดยไม่มี Cookie; token คือสิทธิ์การเข้าถึง)
+func (h *CalendarHandler) ServeFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	body, err := h.service.RenderFeed(token, c.Query("tz"))
+	if err != nil {
+		respondCalendarError(c, err, "Failed to render calendar feed")
+		return
+	}
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", body)
+}
+
+func respondCalendarError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrCalendarFeedNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+	case errors.Is(err, service.ErrInvalidTimezone):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// webcalURL ลิงก์ webcal:// ให้ OS เปิดแอปปฏิทินและ Subscribe ได้ในคลิกเดียว
+func webcalURL(feedURL string) string {
+	if i := strings.Index(feedURL, "://"); i >= 0 {
+		return "webcal" + feedURL[i:]
+	}
+	return feedURL
+}
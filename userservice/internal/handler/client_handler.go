@@ -27,11 +27,15 @@ func NewClientHandler(repo repository.ClientRepository, userService service.User
 
 // --- เพิ่มฟังก์ชันใหม่ต่อท้ายไฟล์ ---
 
-// GET /api/v1/clients/:id/notes
+// GET /api/v1/clients/:id/notes?type=&q=&from=&to=&sort=&limit=&offset=|cursor=
 func (h *ClientHandler) GetClientNotes(c *gin.Context) {
 	clientID, _ := strconv.Atoi(c.Param("id"))
+	p, ok := parseList(c, repository.NoteList)
+	if !ok {
+		return
+	}
 
-	notes, err := h.repo.GetNotesByClientID(clientID)
+	notes, err := h.repo.ListNotes(clientID, p)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
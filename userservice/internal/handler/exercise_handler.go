@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"users/internal/authz"
+	"users/internal/models"
+	"users/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExerciseHandler struct {
+	repo   repository.ExerciseRepository
+	policy *authz.Policy
+}
+
+func NewExerciseHandler(repo repository.ExerciseRepository, policy *authz.Policy) *ExerciseHandler {
+	return &ExerciseHandler{repo: repo, policy: policy}
+}
+
+// GET /api/v1/exercises?search=&category=&muscle=&equipment=&scope=all|global|mine
+func (h *ExerciseHandler) GetExercises(c *gin.Context) {
+	trainerID, _ := c.Get("user_id")
+
+	filter := models.ExerciseFilter{
+		Search:    strings.TrimSpace(c.Query("search")),
+		Category:  c.Query("category"),
+		Muscle:    c.Query("muscle"),
+		Equipment: c.Query("equipment"),
+		Scope:     c.DefaultQuery("scope", "all"),
+	}
+	if filter.Scope != "all" && filter.Scope != "global" && filter.Scope != "mine" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "scope must be one of: all, global, mine"})
+		return
+	}
+
+	exercises, err := h.repo.GetExercises(int(trainerID.(float64)), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch exercises"})
+		return
+	}
+	c.JSON(http.StatusOK, exercises)
+}
+
+// GET /api/v1/exercises/categories
+func (h *ExerciseHandler) GetCategories(c *gin.Context) {
+	c.JSON(http.StatusOK, models.ExerciseCategories)
+}
+
+// GET /api/v1/exercises/muscle-groups
+func (h *ExerciseHandler) GetMuscleGroups(c *gin.Context) {
+	c.JSON(http.StatusOK, models.MuscleGroups)
+}
+
+// GET /api/v1/exercises/:id
+func (h *ExerciseHandler) GetExercise(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exercise ID"})
+		return
+	}
+	trainerID, _ := c.Get("user_id")
+
+	exercise, err := h.repo.GetExerciseByID(id, int(trainerID.(float64)))
+	if err != nil {
+		if errors.Is(err, repository.ErrExerciseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Exercise not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get exercise"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, exercise)
+}
+
+// POST /api/v1/exercises (สร้างท่าฝึกส่วนตัวของเทรนเนอร์; admin ส่ง is_global=true เพื่อสร้างท่ากลาง)
+func (h *ExerciseHandler) CreateExercise(c *gin.Context) {
+	var req models.Exercise
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if msg := validateExercise(&req); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	trainerID, _ := c.Get("user_id")
+	id := int(trainerID.(float64))
+	req.TrainerID = &id
+	if req.IsGlobal {
+		if !h.canManageGlobal(c) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden", "required_permission": authz.PermExercisesManageGlobal})
+			return
+		}
+		req.TrainerID = nil
+	}
+
+	if err := h.repo.CreateExercise(&req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create exercise"})
+		return
+	}
+	c.JSON(http.StatusCreated, req)
+}
+
+// PUT /api/v1/exercises/:id
+func (h *ExerciseHandler) UpdateExercise(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exercise ID"})
+		return
+	}
+
+	var req models.Exercise
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if msg := validateExercise(&req); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	trainerID, _ := c.Get("user_id")
+	req.ID = id
+	req.TrainerID, err = h.ownerForWrite(c, id, int(trainerID.(float64)))
+	if err != nil {
+		h.respondWriteError(c, err, "Failed to update exercise")
+		return
+	}
+
+	if err := h.repo.UpdateExercise(&req); err != nil {
+		h.respondWriteError(c, err, "Failed to update exercise")
+		return
+	}
+	c.JSON(http.StatusOK, req)
+}
+
+// DELETE /api/v1/exercises/:id
+func (h *ExerciseHandler) DeleteExercise(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exercise ID"})
+		return
+	}
+	trainerID, _ := c.Get("user_id")
+	owner, err := h.ownerForWrite(c, id, int(trainerID.(float64)))
+	if err != nil {
+		h.respondWriteError(c, err, "Failed to delete exercise")
+		return
+	}
+
+	if err := h.repo.DeleteExercise(id, owner); err != nil {
+		h.respondWriteError(c, err, "Failed to delete exercise")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Exercise deleted successfully"})
+}
+
+var errGlobalExerciseForbidden = errors.New("global exercise requires permission")
+
+// ownerForWrite หาเจ้าของท่าที่จะแก้/ลบ: ท่าส่วนตัว -> trainerID, ท่ากลาง -> nil (เฉพาะผู้มีสิทธิ์ manage_global)
+// ผู้มีสิทธิ์ ownership bypass แก้/ลบท่าส่วนตัวของเทรนเนอร์คนใดก็ได้ (ใช้ trainer_id จริงของท่า)
+func (h *ExerciseHandler) ownerForWrite(c *gin.Context, exerciseID, trainerID int) (*int, error) {
+	var existing *models.Exercise
+	var err error
+	if h.can(c, authz.PermOwnershipBypass) {
+		existing, err = h.repo.GetAnyExerciseByID(exerciseID)
+	} else {
+		existing, err = h.repo.GetExerciseByID(exerciseID, trainerID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if existing.IsGlobal {
+		if !h.canManageGlobal(c) {
+			return nil, errGlobalExerciseForbidden
+		}
+		return nil, nil
+	}
+	return existing.TrainerID, nil
+}
+
+func (h *ExerciseHandler) canManageGlobal(c *gin.Context) bool {
+	return h.can(c, authz.PermExercisesManageGlobal)
+}
+
+func (h *ExerciseHandler) can(c *gin.Context, perm authz.Permission) bool {
+	role, _ := c.Get("role")
+	roleStr, _ := role.(string)
+	return h.policy.Can(roleStr, perm)
+}
+
+func (h *ExerciseHandler) respondWriteError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrExerciseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Exercise not found"})
+	case errors.Is(err, errGlobalExerciseForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden", "required_permission": authz.PermExercisesManageGlobal})
+	case errors.Is(err, repository.ErrExerciseInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": "Exercise is used by a program or session log"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// validateExercise ตรวจสอบ category/กลุ่มกล้ามเนื้อ และเติมค่า default ให้ array
+func validateExercise(e *models.Exercise) string {
+	e.Name = strings.TrimSpace(e.Name)
+	if e.Name == "" {
+		return "name is required"
+	}
+	if !models.IsValidExerciseCategory(e.Category) {
+		return "invalid category"
+	}
+	if e.PrimaryMuscles == nil {
+		e.PrimaryMuscles = []string{}
+	}
+	if e.SecondaryMuscles == nil {
+		e.SecondaryMuscles = []string{}
+	}
+	for _, m := range append(append([]string{}, e.PrimaryMuscles...), e.SecondaryMuscles...) {
+		if !models.IsValidMuscleGroup(m) {
+			return "invalid muscle group: " + m
+		}
+	}
+	return ""
+}
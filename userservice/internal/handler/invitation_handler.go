@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"users/internal/repository"
+	"users/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvitationHandler struct {
+	service     service.InvitationService
+	frontendURL string
+}
+
+func NewInvitationHandler(s service.InvitationService, frontendURL string) *InvitationHandler {
+	return &InvitationHandler{service: s, frontendURL: strings.TrimRight(frontendURL, "/")}
+}
+
+// POST /api/v1/clients/:id/invitations (เทรนเนอร์ส่งคำเชิญให้ลูกค้าสมัครบัญชี)
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	clientID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid client ID"})
+		return
+	}
+
+	var req struct {
+		Email string `json:"email"`
+	}
+	// Body เป็น optional (ถ้าไม่ส่ง Email มาจะใช้ Email ของ Client)
+	_ = c.ShouldBindJSON(&req)
+
+	trainerID, _ := c.Get("user_id")
+	inv, token, err := h.service.CreateInvitation(int(trainerID.(float64)), clientID, req.Email)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrClientNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Client not found"})
+		case errors.Is(err, service.ErrInvitationNoEmail):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, repository.ErrClientAlreadyLinked):
+			c.JSON(http.StatusConflict, gin.H{"error": "Client already has an account"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"invitation": inv,
+		"token":      token,
+		"invite_url": h.frontendURL + "/invite/" + token,
+	})
+}
+
+// GET /auth/invitations/:token (หน้ารับคำเชิญ: แสดงชื่อลูกค้า/เทรนเนอร์)
+func (h *InvitationHandler) GetInvitation(c *gin.Context) {
+	inv, err := h.service.GetInvitation(c.Param("token"))
+	if err != nil {
+		respondInvitationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, inv)
+}
+
+// POST /auth/invitations/:token/accept (ลูกค้าสมัครบัญชี role = client และเชื่อมกับข้อมูล Client)
+// บัญชียังไม่ยืนยันอีเมล: ระบบส่งลิงก์ยืนยันไปที่อีเมลของคำเชิญ
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	var req service.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	user, err := h.service.AcceptInvitation(c.Param("token"), req)
+	if err != nil {
+		respondInvitationError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, user)
+}
+
+func respondInvitationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrInvitationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+	case errors.Is(err, repository.ErrInvitationUsed), errors.Is(err, service.ErrInvitationExpired):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrClientAlreadyLinked), errors.Is(err, repository.ErrEmailAlreadyExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrPasswordTooShort):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process invitation"})
+	}
+}
@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"net/http"
+	"users/internal/listing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseList อ่าน limit/offset/cursor/sort และตัวกรองของ List Endpoint (ตอบ 400 เองเมื่อไม่ถูกต้อง)
+func parseList[T any](c *gin.Context, spec *listing.Spec[T]) (*listing.Params, bool) {
+	p, err := listing.Parse(spec, c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return p, true
+}
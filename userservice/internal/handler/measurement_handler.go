@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	"users/internal/authz"
+	"users/internal/models"
+	"users/internal/repository"
+	"users/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MeasurementHandler struct {
+	repo    repository.MeasurementRepository
+	service service.MeasurementService
+}
+
+func NewMeasurementHandler(repo repository.MeasurementRepository, measurementService service.MeasurementService) *MeasurementHandler {
+	return &MeasurementHandler{repo: repo, service: measurementService}
+}
+
+// GET /api/v1/measurement-types (ชนิดมาตรฐาน + ชนิดที่เทรนเนอร์สร้างเอง)
+// ลูกค้าเห็นชนิดที่เทรนเนอร์ของตัวเองสร้าง
+func (h *MeasurementHandler) GetMeasurementTypes(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	role, _ := c.Get("role")
+
+	var types []models.MeasurementType
+	var err error
+	if role == "client" {
+		types, err = h.repo.GetMeasurementTypesForClientUser(int(userID.(float64)))
+	} else {
+		types, err = h.repo.GetMeasurementTypes(int(userID.(float64)))
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch measurement types"})
+		return
+	}
+	c.JSON(http.StatusOK, types)
+}
+
+// POST /api/v1/measurement-types
+// Body: {"key": "calf", "name": "Calf", "unit": "cm"}
+func (h *MeasurementHandler) CreateMeasurementType(c *gin.Context) {
+	var req models.MeasurementType
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	trainerID, _ := c.Get("user_id")
+	if err := h.service.CreateMeasurementType(int(trainerID.(float64)), &req); err != nil {
+		respondMeasurementError(c, err, "Failed to create measurement type")
+		return
+	}
+	c.JSON(http.StatusCreated, req)
+}
+
+// DELETE /api/v1/measurement-types/:id (ลบได้เฉพาะชนิดที่สร้างเอง และยังไม่ถูกใช้)
+func (h *MeasurementHandler) DeleteMeasurementType(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid measurement type ID"})
+		return
+	}
+
+	trainerID, _ := c.Get("user_id")
+	if err := h.repo.DeleteMeasurementType(id, int(trainerID.(float64))); err != nil {
+		respondMeasurementError(c, err, "Failed to delete measurement type")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Measurement type deleted successfully"})
+}
+
+// GET /api/v1/clients/:id/measurements?from=&to= (ประวัติการวัด ล่าสุดก่อน)
+func (h *MeasurementHandler) GetMeasurements(c *gin.Context) {
+	clientID, _ := strconv.Atoi(c.Param("id"))
+	from, err1 := parseDateParam(c.Query("from"), false, time.UTC)
+	to, err2 := parseDateParam(c.Query("to"), true, time.UTC)
+	if err1 != nil || err2 != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from/to must be YYYY-MM-DD or RFC3339"})
+		return
+	}
+
+	list, err := h.repo.GetMeasurements(clientID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch measurements"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// GET /api/v1/clients/:id/measurements/latest (ค่าล่าสุดของแต่ละชนิด)
+func (h *MeasurementHandler) GetLatestMeasurements(c *gin.Context) {
+	clientID, _ := strconv.Atoi(c.Param("id"))
+
+	latest, err := h.repo.GetLatestMeasurements(clientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch latest measurements"})
+		return
+	}
+	c.JSON(http.StatusOK, latest)
+}
+
+// GET /api/v1/clients/:id/measurements/series?type=weight&from=&to= (ข้อมูลสำหรับกราฟ เรียงตามเวลา)
+func (h *MeasurementHandler) GetMeasurementSeries(c *gin.Context) {
+	clientID, _ := strconv.Atoi(c.Param("id"))
+	key := c.Query("type")
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type is required"})
+		return
+	}
+	from, err1 := parseDateParam(c.Query("from"), false, time.UTC)
+	to, err2 := parseDateParam(c.Query("to"), true, time.UTC)
+	if err1 != nil || err2 != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from/to must be YYYY-MM-DD or RFC3339"})
+		return
+	}
+
+	points, err := h.repo.GetMeasurementSeries(clientID, key, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch measurement series"})
+		return
+	}
+	c.JSON(http.StatusOK, points)
+}
+
+// POST /api/v1/clients/:id/measurements
+// Body: {"measured_at": "2024-05-01T08:00:00Z", "notes": "", "values": {"weight": 72.5, "body_fat": 18, "waist": 80}}
+func (h *MeasurementHandler) CreateMeasurement(c *gin.Context) {
+	clientID, _ := strconv.Atoi(c.Param("id"))
+	var req models.BodyMeasurement
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	recordedBy := int(userID.(float64))
+	req.ClientID = clientID
+	req.RecordedBy = &recordedBy
+
+	if err := h.service.CreateMeasurement(clientTrainerID(c), &req); err != nil {
+		respondMeasurementError(c, err, "Failed to create measurement")
+		return
+	}
+	c.JSON(http.StatusCreated, req)
+}
+
+// PUT /api/v1/clients/:id/measurements/:measurementId (แทนที่ค่าทั้งหมดของการวัดครั้งนี้)
+func (h *MeasurementHandler) UpdateMeasurement(c *gin.Context) {
+	clientID, _ := strconv.Atoi(c.Param("id"))
+	id, err := strconv.Atoi(c.Param("measurementId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid measurement ID"})
+		return
+	}
+
+	var req models.BodyMeasurement
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	req.ID = id
+	req.ClientID = clientID
+
+	if err := h.service.UpdateMeasurement(clientTrainerID(c), &req); err != nil {
+		respondMeasurementError(c, err, "Failed to update measurement")
+		return
+	}
+	c.JSON(http.StatusOK, req)
+}
+
+// DELETE /api/v1/clients/:id/measurements/:measurementId
+func (h *MeasurementHandler) DeleteMeasurement(c *gin.Context) {
+	clientID, _ := strconv.Atoi(c.Param("id"))
+	id, err := strconv.Atoi(c.Param("measurementId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid measurement ID"})
+		return
+	}
+
+	if err := h.repo.DeleteMeasurement(clientID, id); err != nil {
+		respondMeasurementError(c, err, "Failed to delete measurement")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Measurement deleted successfully"})
+}
+
+// clientTrainerID เทรนเนอร์เจ้าของลูกค้า (จาก OwnershipGuard) ใช้หาชนิดการวัดที่สร้างเอง
+func clientTrainerID(c *gin.Context) int {
+	if owner, ok := c.Get("resource_owner"); ok {
+		return owner.(*authz.Owner).TrainerID
+	}
+	userID, _ := c.Get("user_id")
+	return int(userID.(float64))
+}
+
+func respondMeasurementError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrMeasurementNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Measurement not found"})
+	case errors.Is(err, repository.ErrMeasurementTypeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Measurement type not found"})
+	case errors.Is(err, repository.ErrMeasurementTypeExists), errors.Is(err, repository.ErrMeasurementTypeInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidMeasurement):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"users/internal/repository"
+	"users/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler Inbox ในแอปและการตั้งค่าการแจ้งเตือนของผู้ใช้ที่ Login อยู่
+type NotificationHandler struct {
+	service service.NotificationService
+}
+
+func NewNotificationHandler(s service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{service: s}
+}
+
+// GET /api/v1/notifications?unread=true&limit=50 (ใหม่สุดก่อน)
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	unreadOnly := c.Query("unread") == "true"
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	notifications, unread, err := h.service.GetInbox(int(userID.(float64)), unreadOnly, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"notifications": notifications, "unread_count": unread})
+}
+
+// POST /api/v1/notifications/:id/read
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+	userID, _ := c.Get("user_id")
+
+	if err := h.service.MarkRead(int(userID.(float64)), id); err != nil {
+		respondNotificationError(c, err, "Failed to mark notification as read")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+// POST /api/v1/notifications/read-all
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	updated, err := h.service.MarkAllRead(int(userID.(float64)))
+	if err != nil {
+		respondNotificationError(c, err, "Failed to mark notifications as read")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
+
+// GET /api/v1/notifications/preferences
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	prefs, err := h.service.GetPreferences(int(userID.(float64)))
+	if err != nil {
+		respondNotificationError(c, err, "Failed to get notification preferences")
+		return
+	}
+	c.JSON(http.StatusOK, prefs)
+}
+
+// PUT /api/v1/notifications/preferences Body: {"locale": "en", "email_enabled": true, "sms_enabled": false} (ส่งเฉพาะที่จะแก้)
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	var req service.UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	userID, _ := c.Get("user_id")
+
+	prefs, err := h.service.UpdatePreferences(int(userID.(float64)), req)
+	if err != nil {
+		respondNotificationError(c, err, "Failed to update notification preferences")
+		return
+	}
+	c.JSON(http.StatusOK, prefs)
+}
+
+func respondNotificationError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrNotificationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+	case errors.Is(err, service.ErrInvalidPreferences):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"users/internal/oidc"
+	"users/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Cookie เก็บ state/nonce/PKCE ระหว่างไป Login ที่ผู้ให้บริการ (ส่งเฉพาะ /auth)
+const (
+	oidcStateCookie = "oidc_state"
+	oidcStateTTL    = 10 * time.Minute
+)
+
+type OIDCHandler struct {
+	userService service.UserService
+	providers   map[string]*oidc.Provider
+	frontendURL string
+}
+
+func NewOIDCHandler(us service.UserService, providers []*oidc.Provider, frontendURL string) *OIDCHandler {
+	byName := map[string]*oidc.Provider{}
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+	return &OIDCHandler{userService: us, providers: byName, frontendURL: strings.TrimRight(frontendURL, "/")}
+}
+
+// OIDCProviderAlias ใช้ route เดิม (เช่น /auth/google/login) กับผู้ให้บริการที่กำหนด
+func OIDCProviderAlias(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Params = append(c.Params, gin.Param{Key: "provider", Value: name})
+		c.Next()
+	}
+}
+
+// GET /auth/oidc/:provider/login
+func (h *OIDCHandler) Login(c *gin.Context) {
+	provider, ok := h.provider(c)
+	if !ok {
+		return
+	}
+
+	state, err := oidc.NewLoginState(provider.Name(), oidcStateTTL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return
+	}
+	url, err := provider.AuthCodeURL(c.Request.Context(), state.State, state.Nonce, state.Verifier)
+	if err != nil {
+		log.Printf("oidc login: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Identity provider is unavailable"})
+		return
+	}
+	sealed, err := state.Seal(JWT_SECRET)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return
+	}
+
+	c.SetCookie(oidcStateCookie, sealed, int(oidcStateTTL.Seconds()), "/auth", "localhost", false, true)
+	// สั่ง Redirect (เด้ง) เบราว์เซอร์ของผู้ใช้ไปหน้า Login ของผู้ให้บริการ
+	c.Redirect(http.StatusTemporaryRedirect, url)
+}
+
+// GET /auth/oidc/:provider/callback
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	provider, ok := h.provider(c)
+	if !ok {
+		return
+	}
+
+	// state ใช้ได้ครั้งเดียว: ล้าง Cookie ทุกกรณี
+	sealed, _ := c.Cookie(oidcStateCookie)
+	c.SetCookie(oidcStateCookie, "", -1, "/auth", "localhost", false, true)
+
+	// ผู้ใช้กดยกเลิก หรือผู้ให้บริการปฏิเสธ
+	if e := c.Query("error"); e != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Login was not completed: " + e})
+		return
+	}
+
+	state, err := oidc.OpenLoginState(JWT_SECRET, sealed, provider.Name(), c.Query("state"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired login state, please try again"})
+		return
+	}
+	code := c.Query("code")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing authorization code"})
+		return
+	}
+
+	identity, err := provider.Exchange(c.Request.Context(), code, state.Verifier, state.Nonce)
+	if err != nil {
+		log.Printf("oidc callback: %v", err)
+		if errors.Is(err, oidc.ErrInvalidIDToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid ID token"})
+			return
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to exchange authorization code"})
+		return
+	}
+
+	tokens, err := h.userService.LoginWithIdentity(*identity)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrIdentityEmailRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrIdentityConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "An account with this email already exists, please log in with your password"})
+		case errors.Is(err, service.ErrEmailNotVerified):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Please verify your email address before logging in"})
+		default:
+			log.Printf("oidc login for %s: %v", provider.Name(), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		}
+		return
+	}
+
+	setAuthCookies(c, tokens)
+	// Redirect กลับไปหน้า Frontend
+	c.Redirect(http.StatusTemporaryRedirect, h.frontendURL+"/dashboard")
+}
+
+func (h *OIDCHandler) provider(c *gin.Context) (*oidc.Provider, bool) {
+	p, ok := h.providers[c.Param("provider")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown identity provider"})
+	}
+	return p, ok
+}
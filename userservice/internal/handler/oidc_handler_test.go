@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"users/internal/oidc"
+
+	"github.com/gin-gonic/gin"
+)
+
+// issuer ไม่มีอยู่จริง: ทุกกรณีในนี้ต้องถูกปฏิเสธก่อนติดต่อผู้ให้บริการ
+func newTestOIDCRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewOIDCHandler(nil, []*oidc.Provider{oidc.NewProvider(oidc.Config{Name: "mock", Issuer: "http://127.0.0.1:1", ClientID: "app"})}, "http://localhost:3000")
+	r := gin.New()
+	r.GET("/auth/oidc/:provider/callback", h.Callback)
+	return r
+}
+
+func sealTestState(t *testing.T, provider string, key []byte, ttl time.Duration) (*oidc.LoginState, string) {
+	t.Helper()
+	state, err := oidc.NewLoginState(provider, ttl)
+	if err != nil {
+		t.Fatalf("NewLoginState: %v", err)
+	}
+	sealed, err := state.Seal(key)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	return state, sealed
+}
+
+func TestOIDCCallbackRejectsState(t *testing.T) {
+	state, sealed := sealTestState(t, "mock", JWT_SECRET, time.Minute)
+	_, forged := sealTestState(t, "mock", []byte("attacker-key"), time.Minute)
+	_, otherProvider := sealTestState(t, "google", JWT_SECRET, time.Minute)
+	expiredState, expired := sealTestState(t, "mock", JWT_SECRET, -time.Minute)
+
+	tests := []struct {
+		name   string
+		cookie string
+		query  string
+		status int
+	}{
+		{"missing cookie", "", "state=" + state.State + "&code=c", http.StatusBadRequest},
+		{"forged cookie", forged, "state=" + state.State + "&code=c", http.StatusBadRequest},
+		{"garbage cookie", "not-a-state", "state=" + state.State + "&code=c", http.StatusBadRequest},
+		{"state mismatch", sealed, "state=other&code=c", http.StatusBadRequest},
+		{"missing state", sealed, "code=c", http.StatusBadRequest},
+		{"cookie for another provider", otherProvider, "state=" + state.State + "&code=c", http.StatusBadRequest},
+		{"expired cookie", expired, "state=" + expiredState.State + "&code=c", http.StatusBadRequest},
+		{"provider error", sealed, "error=access_denied&state=" + state.State, http.StatusBadRequest},
+		{"missing code", sealed, "state=" + state.State, http.StatusBadRequest},
+	}
+	router := newTestOIDCRouter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/auth/oidc/mock/callback?"+tt.query, nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: tt.cookie})
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.status, w.Body)
+			}
+			// state ใช้ได้ครั้งเดียว: ต้องล้าง Cookie แม้ถูกปฏิเสธ
+			if cleared := w.Header().Get("Set-Cookie"); !strings.HasPrefix(cleared, oidcStateCookie+"=;") || !strings.Contains(cleared, "Max-Age=0") {
+				t.Errorf("Set-Cookie = %q, want the state cookie cleared", cleared)
+			}
+		})
+	}
+}
+
+func TestOIDCCallbackUnknownProvider(t *testing.T) {
+	w := httptest.NewRecorder()
+	newTestOIDCRouter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/oidc/nope/callback?state=s&code=c", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", w.Code)
+	}
+}
@@ -1,26 +1,38 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"users/internal/middleware"
 	"users/internal/models"
 	"users/internal/repository"
+	"users/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
 type ProgramHandler struct {
-	repo repository.ProgramRepository
+	repo    repository.ProgramRepository
+	service service.ProgramService
+	owns    *middleware.OwnershipGuard
 }
 
-func NewProgramHandler(repo repository.ProgramRepository) *ProgramHandler {
-	return &ProgramHandler{repo: repo}
+func NewProgramHandler(repo repository.ProgramRepository, programService service.ProgramService, owns *middleware.OwnershipGuard) *ProgramHandler {
+	return &ProgramHandler{repo: repo, service: programService, owns: owns}
 }
 
-// GET /api/v1/programs (ดึงรายการโปรแกรม)
+// GET /api/v1/programs?q=&is_template=&client_id=&sort=&limit=&offset=|cursor= (ดึงรายการโปรแกรม)
 func (h *ProgramHandler) GetPrograms(c *gin.Context) {
-	trainerID, _ := c.Get("user_id")
-	programs, err := h.repo.GetProgramsByTrainerID(int(trainerID.(float64)))
+	p, ok := parseList(c, repository.ProgramList)
+	if !ok {
+		return
+	}
+	userID, _ := c.Get("user_id")
+	role, _ := c.Get("role")
+
+	// ลูกค้าเห็นเฉพาะโปรแกรมของตัวเอง
+	programs, err := h.repo.ListPrograms(int(userID.(float64)), role.(string), p)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch programs"})
 		return
@@ -28,23 +40,15 @@ func (h *ProgramHandler) GetPrograms(c *gin.Context) {
 	c.JSON(http.StatusOK, programs)
 }
 
-// GET /api/v1/programs/:id (ดึงรายละเอียด + ท่าฝึก)
+// GET /api/v1/programs/:id (ดึงรายละเอียด + โครงสร้าง Phase/Week/Day + ท่าฝึก)
 func (h *ProgramHandler) GetProgramDetail(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
 
-	// 1. ดึงข้อมูล Program
-	program, err := h.repo.GetProgramByID(id)
+	program, err := h.service.GetProgramDetail(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Program not found"})
+		respondProgramStructureError(c, err, "Failed to get program")
 		return
 	}
-
-	// 2. ดึงข้อมูล Exercises
-	exercises, err := h.repo.GetExercisesByProgramID(id)
-	if err == nil {
-		program.Exercises = exercises
-	}
-
 	c.JSON(http.StatusOK, program)
 }
 
@@ -87,6 +91,10 @@ func (h *ProgramHandler) CreateProgram(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
+	// โปรแกรมของลูกค้า (client_id) สร้างได้เฉพาะลูกค้าของตัวเอง
+	if req.ClientID != nil && !h.owns.CheckClient(c, *req.ClientID) {
+		return
+	}
 
 	trainerID, _ := c.Get("user_id")
 	req.TrainerID = int(trainerID.(float64))
@@ -101,7 +109,7 @@ func (h *ProgramHandler) CreateProgram(c *gin.Context) {
 	c.JSON(http.StatusCreated, req)
 }
 
-// POST /api/v1/programs/:id/exercises (เพิ่มท่าฝึกในโปรแกรม)
+// POST /api/v1/programs/:id/exercises (เพิ่มท่าฝึกในโปรแกรม; ส่ง day_id เพื่อใส่ในวันฝึก)
 func (h *ProgramHandler) AddExercise(c *gin.Context) {
 	programID, _ := strconv.Atoi(c.Param("id"))
 	var req models.ProgramExercise
@@ -111,9 +119,115 @@ func (h *ProgramHandler) AddExercise(c *gin.Context) {
 	}
 	req.ProgramID = programID
 
-	if err := h.repo.AddExercise(&req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add exercise"})
+	if err := h.service.AddExercise(&req); err != nil {
+		respondProgramStructureError(c, err, "Failed to add exercise")
 		return
 	}
 	c.JSON(http.StatusCreated, req)
 }
+
+// PUT /api/v1/programs/:id/exercises/:exerciseRowId (แก้ไขท่าฝึกในโปรแกรม; :exerciseRowId = id ของแถว program_exercises)
+func (h *ProgramHandler) UpdateExercise(c *gin.Context) {
+	programID, rowID, ok := programChildIDs(c, "exerciseRowId")
+	if !ok {
+		return
+	}
+	var req models.ProgramExercise
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	req.ID = rowID
+	req.ProgramID = programID
+
+	if err := h.service.UpdateExercise(&req); err != nil {
+		respondProgramStructureError(c, err, "Failed to update exercise")
+		return
+	}
+	c.JSON(http.StatusOK, req)
+}
+
+// DELETE /api/v1/programs/:id/exercises/:exerciseRowId (ลบท่าฝึกออกจากโปรแกรม)
+func (h *ProgramHandler) DeleteExercise(c *gin.Context) {
+	programID, rowID, ok := programChildIDs(c, "exerciseRowId")
+	if !ok {
+		return
+	}
+	if err := h.repo.DeleteExerciseFromProgram(programID, rowID); err != nil {
+		respondProgramStructureError(c, err, "Failed to remove exercise")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Exercise removed successfully"})
+}
+
+// POST /api/v1/programs/:id/exercises/reorder (เรียงท่าฝึกใหม่ทั้งวันในครั้งเดียว)
+// Body: {"day_id": 3, "exercise_ids": [12, 10, 11]} (ไม่ส่ง day_id = ท่าที่ไม่อยู่ในวันฝึกใด)
+func (h *ProgramHandler) ReorderExercises(c *gin.Context) {
+	programID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid program ID"})
+		return
+	}
+	var req struct {
+		DayID       *int  `json:"day_id"`
+		ExerciseIDs []int `json:"exercise_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if err := h.repo.ReorderProgramExercises(programID, req.DayID, req.ExerciseIDs); err != nil {
+		respondProgramStructureError(c, err, "Failed to reorder exercises")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Exercises reordered successfully"})
+}
+
+// POST /api/v1/programs/:id/assign (copy Template ให้ลูกค้า) Body: {"client_ids": [1, 2]}
+func (h *ProgramHandler) AssignProgram(c *gin.Context) {
+	templateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid program ID"})
+		return
+	}
+	var req struct {
+		ClientIDs []int `json:"client_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	programs, err := h.service.AssignProgram(templateID, req.ClientIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrProgramNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Program not found"})
+		case errors.Is(err, repository.ErrProgramClientNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrProgramNotTemplate), errors.Is(err, service.ErrInvalidAssignment):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign program"})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, programs)
+}
+
+// GET /api/v1/programs/:id/assignments (ลูกค้าที่ใช้โปรแกรมจาก Template นี้)
+func (h *ProgramHandler) GetProgramAssignments(c *gin.Context) {
+	templateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid program ID"})
+		return
+	}
+
+	assignments, err := h.repo.GetProgramAssignments(templateID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch program assignments"})
+		return
+	}
+	c.JSON(http.StatusOK, assignments)
+}
@@ -0,0 +1,308 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"users/internal/models"
+	"users/internal/repository"
+	"users/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- Program Structure: Phase -> Week -> Day ---
+// :id ผ่าน OwnershipGuard แล้ว ส่วน :weekId/:dayId ถูกตรวจใน Repository ว่าอยู่ในโปรแกรมนี้
+
+// POST /api/v1/programs/:id/phases
+func (h *ProgramHandler) CreatePhase(c *gin.Context) {
+	programID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid program ID"})
+		return
+	}
+	var req models.ProgramPhase
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	req.ProgramID = programID
+	req.Weeks = []models.ProgramWeek{}
+
+	if err := h.repo.CreatePhase(&req); err != nil {
+		respondProgramStructureError(c, err, "Failed to create phase")
+		return
+	}
+	c.JSON(http.StatusCreated, req)
+}
+
+// POST /api/v1/programs/:id/weeks (เพิ่มสัปดาห์ต่อท้าย)
+func (h *ProgramHandler) CreateWeek(c *gin.Context) {
+	programID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid program ID"})
+		return
+	}
+	var req models.ProgramWeek
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	req.ProgramID = programID
+	req.Days = []models.ProgramDay{}
+
+	if err := h.service.CreateWeek(&req); err != nil {
+		respondProgramStructureError(c, err, "Failed to create week")
+		return
+	}
+	c.JSON(http.StatusCreated, req)
+}
+
+// PUT /api/v1/programs/:id/weeks/:weekId (ชื่อ/Phase/Override)
+func (h *ProgramHandler) UpdateWeek(c *gin.Context) {
+	programID, weekID, ok := programChildIDs(c, "weekId")
+	if !ok {
+		return
+	}
+	var req models.ProgramWeek
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	req.ID = weekID
+	req.ProgramID = programID
+
+	if err := h.service.UpdateWeek(&req); err != nil {
+		respondProgramStructureError(c, err, "Failed to update week")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Week updated successfully"})
+}
+
+// DELETE /api/v1/programs/:id/weeks/:weekId
+func (h *ProgramHandler) DeleteWeek(c *gin.Context) {
+	programID, weekID, ok := programChildIDs(c, "weekId")
+	if !ok {
+		return
+	}
+	if err := h.repo.DeleteWeek(programID, weekID); err != nil {
+		respondProgramStructureError(c, err, "Failed to delete week")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Week deleted successfully"})
+}
+
+// POST /api/v1/programs/:id/weeks/:weekId/move Body: {"position": 3}
+func (h *ProgramHandler) MoveWeek(c *gin.Context) {
+	programID, weekID, ok := programChildIDs(c, "weekId")
+	if !ok {
+		return
+	}
+	var req struct {
+		Position int `json:"position" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "position must be a positive number"})
+		return
+	}
+
+	if err := h.repo.MoveWeek(programID, weekID, req.Position); err != nil {
+		respondProgramStructureError(c, err, "Failed to move week")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Week moved successfully"})
+}
+
+// POST /api/v1/programs/:id/weeks/:weekId/duplicate (copy ไว้ต่อจากสัปดาห์เดิม)
+func (h *ProgramHandler) DuplicateWeek(c *gin.Context) {
+	programID, weekID, ok := programChildIDs(c, "weekId")
+	if !ok {
+		return
+	}
+	week, err := h.service.DuplicateWeek(programID, weekID)
+	if err != nil {
+		respondProgramStructureError(c, err, "Failed to duplicate week")
+		return
+	}
+	c.JSON(http.StatusCreated, week)
+}
+
+// POST /api/v1/programs/:id/weeks/:weekId/days (เพิ่มวันฝึกต่อท้ายสัปดาห์)
+func (h *ProgramHandler) CreateDay(c *gin.Context) {
+	programID, weekID, ok := programChildIDs(c, "weekId")
+	if !ok {
+		return
+	}
+	var req models.ProgramDay
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	req.WeekID = weekID
+	req.Exercises = []models.ProgramExercise{}
+
+	if err := h.repo.CreateDay(programID, &req); err != nil {
+		respondProgramStructureError(c, err, "Failed to create day")
+		return
+	}
+	c.JSON(http.StatusCreated, req)
+}
+
+// DELETE /api/v1/programs/:id/days/:dayId
+func (h *ProgramHandler) DeleteDay(c *gin.Context) {
+	programID, dayID, ok := programChildIDs(c, "dayId")
+	if !ok {
+		return
+	}
+	if err := h.repo.DeleteDay(programID, dayID); err != nil {
+		respondProgramStructureError(c, err, "Failed to delete day")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Day deleted successfully"})
+}
+
+// POST /api/v1/programs/:id/days/:dayId/move Body: {"week_id": 5, "position": 1}
+func (h *ProgramHandler) MoveDay(c *gin.Context) {
+	programID, dayID, ok := programChildIDs(c, "dayId")
+	if !ok {
+		return
+	}
+	var req struct {
+		WeekID   int `json:"week_id" binding:"required"`
+		Position int `json:"position" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "week_id and a positive position are required"})
+		return
+	}
+
+	if err := h.repo.MoveDay(programID, dayID, req.WeekID, req.Position); err != nil {
+		respondProgramStructureError(c, err, "Failed to move day")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Day moved successfully"})
+}
+
+// POST /api/v1/programs/:id/days/:dayId/duplicate Body (optional): {"week_id": 6} (ไม่ระบุ = สัปดาห์เดิม)
+func (h *ProgramHandler) DuplicateDay(c *gin.Context) {
+	programID, dayID, ok := programChildIDs(c, "dayId")
+	if !ok {
+		return
+	}
+	var req struct {
+		WeekID *int `json:"week_id"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	weekID := 0
+	if req.WeekID != nil {
+		weekID = *req.WeekID
+	} else {
+		day, err := h.repo.GetDay(programID, dayID)
+		if err != nil {
+			respondProgramStructureError(c, err, "Failed to duplicate day")
+			return
+		}
+		weekID = day.WeekID
+	}
+
+	day, err := h.service.DuplicateDay(programID, dayID, weekID)
+	if err != nil {
+		respondProgramStructureError(c, err, "Failed to duplicate day")
+		return
+	}
+	c.JSON(http.StatusCreated, day)
+}
+
+// POST /api/v1/programs/:id/days/:dayId/blocks (เพิ่ม Block ต่อท้ายวันฝึก)
+// Body: {"block_type": "superset", "rounds": 3, "rest_seconds": 90}
+func (h *ProgramHandler) CreateBlock(c *gin.Context) {
+	programID, dayID, ok := programChildIDs(c, "dayId")
+	if !ok {
+		return
+	}
+	var req models.ProgramBlock
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	req.DayID = dayID
+	req.Exercises = []models.ProgramExercise{}
+
+	if err := h.service.CreateBlock(programID, &req); err != nil {
+		respondProgramStructureError(c, err, "Failed to create block")
+		return
+	}
+	c.JSON(http.StatusCreated, req)
+}
+
+// PUT /api/v1/programs/:id/blocks/:blockId
+func (h *ProgramHandler) UpdateBlock(c *gin.Context) {
+	programID, blockID, ok := programChildIDs(c, "blockId")
+	if !ok {
+		return
+	}
+	var req models.ProgramBlock
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	req.ID = blockID
+
+	if err := h.service.UpdateBlock(programID, &req); err != nil {
+		respondProgramStructureError(c, err, "Failed to update block")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Block updated successfully"})
+}
+
+// DELETE /api/v1/programs/:id/blocks/:blockId (ท่าฝึกใน Block กลายเป็นท่าเดี่ยว)
+func (h *ProgramHandler) DeleteBlock(c *gin.Context) {
+	programID, blockID, ok := programChildIDs(c, "blockId")
+	if !ok {
+		return
+	}
+	if err := h.repo.DeleteBlock(programID, blockID); err != nil {
+		respondProgramStructureError(c, err, "Failed to delete block")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Block deleted successfully"})
+}
+
+// programChildIDs อ่าน :id และ id ลูก (:weekId/:dayId/:blockId/...) ตอบ 400 เองถ้าไม่ถูกต้อง
+func programChildIDs(c *gin.Context, param string) (int, int, bool) {
+	programID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid program ID"})
+		return 0, 0, false
+	}
+	childID, err := strconv.Atoi(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param})
+		return 0, 0, false
+	}
+	return programID, childID, true
+}
+
+func respondProgramStructureError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrProgramNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Program not found"})
+	case errors.Is(err, repository.ErrProgramPhaseNotFound),
+		errors.Is(err, repository.ErrProgramWeekNotFound),
+		errors.Is(err, repository.ErrProgramDayNotFound),
+		errors.Is(err, repository.ErrProgramBlockNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrProgramExerciseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Program exercise not found"})
+	case errors.Is(err, repository.ErrExerciseNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Exercise not found"})
+	case errors.Is(err, service.ErrInvalidProgramWeek),
+		errors.Is(err, service.ErrInvalidProgramExercise),
+		errors.Is(err, service.ErrInvalidProgramBlock),
+		errors.Is(err, repository.ErrInvalidExerciseOrder):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
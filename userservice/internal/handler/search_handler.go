@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"users/internal/authz"
+	"users/internal/models"
+	"users/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// สิทธิ์ที่ต้องมีเพื่อค้นหาแต่ละชนิด
+var searchPermissions = map[string]authz.Permission{
+	models.SearchTypeClients:   authz.PermClientsRead,
+	models.SearchTypeNotes:     authz.PermNotesRead,
+	models.SearchTypePrograms:  authz.PermProgramsRead,
+	models.SearchTypeExercises: authz.PermExercisesRead,
+}
+
+type SearchHandler struct {
+	service service.SearchService
+	policy  *authz.Policy
+}
+
+func NewSearchHandler(s service.SearchService, policy *authz.Policy) *SearchHandler {
+	return &SearchHandler{service: s, policy: policy}
+}
+
+// GET /api/v1/search?q=&types=clients,notes,programs,exercises&limit=5
+// types ว่าง = ทุกชนิดที่ Role มีสิทธิ์อ่าน, limit = จำนวนผลต่อกลุ่ม (สูงสุด 20)
+func (h *SearchHandler) Search(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	role, _ := c.Get("role")
+	roleName, _ := role.(string)
+
+	var types []string
+	if raw := strings.TrimSpace(c.Query("types")); raw != "" {
+		for _, t := range strings.Split(raw, ",") {
+			t = strings.TrimSpace(t)
+			perm, ok := searchPermissions[t]
+			if !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": service.ErrInvalidSearchType.Error()})
+				return
+			}
+			if !h.policy.Can(roleName, perm) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to search " + t})
+				return
+			}
+			types = append(types, t)
+		}
+	} else {
+		for _, t := range models.SearchTypes {
+			if h.policy.Can(roleName, searchPermissions[t]) {
+				types = append(types, t)
+			}
+		}
+		if len(types) == 0 {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			return
+		}
+	}
+
+	limit := service.SearchDefaultLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > service.SearchMaxLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(service.SearchMaxLimit)})
+			return
+		}
+		limit = n
+	}
+
+	resp, err := h.service.Search(service.SearchQuery{
+		UserID: int(userID.(float64)),
+		Role:   roleName,
+		Text:   c.Query("q"),
+		Types:  types,
+		Limit:  limit,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrSearchQueryLength) || errors.Is(err, service.ErrInvalidSearchType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search"})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -1,35 +1,49 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"users/internal/middleware"
 	"users/internal/models"
 	"users/internal/repository"
+	"users/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
 type SessionHandler struct {
-	repo repository.SessionRepository
+	repo            repository.SessionRepository
+	service         service.SessionService
+	scheduleService service.ScheduleService
+	owns            *middleware.OwnershipGuard
 }
 
-func NewSessionHandler(repo repository.SessionRepository) *SessionHandler {
-	return &SessionHandler{repo: repo}
+func NewSessionHandler(repo repository.SessionRepository, sessionService service.SessionService, scheduleService service.ScheduleService, owns *middleware.OwnershipGuard) *SessionHandler {
+	return &SessionHandler{repo: repo, service: sessionService, scheduleService: scheduleService, owns: owns}
 }
 
-// POST /api/v1/sessions (สร้างนัดหมาย)
+// POST /api/v1/sessions?conflicts=reject|warn (สร้างนัดหมาย; ชนกับนัดเดิมได้ 409 เหมือน POST /schedules)
 func (h *SessionHandler) CreateSession(c *gin.Context) {
+	allowConflicts, ok := conflictMode(c)
+	if !ok {
+		return
+	}
 	var req models.Schedule
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
 
+	if !h.owns.CheckClient(c, req.ClientID) {
+		return
+	}
+
 	trainerID, _ := c.Get("user_id")
 	req.TrainerID = int(trainerID.(float64))
 
-	if err := h.repo.CreateSchedule(&req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+	if err := h.scheduleService.CreateSchedule(&req, allowConflicts); err != nil {
+		respondScheduleError(c, err, "Failed to create session")
 		return
 	}
 	c.JSON(http.StatusCreated, req)
@@ -47,21 +61,141 @@ func (h *SessionHandler) GetClientSessions(c *gin.Context) {
 	c.JSON(http.StatusOK, sessions)
 }
 
-// POST /api/v1/sessions/:id/logs (บันทึกผลการฝึก)
-// (อันนี้ซับซ้อนหน่อย เพราะ Frontend อาจส่งมาเป็น Array ของ Exercises)
-// เอาแบบง่ายก่อนคือรับทีละ Log
+// POST /api/v1/sessions/:id/logs (บันทึกผลการฝึกทั้ง Session)
+// Body: {"exercises": [{"exercise_id": 1, "notes": "", "sets": [{"set_number": 1, "weight_kg": 60, "reps": 8, "rpe": 8}]}]}
+// ทุกท่าและทุกเซตถูกบันทึกใน Transaction เดียว (สำเร็จทั้งหมด หรือไม่บันทึกเลย)
+// Response มี new_prs = PR ใหม่ที่เกิดขึ้นใน Session นี้
 func (h *SessionHandler) CreateLog(c *gin.Context) {
 	scheduleID, _ := strconv.Atoi(c.Param("id"))
+	var req models.WorkoutLog
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if err := h.service.CreateWorkoutLog(scheduleID, &req); err != nil {
+		switch {
+		case errors.Is(err, service.ErrScheduleNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		case errors.Is(err, service.ErrScheduleCancelled):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrInvalidWorkoutLog):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log session"})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, req)
+}
+
+// GET /api/v1/sessions/:id/logs (ดึงผลการฝึกของ Session พร้อมเซตทั้งหมดและข้อมูลท่า)
+func (h *SessionHandler) GetLogs(c *gin.Context) {
+	scheduleID, _ := strconv.Atoi(c.Param("id"))
+
+	logs, err := h.repo.GetLogsByScheduleID(scheduleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch session logs"})
+		return
+	}
+	c.JSON(http.StatusOK, logs)
+}
+
+// PUT /api/v1/sessions/:id/logs/:logId
+// Body: {"exercise_id": 1, "notes": "", "sets": [...]} (ถ้าไม่ส่ง sets จะคงเซตเดิมไว้, ส่ง [] = ลบเซตทั้งหมด)
+func (h *SessionHandler) UpdateLog(c *gin.Context) {
+	scheduleID, _ := strconv.Atoi(c.Param("id"))
+	logID, err := strconv.Atoi(c.Param("logId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid log ID"})
+		return
+	}
+
 	var req models.SessionLog
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
+	req.ID = logID
 	req.ScheduleID = scheduleID
 
-	if err := h.repo.CreateSessionLog(&req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log session"})
+	updated, err := h.service.UpdateSessionLog(&req)
+	if err != nil {
+		respondSessionLogError(c, err, "Failed to update session log")
 		return
 	}
-	c.JSON(http.StatusCreated, req)
+	c.JSON(http.StatusOK, updated)
+}
+
+// DELETE /api/v1/sessions/:id/logs/:logId (ลบ Log พร้อมเซตทั้งหมด)
+func (h *SessionHandler) DeleteLog(c *gin.Context) {
+	scheduleID, _ := strconv.Atoi(c.Param("id"))
+	logID, err := strconv.Atoi(c.Param("logId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid log ID"})
+		return
+	}
+
+	if err := h.service.DeleteSessionLog(scheduleID, logID); err != nil {
+		respondSessionLogError(c, err, "Failed to delete session log")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Session log deleted successfully"})
+}
+
+// PUT /api/v1/sessions/:id/logs/:logId/sets/:setId (แก้ไขเซตเดียว เช่น พิมพ์น้ำหนักผิด)
+func (h *SessionHandler) UpdateLogSet(c *gin.Context) {
+	scheduleID, _ := strconv.Atoi(c.Param("id"))
+	logID, err1 := strconv.Atoi(c.Param("logId"))
+	setID, err2 := strconv.Atoi(c.Param("setId"))
+	if err1 != nil || err2 != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid log or set ID"})
+		return
+	}
+
+	var req models.SessionLogSet
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	req.ID = setID
+	req.SessionLogID = logID
+
+	if err := h.service.UpdateSessionLogSet(scheduleID, &req); err != nil {
+		respondSessionLogError(c, err, "Failed to update set")
+		return
+	}
+	c.JSON(http.StatusOK, req)
+}
+
+// DELETE /api/v1/sessions/:id/logs/:logId/sets/:setId
+func (h *SessionHandler) DeleteLogSet(c *gin.Context) {
+	scheduleID, _ := strconv.Atoi(c.Param("id"))
+	logID, err1 := strconv.Atoi(c.Param("logId"))
+	setID, err2 := strconv.Atoi(c.Param("setId"))
+	if err1 != nil || err2 != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid log or set ID"})
+		return
+	}
+
+	if err := h.service.DeleteSessionLogSet(scheduleID, logID, setID); err != nil {
+		respondSessionLogError(c, err, "Failed to delete set")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Set deleted successfully"})
+}
+
+func respondSessionLogError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrScheduleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+	case errors.Is(err, repository.ErrSessionLogNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session log not found"})
+	case errors.Is(err, repository.ErrSessionLogSetNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Set not found"})
+	case errors.Is(err, service.ErrInvalidWorkoutLog):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
 }
@@ -1,29 +1,38 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"users/internal/middleware"
 	"users/internal/models"
 	"users/internal/repository"
+	"users/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
 type TrainingHandler struct {
-	repo repository.TrainingRepository
+	repo            repository.TrainingRepository
+	scheduleService service.ScheduleService
+	// ตรวจ client_id ใน Body ว่าเป็นลูกค้าของผู้ใช้
+	owns *middleware.OwnershipGuard
 }
 
-func NewTrainingHandler(repo repository.TrainingRepository) *TrainingHandler {
-	return &TrainingHandler{repo: repo}
+func NewTrainingHandler(repo repository.TrainingRepository, scheduleService service.ScheduleService, owns *middleware.OwnershipGuard) *TrainingHandler {
+	return &TrainingHandler{repo: repo, scheduleService: scheduleService, owns: owns}
 }
 
-// GET /api/v1/clients (เปลี่ยนชื่อจาก GetMyTrainees)
+// GET /api/v1/clients?q=&goal=&activity_level=&has_account=&sort=&limit=&offset=|cursor= (เปลี่ยนชื่อจาก GetMyTrainees)
 func (h *TrainingHandler) GetClients(c *gin.Context) {
+	p, ok := parseList(c, repository.ClientList)
+	if !ok {
+		return
+	}
 	trainerID, _ := c.Get("user_id")
 	id := int(trainerID.(float64))
 
-	// เรียก Repo ใหม่ที่คืนค่า []models.Client
-	clients, err := h.repo.GetClientsByTrainerID(id)
+	clients, err := h.repo.ListClients(id, p)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -65,12 +74,16 @@ func (h *TrainingHandler) GetPrograms(c *gin.Context) {
 	c.JSON(http.StatusOK, programs)
 }
 
-// GET /api/v1/schedules
+// GET /api/v1/schedules?status=&from=&to=&client_id=&series_id=&sort=&limit=&offset=|cursor=
 func (h *TrainingHandler) GetSchedules(c *gin.Context) {
+	p, ok := parseList(c, repository.ScheduleList)
+	if !ok {
+		return
+	}
 	userID, _ := c.Get("user_id")
 	role, _ := c.Get("role")
 
-	schedules, err := h.repo.GetSchedulesByUserID(int(userID.(float64)), role.(string))
+	schedules, err := h.repo.ListSchedules(int(userID.(float64)), role.(string), p)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -78,12 +91,16 @@ func (h *TrainingHandler) GetSchedules(c *gin.Context) {
 	c.JSON(http.StatusOK, schedules)
 }
 
-// GET /api/v1/assignments
+// GET /api/v1/assignments?status=&due_from=&due_to=&client_id=&sort=&limit=&offset=|cursor=
 func (h *TrainingHandler) GetAssignments(c *gin.Context) {
+	p, ok := parseList(c, repository.AssignmentList)
+	if !ok {
+		return
+	}
 	userID, _ := c.Get("user_id")
 	role, _ := c.Get("role")
 
-	assignments, err := h.repo.GetAssignmentsByUserID(int(userID.(float64)), role.(string))
+	assignments, err := h.repo.ListAssignments(int(userID.(float64)), role.(string), p)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -101,6 +118,10 @@ func (h *TrainingHandler) CreateProgram(c *gin.Context) {
 		return
 	}
 
+	if req.ClientID != nil && !h.owns.CheckClient(c, *req.ClientID) {
+		return
+	}
+
 	// ดึง Trainer ID จาก Token (คนที่ Login อยู่คือคนสร้าง)
 	trainerID, _ := c.Get("user_id")
 	req.TrainerID = int(trainerID.(float64))
@@ -113,20 +134,29 @@ func (h *TrainingHandler) CreateProgram(c *gin.Context) {
 	c.JSON(http.StatusCreated, req)
 }
 
-// POST /api/v1/schedules
+// POST /api/v1/schedules?conflicts=reject|warn
+// นัดที่ชนกับนัดเดิมของเทรนเนอร์/ลูกค้าจะได้ 409 (ส่ง conflicts=warn เพื่อบันทึกต่อและดูรายการที่ชนใน Response)
 func (h *TrainingHandler) CreateSchedule(c *gin.Context) {
+	allowConflicts, ok := conflictMode(c)
+	if !ok {
+		return
+	}
 	var req models.Schedule
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
 
+	if !h.owns.CheckClient(c, req.ClientID) {
+		return
+	}
+
 	// ดึง Trainer ID จาก Token
 	trainerID, _ := c.Get("user_id")
 	req.TrainerID = int(trainerID.(float64))
 
-	if err := h.repo.CreateSchedule(&req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create schedule"})
+	if err := h.scheduleService.CreateSchedule(&req, allowConflicts); err != nil {
+		respondScheduleError(c, err, "Failed to create schedule")
 		return
 	}
 
@@ -150,12 +180,13 @@ func (h *TrainingHandler) CreateAssignment(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Client ID is required for assignment"})
 		return
 	}
-
-	// ตั้ง Status เป็น pending (ถ้า Frontend ไม่ได้ส่งมา)
-	if req.Status == "" {
-		req.Status = "pending"
+	if !h.owns.CheckClient(c, req.ClientID) {
+		return
 	}
 
+	// งานใหม่เริ่มที่ pending เสมอ (เปลี่ยนสถานะต่อผ่าน /start, /submit, /review)
+	req.Status = models.AssignmentPending
+
 	// เรียก Repository เพื่อสร้าง Assignment
 	if err := h.repo.CreateAssignment(&req); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create assignment"})
@@ -165,42 +196,129 @@ func (h *TrainingHandler) CreateAssignment(c *gin.Context) {
 	c.JSON(http.StatusCreated, req)
 }
 
-// PUT /api/v1/schedules/:id
-func (h *TrainingHandler) UpdateSchedule(c *gin.Context) {
-	var req models.Schedule
+// POST /api/v1/schedules/series?conflicts=reject|warn (สร้างนัดแบบเกิดซ้ำ แล้วขยายเป็นนัดแต่ละครั้ง)
+// Body: {"title": "PT", "client_id": 1, "start_time": "2024-01-01T18:00:00+07:00", "end_time": "2024-01-01T19:00:00+07:00", "rrule": "FREQ=WEEKLY;BYDAY=MO,WE,FR;UNTIL=20240331", "timezone": "Asia/Bangkok"}
+func (h *TrainingHandler) CreateScheduleSeries(c *gin.Context) {
+	allowConflicts, ok := conflictMode(c)
+	if !ok {
+		return
+	}
+	var req models.ScheduleSeries
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
 
-	id, _ := strconv.Atoi(c.Param("id"))
-	req.ID = id
+	// ตรวจลูกค้าก่อนขยายนัดและตรวจการชน
+	if !h.owns.CheckClient(c, req.ClientID) {
+		return
+	}
 
 	trainerID, _ := c.Get("user_id")
 	req.TrainerID = int(trainerID.(float64))
 
-	if err := h.repo.UpdateSchedule(&req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.scheduleService.CreateSeries(&req, allowConflicts); err != nil {
+		respondScheduleError(c, err, "Failed to create schedule series")
 		return
 	}
-	c.JSON(http.StatusOK, req)
+	series, err := h.scheduleService.GetSeries(req.ID)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to create schedule series")
+		return
+	}
+	series.Conflicts = req.Conflicts
+	c.JSON(http.StatusCreated, series)
 }
 
-// DELETE /api/v1/schedules/:id
-func (h *TrainingHandler) DeleteSchedule(c *gin.Context) {
+// GET /api/v1/schedules/series/:id (series พร้อมนัดทั้งหมด)
+func (h *TrainingHandler) GetScheduleSeries(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
-	trainerID, _ := c.Get("user_id")
 
-	err := h.repo.DeleteSchedule(id, int(trainerID.(float64)))
+	series, err := h.scheduleService.GetSeries(id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete schedule"})
+		respondScheduleError(c, err, "Failed to get schedule series")
+		return
+	}
+	c.JSON(http.StatusOK, series)
+}
+
+// PUT /api/v1/schedules/:id?scope=this|following|all&conflicts=reject|warn
+// นัดใน series: this = แก้เฉพาะครั้งนี้, following = ครั้งนี้และครั้งถัดไป (แยกเป็น series ใหม่), all = ทั้ง series
+// scope=this ตอบกลับเป็นนัด, following/all ตอบกลับเป็น series
+func (h *TrainingHandler) UpdateSchedule(c *gin.Context) {
+	allowConflicts, ok := conflictMode(c)
+	if !ok {
+		return
+	}
+	var req models.ScheduleUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	// ไม่ส่ง client_id = ลูกค้าเดิม, ย้ายไปลูกค้าอื่นได้เฉพาะลูกค้าของตัวเอง
+	if req.ClientID != 0 && !h.owns.CheckClient(c, req.ClientID) {
+		return
+	}
+
+	id, _ := strconv.Atoi(c.Param("id"))
+	schedule, series, err := h.scheduleService.UpdateSchedule(id, &req, c.DefaultQuery("scope", service.ScopeThis), allowConflicts)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to update schedule")
+		return
+	}
+	if series != nil {
+		c.JSON(http.StatusOK, series)
+		return
+	}
+	c.JSON(http.StatusOK, schedule)
+}
+
+// DELETE /api/v1/schedules/:id?scope=this|following|all
+// นัดใน series: this = ยกเลิกเฉพาะครั้งนี้, following = ตัด series ตั้งแต่ครั้งนี้, all = ลบทั้ง series
+// (นัดที่บันทึกผลแล้วจะไม่ถูกลบ)
+func (h *TrainingHandler) DeleteSchedule(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	if err := h.scheduleService.DeleteSchedule(id, c.DefaultQuery("scope", service.ScopeThis)); err != nil {
+		respondScheduleError(c, err, "Failed to delete schedule")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Schedule deleted"})
 }
 
-// PUT /api/v1/assignments/:id
+// conflictMode อ่าน ?conflicts= (reject = ค่าเริ่มต้น ตอบ 409, warn = บันทึกได้แต่แนบรายการที่ชน)
+func conflictMode(c *gin.Context) (allowConflicts bool, ok bool) {
+	switch c.DefaultQuery("conflicts", "reject") {
+	case "reject":
+		return false, true
+	case "warn":
+		return true, true
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "conflicts must be one of: reject, warn"})
+	return false, false
+}
+
+func respondScheduleError(c *gin.Context, err error, fallback string) {
+	var conflictErr *service.ScheduleConflictError
+	if errors.As(err, &conflictErr) {
+		c.JSON(http.StatusConflict, gin.H{"error": service.ErrScheduleConflict.Error(), "conflicts": conflictErr.Conflicts})
+		return
+	}
+
+	switch {
+	case errors.Is(err, service.ErrScheduleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+	case errors.Is(err, service.ErrSeriesNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schedule series not found"})
+	case errors.Is(err, service.ErrInvalidScope), errors.Is(err, service.ErrInvalidSchedule):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// PUT /api/v1/assignments/:id (แก้รายละเอียด/due_date; status ที่ส่งมาจะถูกละไว้)
 func (h *TrainingHandler) UpdateAssignment(c *gin.Context) {
 	var req models.Assignment
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -211,10 +329,30 @@ func (h *TrainingHandler) UpdateAssignment(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
 	req.ID = id
 
+	currentClientID, err := h.repo.GetAssignmentClientID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrAssignmentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Assignment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update assignment"})
+		return
+	}
+	// ไม่ส่ง client_id = คงลูกค้าเดิม; ย้ายงานไปลูกค้าคนอื่นได้เฉพาะลูกค้าของตัวเอง
+	if req.ClientID == 0 {
+		req.ClientID = currentClientID
+	} else if req.ClientID != currentClientID && !h.owns.CheckClient(c, req.ClientID) {
+		return
+	}
+
 	trainerID, _ := c.Get("user_id")
 	req.TrainerID = int(trainerID.(float64))
 
 	if err := h.repo.UpdateAssignment(&req); err != nil {
+		if errors.Is(err, repository.ErrAssignmentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Assignment not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
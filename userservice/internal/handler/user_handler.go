@@ -1,49 +1,28 @@
 package handler
 
 import (
-	"context"
-	"encoding/json"
-	"io"
+	"errors"
 	"log"
 	"net/http"
 	"os"
 	"strconv" // (เพิ่ม import นี้ สำหรับ GetUserByID)
 
-	"users/internal/models"
+	"users/internal/repository"
 	"users/internal/service"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
 )
 
 // (ตัวแปร global สำหรับเก็บ Config)
 var (
-	googleOauthConfig *oauth2.Config
 	// (ย้าย JWT_SECRET มาไว้ที่นี่ และอ่านจาก .env)
 	JWT_SECRET []byte
 )
 
 // init() จะทำงาน *ก่อน* main() เสมอ
+// (Google OAuth ย้ายไปเป็นผู้ให้บริการ OIDC ใน oidc_handler.go แล้ว)
 func init() {
-	// 1. ตั้งค่า Google OAuth
-	clientID := os.Getenv("GOOGLE_CLIENT_ID")
-	clientSecret := os.Getenv("GOOGLE_CLIENT_SECRET")
-	redirectURL := os.Getenv("GOOGLE_REDIRECT_URL")
-
-	if clientID == "" || clientSecret == "" {
-		log.Println("WARNING: GOOGLE_CLIENT_ID or GOOGLE_CLIENT_SECRET is not set. Google OAuth will not work.")
-	}
-
-	googleOauthConfig = &oauth2.Config{
-		ClientID:     clientID,
-		ClientSecret: clientSecret,
-		RedirectURL:  redirectURL,
-		Scopes:       []string{"https://www.googleapis.com/auth/userinfo.email", "https://www.googleapis.com/auth/userinfo.profile"},
-		Endpoint:     google.Endpoint,
-	}
-
-	// 2. ตั้งค่า JWT Secret (จาก .env)
+	// ตั้งค่า JWT Secret (จาก .env)
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
 		log.Println("WARNING: JWT_SECRET is not set. Using default (unsafe) secret.")
@@ -65,8 +44,13 @@ func NewUserHandler(us service.UserService) *UserHandler {
 // (แก้ไข) ฟังก์ชัน CRUD เดิม (เติม Logic ให้สมบูรณ์)
 // ----------------------------------------------------
 
+// GET /api/v1/users?q=&role=&verified=&sort=&limit=&offset=|cursor=
 func (h *UserHandler) GetAllUsers(c *gin.Context) {
-	users, err := h.userService.GetAllUsers()
+	p, ok := parseList(c, repository.UserList)
+	if !ok {
+		return
+	}
+	users, err := h.userService.GetAllUsers(p)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get users"})
 		return
@@ -174,6 +158,14 @@ func (h *UserHandler) Register(c *gin.Context) {
 	// เรียก Service (Logic การ Hash อยู่ใน Service แล้ว)
 	user, err := h.userService.RegisterUser(req)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidEmail) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email address"})
+			return
+		}
+		if errors.Is(err, service.ErrPasswordTooShort) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -197,7 +189,11 @@ func (h *UserHandler) Login(c *gin.Context) {
 	// ++++++++++++++++++++++++++++++++++++++
 
 	// เรียก Service
-	accessToken, err := h.userService.LoginUser(req)
+	tokens, err := h.userService.LoginUser(req)
+	if errors.Is(err, service.ErrEmailNotVerified) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Please verify your email address before logging in"})
+		return
+	}
 	if err != nil {
 		// (ปรับ Error Message ให้ผู้ใช้เข้าใจง่ายขึ้น ไม่ควรส่ง raw error)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
@@ -205,109 +201,143 @@ func (h *UserHandler) Login(c *gin.Context) {
 	}
 
 	// ตั้งค่า httpOnly Cookie (จากเอกสาร Auth Part 1)
-	c.SetCookie("access_token", accessToken, 15*60, "/", "localhost", false, true) // httpOnly=true
+	setAuthCookies(c, tokens)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Login successful"})
 }
 
-func (h *UserHandler) Logout(c *gin.Context) {
-	// ล้าง Cookie
-	c.SetCookie("access_token", "", -1, "/", "localhost", false, true)
-	c.JSON(http.StatusOK, gin.H{"message": "Logout successful"})
-}
+// POST /auth/refresh (ขอ Access Token ใหม่ด้วย Refresh Token + หมุน Refresh Token)
+func (h *UserHandler) Refresh(c *gin.Context) {
+	refreshToken, err := c.Cookie("refresh_token")
+	if err != nil || refreshToken == "" {
+		// ถ้าไม่มี Cookie, ลองอ่านจาก Body (เผื่อ client ที่ไม่ใช่ Browser)
+		var req struct {
+			RefreshToken string `json:"refresh_token"`
+		}
+		_ = c.ShouldBindJSON(&req)
+		refreshToken = req.RefreshToken
+	}
+	if refreshToken == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing refresh token"})
+		return
+	}
 
-// (Logic ให้ GoogleLogin)
-func (h *UserHandler) GoogleLogin(c *gin.Context) {
-	state := "random-state-string-for-csrf-protection" // (ในระบบจริงควรสุ่มค่านี้)
-	url := googleOauthConfig.AuthCodeURL(state)
+	tokens, err := h.userService.RefreshTokens(refreshToken)
+	if err != nil {
+		clearAuthCookies(c)
+		switch {
+		case errors.Is(err, service.ErrRefreshTokenReused):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token reuse detected, please log in again"})
+		case errors.Is(err, service.ErrInvalidRefreshToken):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		}
+		return
+	}
 
-	// สั่ง Redirect (เด้ง) เบราว์เซอร์ของผู้ใช้ไปหน้า Login ของ Google
-	c.Redirect(http.StatusTemporaryRedirect, url)
+	setAuthCookies(c, tokens)
+	c.JSON(http.StatusOK, gin.H{"message": "Token refreshed"})
 }
 
-// (Logic ให้ GoogleCallback)
-func (h *UserHandler) GoogleCallback(c *gin.Context) {
-	// 1. รับ "code" ที่ Google ส่งกลับมา
-	code := c.Query("code")
-
-	// 2. นำ "code" ไปแลกเป็น "Google Token"
-	token, err := googleOauthConfig.Exchange(context.Background(), code)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to exchange token"})
+// POST /auth/verify-email
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	var req struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
-
-	// 3. นำ "Google Token" ไปขอข้อมูลโปรไฟล์ผู้ใช้
-	response, err := http.Get("https://www.googleapis.com/oauth2/v2/userinfo?access_token=" + token.AccessToken)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user info"})
+	if err := h.userService.VerifyEmail(req.Token); err != nil {
+		respondUserTokenError(c, err, "Failed to verify email")
 		return
 	}
-	defer response.Body.Close()
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified"})
+}
 
-	body, err := io.ReadAll(response.Body)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read user info"})
+// POST /auth/verify-email/resend
+func (h *UserHandler) ResendVerification(c *gin.Context) {
+	var req struct {
+		Email string `json:"email" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
-
-	// 4. (Flow 3) ค้นหา หรือ สร้าง User
-	var googleUser struct {
-		Email string `json:"email"`
-		Name  string `json:"name"`
+	if err := h.userService.ResendVerification(req.Email); err != nil {
+		log.Printf("failed to resend verification email: %v", err)
 	}
-	json.Unmarshal(body, &googleUser)
+	// ตอบเหมือนกันทุกกรณี (ไม่เปิดเผยว่ามีอีเมลนี้ในระบบหรือไม่)
+	c.JSON(http.StatusOK, gin.H{"message": "If the account exists and is not verified, a verification email has been sent"})
+}
 
-	// (a) ค้นหา User ด้วย Email
-	user, err := h.userService.GetUserByEmail(googleUser.Email)
-	if err != nil {
-		// (b) ถ้า "ไม่เจอ" (Sign Up) -> สร้าง User ใหม่
-		if err.Error() == "user not found" {
-			// สร้าง User ใหม่ด้วย Role "client"
-			newUser := models.User{
-				Name:  googleUser.Name,
-				Email: googleUser.Email,
-				Role:  "trainer",
-			}
-
-			// (แก้ไข) ใช้ Password สั้นๆ ที่แน่นอน
-			createdUser, err := h.userService.RegisterUser(service.RegisterRequest{
-				FirstName: newUser.Name,
-				LastName:  "", // (Google อาจจะไม่ได้แยกชื่อมาให้)
-				Email:     newUser.Email,
-				Password:  "google_user_placeholder_password",
-			})
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create new user via Google"})
-				return
-			}
-			user = createdUser
-		} else {
-			// ถ้า Error อื่น (เช่น DB ล่ม)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error on user lookup"})
-			return
-		}
+// POST /auth/forgot-password
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var req struct {
+		Email string `json:"email" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
 	}
+	if err := h.userService.RequestPasswordReset(req.Email); err != nil {
+		log.Printf("failed to send password reset email: %v", err)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "If the account exists, a password reset email has been sent"})
+}
 
-	// (c) ถ้า "เจอ" (Log In) -> เราได้ `user` มาแล้ว
+// POST /auth/reset-password
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if err := h.userService.ResetPassword(req.Token, req.Password); err != nil {
+		respondUserTokenError(c, err, "Failed to reset password")
+		return
+	}
+	// Refresh Token ทั้งหมดถูกยกเลิกแล้ว ล้าง Cookie ของอุปกรณ์นี้ด้วย
+	clearAuthCookies(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset, please log in again"})
+}
 
-	// 5. สร้าง JWT Token และตั้ง Cookie (เหมือน `Login` Handler)
-	// (เราได้ปรับ Logic ใน service.LoginUser ให้รองรับ Password ว่างเปล่าแล้ว)
-	accessToken, err := h.userService.LoginUser(service.LoginRequest{
-		Email:    user.Email,
-		Password: "", // (ส่ง Password ว่างเปล่าไป)
-	})
+func respondUserTokenError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrUserTokenInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
+	case errors.Is(err, service.ErrPasswordTooShort):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
 
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in user after Google auth"})
-		return
+func (h *UserHandler) Logout(c *gin.Context) {
+	// ยกเลิก Refresh Token ฝั่ง Server ก่อน แล้วค่อยล้าง Cookie
+	refreshToken, _ := c.Cookie("refresh_token")
+	if err := h.userService.Logout(refreshToken); err != nil {
+		log.Printf("failed to revoke refresh token on logout: %v", err)
 	}
 
-	// 6. ตั้งค่า httpOnly Cookie
-	c.SetCookie("access_token", accessToken, 15*60, "/", "localhost", false, true)
+	// ล้าง Cookie
+	clearAuthCookies(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Logout successful"})
+}
+
+// setAuthCookies ตั้ง Access Token (ใช้กับทุก path) และ Refresh Token (ส่งเฉพาะ /auth)
+func setAuthCookies(c *gin.Context, tokens *service.TokenPair) {
+	c.SetCookie("access_token", tokens.AccessToken, int(service.AccessTokenTTL.Seconds()), "/", "localhost", false, true) // httpOnly=true
+	c.SetCookie("refresh_token", tokens.RefreshToken, int(service.RefreshTokenTTL.Seconds()), "/auth", "localhost", false, true)
+}
 
-	// 7. (สำคัญ) Redirect กลับไปหน้า Frontend
-	c.Redirect(http.StatusTemporaryRedirect, "http://localhost:3000/dashboard")
+func clearAuthCookies(c *gin.Context) {
+	c.SetCookie("access_token", "", -1, "/", "localhost", false, true)
+	c.SetCookie("refresh_token", "", -1, "/auth", "localhost", false, true)
 }
 
 func (h *UserHandler) CheckAuth(c *gin.Context) {
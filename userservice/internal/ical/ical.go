@@ -0,0 +1,111 @@
+// Package ical เขียนไฟล์ iCalendar (RFC 5545) สำหรับ Subscription Feed
+// เวลาทั้งหมดเขียนเป็น UTC (ลงท้ายด้วย Z) ซึ่งทุกแอปปฏิทินแปลงเป็นเวลาท้องถิ่นเองได้
+// และใส่ X-WR-TIMEZONE เป็นคำแนะนำ Time Zone ของปฏิทิน
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// สถานะของ VEVENT
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusTentative = "TENTATIVE"
+	StatusCancelled = "CANCELLED"
+)
+
+// บรรทัดยาวเกิน 75 octets ต้องถูกพับ (RFC 5545 3.1)
+const maxLineOctets = 75
+
+type Event struct {
+	UID          string
+	Summary      string
+	Description  string
+	Start        time.Time
+	End          time.Time
+	Status       string
+	Created      time.Time
+	LastModified time.Time
+}
+
+type Calendar struct {
+	ProdID   string
+	Name     string
+	Timezone string // IANA เช่น "Asia/Bangkok" (ใส่เป็น X-WR-TIMEZONE; ว่างได้)
+	Events   []Event
+}
+
+// Encode แปลงเป็นข้อความ iCalendar (บรรทัดคั่นด้วย CRLF)
+func (c *Calendar) Encode() []byte {
+	var b bytes.Buffer
+	stamp := formatTime(time.Now())
+
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:"+c.ProdID)
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:PUBLISH")
+	if c.Name != "" {
+		writeLine(&b, "X-WR-CALNAME:"+escapeText(c.Name))
+	}
+	if c.Timezone != "" {
+		writeLine(&b, "X-WR-TIMEZONE:"+c.Timezone)
+	}
+
+	for _, e := range c.Events {
+		writeLine(&b, "BEGIN:VEVENT")
+		writeLine(&b, "UID:"+e.UID)
+		writeLine(&b, "DTSTAMP:"+stamp)
+		writeLine(&b, "DTSTART:"+formatTime(e.Start))
+		writeLine(&b, "DTEND:"+formatTime(e.End))
+		writeLine(&b, "SUMMARY:"+escapeText(e.Summary))
+		if e.Description != "" {
+			writeLine(&b, "DESCRIPTION:"+escapeText(e.Description))
+		}
+		if e.Status != "" {
+			writeLine(&b, "STATUS:"+e.Status)
+		}
+		if !e.Created.IsZero() {
+			writeLine(&b, "CREATED:"+formatTime(e.Created))
+		}
+		if !e.LastModified.IsZero() {
+			writeLine(&b, "LAST-MODIFIED:"+formatTime(e.LastModified))
+		}
+		writeLine(&b, "END:VEVENT")
+	}
+
+	writeLine(&b, "END:VCALENDAR")
+	return b.Bytes()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escapeText escape ค่าแบบ TEXT: \ ; , และขึ้นบรรทัดใหม่
+func escapeText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+	return r.Replace(s)
+}
+
+// writeLine เขียน 1 content line พร้อมพับบรรทัดทุก 75 octets (ไม่ตัดกลางตัวอักษร UTF-8)
+// บรรทัดต่อเนื่องขึ้นต้นด้วยช่องว่าง 1 ตัว
+func writeLine(b *bytes.Buffer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// บรรทัดต่อเนื่องมีช่องว่างนำหน้า 1 octet
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,102 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// unfold ต่อบรรทัดที่ถูกพับกลับ (CRLF ตามด้วยช่องว่าง 1 ตัว)
+func unfold(s string) string {
+	return strings.ReplaceAll(s, "\r\n ", "")
+}
+
+func TestWriteLineFolding(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"short", "SUMMARY:Leg day"},
+		{"exactly 75 octets", "SUMMARY:" + strings.Repeat("a", 67)},
+		{"ascii", "DESCRIPTION:" + strings.Repeat("x", 200)},
+		// ตัวอักษรไทย 3 octets ต่อตัว: จุดพับต้องไม่ตกกลางตัวอักษร
+		{"thai", "SUMMARY:" + strings.Repeat("ฝึกขา", 30)},
+		{"emoji", "SUMMARY:" + strings.Repeat("a💪", 40)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			writeLine(&b, tt.line)
+			out := b.String()
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("missing CRLF: %q", out)
+			}
+			for i, l := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+				if len(l) > maxLineOctets {
+					t.Errorf("line %d has %d octets", i, len(l))
+				}
+				if i > 0 && !strings.HasPrefix(l, " ") {
+					t.Errorf("continuation line %d does not start with a space: %q", i, l)
+				}
+				if !utf8.ValidString(l) {
+					t.Errorf("line %d splits a UTF-8 character: %q", i, l)
+				}
+			}
+			if got := unfold(strings.TrimSuffix(out, "\r\n")); got != tt.line {
+				t.Errorf("unfolded = %q, want %q", got, tt.line)
+			}
+		})
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Leg day", "Leg day"},
+		{`a\b`, `a\\b`},
+		{"squat; bench, row", `squat\; bench\, row`},
+		{"line1\nline2\r\nline3\rline4", `line1\nline2\nline3\nline4`},
+		{"ไม่มา: ป่วย", "ไม่มา: ป่วย"},
+	}
+	for _, tt := range tests {
+		if got := escapeText(tt.in); got != tt.want {
+			t.Errorf("escapeText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	start := time.Date(2024, 5, 1, 8, 0, 0, 0, time.FixedZone("+07", 7*3600))
+	cal := Calendar{
+		ProdID:   "-//test//EN",
+		Name:     "ตารางฝึก, สมชาย",
+		Timezone: "Asia/Bangkok",
+		Events: []Event{
+			{UID: "schedule-1@example.com", Summary: "Leg day", Start: start, End: start.Add(time.Hour), Status: StatusConfirmed},
+			{UID: "schedule-2@example.com", Summary: "Push; pull", Start: start, End: start.Add(time.Hour), Status: StatusCancelled},
+		},
+	}
+	out := unfold(string(cal.Encode()))
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"X-WR-CALNAME:ตารางฝึก\\, สมชาย\r\n",
+		"X-WR-TIMEZONE:Asia/Bangkok\r\n",
+		// เวลาเขียนเป็น UTC เสมอ
+		"DTSTART:20240501T010000Z\r\n",
+		"DTEND:20240501T020000Z\r\n",
+		"SUMMARY:Push\\; pull\r\nSTATUS:CANCELLED\r\n",
+		"SUMMARY:Leg day\r\nSTATUS:CONFIRMED\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "BEGIN:VEVENT"); n != 2 {
+		t.Errorf("VEVENT count = %d, want 2", n)
+	}
+}
@@ -0,0 +1,259 @@
+// Package listing การแบ่งหน้า (offset/cursor) การเรียง และตัวกรองของ List Endpoint ทุกตัว
+// Repository กำหนด Spec (ฟิลด์ที่เรียง/กรองได้ -> คอลัมน์ SQL) Handler อ่าน Query String ด้วย Parse
+// แล้ว Repository ประกอบ SQL ด้วย Run ผลลัพธ์เป็น Page {data, paging} รูปแบบเดียวกันทุก Endpoint
+//
+//	?limit=20&offset=40            หน้าแบบ offset (มี total)
+//	?limit=20&cursor=<next_cursor> หน้าถัดไปแบบ cursor (เร็วกว่าเมื่อข้อมูลเยอะ ไม่มี total)
+//	?sort=-start_time              เรียงมากไปน้อย (ไม่มี - = น้อยไปมาก)
+//	?status=scheduled,completed&from=2024-01-01  ตัวกรองตาม Spec
+package listing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// ErrInvalidParams Query String ไม่ถูกต้อง (Handler ตอบ 400)
+var ErrInvalidParams = errors.New("invalid list parameters")
+
+// FilterType ชนิดของค่าตัวกรอง
+type FilterType int
+
+const (
+	Text     FilterType = iota // column <op> ค่า
+	TextList                   // column เป็นค่าใดค่าหนึ่ง (คั่นด้วย ",")
+	Int
+	Bool
+	Time   // RFC3339 หรือ YYYY-MM-DD (เที่ยงคืน UTC)
+	Search // column ILIKE %ค่า% (ไม่สนตัวพิมพ์เล็ก/ใหญ่)
+)
+
+// Filter ตัวกรองหนึ่งตัว (Column เป็น SQL ที่ Repository กำหนด ไม่ได้มาจากผู้ใช้)
+type Filter struct {
+	Column string
+	Type   FilterType
+	// ตัวเปรียบเทียบของ Text/Int/Time: =, <, <=, >, >= (ว่าง = "=")
+	Op string
+	// ค่าที่อนุญาตของ Text/TextList (ว่าง = ไม่จำกัด)
+	Values []string
+}
+
+// Sort ฟิลด์ที่เรียงได้ (คอลัมน์ต้องไม่เป็น NULL) Key คืนค่าของคอลัมน์จากแถวสำหรับสร้าง cursor
+type Sort[T any] struct {
+	Column string
+	Key    func(T) any
+}
+
+// Spec ฟิลด์ที่เรียง/กรองได้ของรายการหนึ่ง ID ใช้เรียงต่อเมื่อค่าที่เรียงเท่ากัน (ให้ลำดับคงที่สำหรับ cursor)
+type Spec[T any] struct {
+	Sorts       map[string]Sort[T]
+	DefaultSort string
+	IDColumn    string
+	ID          func(T) int
+	Filters     map[string]Filter
+}
+
+type condition struct {
+	column string
+	op     string
+	value  any
+}
+
+// cursor ตำแหน่งของแถวสุดท้ายในหน้าก่อน (ผูกกับการเรียง ใช้ข้ามการเรียงไม่ได้)
+type cursor struct {
+	Sort  string `json:"s"`
+	Value any    `json:"v"`
+	ID    int    `json:"id"`
+}
+
+// Params ค่าที่อ่านจาก Query String แล้ว
+type Params struct {
+	Limit  int
+	Offset int
+	Sort   string // "-start_time" = มากไปน้อย
+
+	cursor *cursor
+	conds  []condition
+}
+
+func (p *Params) sortKey() (key string, desc bool) {
+	return strings.TrimPrefix(p.Sort, "-"), strings.HasPrefix(p.Sort, "-")
+}
+
+// Parse อ่าน limit, offset, cursor, sort และตัวกรองใน spec (พารามิเตอร์อื่นถูกละไว้)
+func Parse[T any](spec *Spec[T], q url.Values) (*Params, error) {
+	p := &Params{Limit: DefaultLimit, Sort: spec.DefaultSort}
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > MaxLimit {
+			return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidParams, MaxLimit)
+		}
+		p.Limit = n
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%w: offset must be a non-negative integer", ErrInvalidParams)
+		}
+		p.Offset = n
+	}
+	if v := q.Get("sort"); v != "" {
+		if _, ok := spec.Sorts[strings.TrimPrefix(v, "-")]; !ok {
+			return nil, fmt.Errorf("%w: sort must be one of: %s (prefix with - for descending)", ErrInvalidParams, strings.Join(sortedKeys(spec.Sorts), ", "))
+		}
+		p.Sort = v
+	}
+	if v := q.Get("cursor"); v != "" {
+		if q.Get("offset") != "" {
+			return nil, fmt.Errorf("%w: use either cursor or offset, not both", ErrInvalidParams)
+		}
+		c, err := decodeCursor(v)
+		if err != nil || c.Sort != p.Sort {
+			return nil, fmt.Errorf("%w: invalid cursor (it must come from the same sort)", ErrInvalidParams)
+		}
+		p.cursor = c
+	}
+
+	for _, name := range sortedKeys(spec.Filters) {
+		raw := strings.TrimSpace(q.Get(name))
+		if raw == "" {
+			continue
+		}
+		cond, err := parseFilter(spec.Filters[name], raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %v", ErrInvalidParams, name, err)
+		}
+		p.conds = append(p.conds, cond)
+	}
+	return p, nil
+}
+
+func parseFilter(f Filter, raw string) (condition, error) {
+	op := f.Op
+	if op == "" {
+		op = "="
+	}
+	switch f.Type {
+	case Text:
+		if len(f.Values) > 0 && !contains(f.Values, raw) {
+			return condition{}, fmt.Errorf("must be one of: %s", strings.Join(f.Values, ", "))
+		}
+		return condition{f.Column, op, raw}, nil
+	case TextList:
+		var values []string
+		for _, v := range strings.Split(raw, ",") {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				continue
+			}
+			if len(f.Values) > 0 && !contains(f.Values, v) {
+				return condition{}, fmt.Errorf("must be one or more of: %s", strings.Join(f.Values, ", "))
+			}
+			values = append(values, v)
+		}
+		return condition{f.Column, "IN", values}, nil
+	case Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return condition{}, errors.New("must be an integer")
+		}
+		return condition{f.Column, op, n}, nil
+	case Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return condition{}, errors.New("must be true or false")
+		}
+		return condition{f.Column, "=", b}, nil
+	case Time:
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			t, err = time.Parse(time.DateOnly, raw)
+		}
+		if err != nil {
+			return condition{}, errors.New("must be a date (YYYY-MM-DD) or RFC3339 time")
+		}
+		return condition{f.Column, op, t}, nil
+	case Search:
+		escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(raw)
+		return condition{f.Column, "ILIKE", "%" + escaped + "%"}, nil
+	}
+	return condition{}, errors.New("is not supported")
+}
+
+// Page ผลลัพธ์ของ List Endpoint
+type Page[T any] struct {
+	Data   []T    `json:"data"`
+	Paging Paging `json:"paging"`
+}
+
+// Paging ข้อมูลการแบ่งหน้า (next_cursor มีเมื่อยังมีหน้าถัดไป ใช้ต่อได้ทั้งสองโหมด)
+type Paging struct {
+	Limit      int    `json:"limit"`
+	Sort       string `json:"sort"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+
+	// โหมด offset เท่านั้น
+	Offset *int `json:"offset,omitempty"`
+	Total  *int `json:"total,omitempty"`
+}
+
+func encodeCursor(c cursor) (string, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var c cursor
+	dec := json.NewDecoder(strings.NewReader(string(b)))
+	dec.UseNumber()
+	if err := dec.Decode(&c); err != nil {
+		return nil, err
+	}
+	switch v := c.Value.(type) {
+	case json.Number:
+		c.Value = v.String()
+	case string:
+	default:
+		return nil, errors.New("invalid cursor value")
+	}
+	return &c, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,241 @@
+package listing
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type testItem struct {
+	ID        int
+	Name      string
+	StartTime time.Time
+}
+
+var testSpec = &Spec[testItem]{
+	Sorts: map[string]Sort[testItem]{
+		"id":         {Column: "s.id", Key: func(i testItem) any { return i.ID }},
+		"name":       {Column: "s.name", Key: func(i testItem) any { return i.Name }},
+		"start_time": {Column: "s.start_time", Key: func(i testItem) any { return i.StartTime }},
+	},
+	DefaultSort: "-start_time",
+	IDColumn:    "s.id",
+	ID:          func(i testItem) int { return i.ID },
+	Filters: map[string]Filter{
+		"status":    {Column: "s.status", Type: TextList, Values: []string{"scheduled", "completed", "cancelled"}},
+		"kind":      {Column: "s.kind", Type: Text, Values: []string{"a", "b"}},
+		"client_id": {Column: "s.client_id", Type: Int},
+		"min_reps":  {Column: "s.reps", Type: Int, Op: ">="},
+		"active":    {Column: "s.active", Type: Bool},
+		"from":      {Column: "s.start_time", Type: Time, Op: ">="},
+		"q":         {Column: "s.name", Type: Search},
+	},
+}
+
+func parseQuery(t *testing.T, raw string) (*Params, error) {
+	t.Helper()
+	q, err := url.ParseQuery(raw)
+	if err != nil {
+		t.Fatalf("ParseQuery(%q): %v", raw, err)
+	}
+	return Parse(testSpec, q)
+}
+
+func mustCursor(t *testing.T, c cursor) string {
+	t.Helper()
+	s, err := encodeCursor(c)
+	if err != nil {
+		t.Fatalf("encodeCursor: %v", err)
+	}
+	return s
+}
+
+func TestParseDefaults(t *testing.T) {
+	p, err := parseQuery(t, "")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if p.Limit != DefaultLimit || p.Offset != 0 || p.Sort != "-start_time" || p.cursor != nil || len(p.conds) != 0 {
+		t.Errorf("defaults = %+v", p)
+	}
+}
+
+func TestParseLimitOffset(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantLimit  int
+		wantOffset int
+		wantErr    bool
+	}{
+		{"limit=1", 1, 0, false},
+		{"limit=" + strconv.Itoa(MaxLimit), MaxLimit, 0, false},
+		{"limit=0", 0, 0, true},
+		{"limit=-1", 0, 0, true},
+		{"limit=" + strconv.Itoa(MaxLimit+1), 0, 0, true},
+		{"limit=ten", 0, 0, true},
+		{"offset=0", DefaultLimit, 0, false},
+		{"offset=40&limit=20", 20, 40, false},
+		{"offset=-1", 0, 0, true},
+		{"offset=x", 0, 0, true},
+	}
+	for _, tt := range tests {
+		p, err := parseQuery(t, tt.query)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidParams) {
+				t.Errorf("%s: err = %v, want ErrInvalidParams", tt.query, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.query, err)
+			continue
+		}
+		if p.Limit != tt.wantLimit || p.Offset != tt.wantOffset {
+			t.Errorf("%s: limit %d offset %d, want %d %d", tt.query, p.Limit, p.Offset, tt.wantLimit, tt.wantOffset)
+		}
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	for _, sort := range []string{"name", "-name", "start_time", "-id"} {
+		p, err := parseQuery(t, "sort="+sort)
+		if err != nil || p.Sort != sort {
+			t.Errorf("sort=%s: %v (sort %q)", sort, err, p.Sort)
+		}
+	}
+	// ไม่อยู่ใน whitelist (รวมถึงความพยายามแทรก SQL)
+	for _, sort := range []string{"password", "--name", "name desc", "s.name", "name;DROP TABLE users"} {
+		if _, err := parseQuery(t, url.Values{"sort": {sort}}.Encode()); !errors.Is(err, ErrInvalidParams) {
+			t.Errorf("sort=%q: err = %v, want ErrInvalidParams", sort, err)
+		}
+	}
+}
+
+func TestParseCursor(t *testing.T) {
+	start := time.Date(2024, 3, 4, 8, 30, 0, 123456000, time.FixedZone("+07", 7*3600))
+
+	t.Run("time cursor round trip", func(t *testing.T) {
+		page, err := newPage(testSpec, &Params{Limit: 1, Sort: "-start_time"}, []testItem{{ID: 7, StartTime: start}, {ID: 8}}, Paging{})
+		if err != nil {
+			t.Fatalf("newPage: %v", err)
+		}
+		if !page.Paging.HasMore || page.Paging.NextCursor == "" || len(page.Data) != 1 {
+			t.Fatalf("page = %+v", page)
+		}
+
+		p, err := parseQuery(t, "sort=-start_time&cursor="+page.Paging.NextCursor)
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		if p.cursor.ID != 7 || p.cursor.Sort != "-start_time" {
+			t.Errorf("cursor = %+v", p.cursor)
+		}
+		value, ok := p.cursor.Value.(string)
+		if !ok {
+			t.Fatalf("cursor value %T, want string", p.cursor.Value)
+		}
+		got, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil || !got.Equal(start) {
+			t.Errorf("cursor time = %v (%v), want %v", got, err, start)
+		}
+	})
+
+	t.Run("int cursor", func(t *testing.T) {
+		p, err := parseQuery(t, "sort=id&cursor="+mustCursor(t, cursor{Sort: "id", Value: 42, ID: 42}))
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		if p.cursor.Value != "42" {
+			t.Errorf("cursor value = %#v, want \"42\"", p.cursor.Value)
+		}
+	})
+
+	t.Run("default sort", func(t *testing.T) {
+		if _, err := parseQuery(t, "cursor="+mustCursor(t, cursor{Sort: "-start_time", Value: "2024-01-01T00:00:00Z", ID: 1})); err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+	})
+
+	invalid := []struct {
+		name, query string
+	}{
+		{"from a different sort", "sort=name&cursor=" + mustCursor(t, cursor{Sort: "-start_time", Value: "2024-01-01T00:00:00Z", ID: 1})},
+		{"from the other direction", "sort=start_time&cursor=" + mustCursor(t, cursor{Sort: "-start_time", Value: "2024-01-01T00:00:00Z", ID: 1})},
+		{"with offset", "offset=20&cursor=" + mustCursor(t, cursor{Sort: "-start_time", Value: "x", ID: 1})},
+		{"not base64", "cursor=***"},
+		{"not json", "cursor=" + "bm90IGpzb24"},
+		{"object value", "cursor=" + mustCursor(t, cursor{Sort: "-start_time", Value: map[string]int{"a": 1}, ID: 1})},
+		{"null value", "cursor=" + mustCursor(t, cursor{Sort: "-start_time", ID: 1})},
+	}
+	for _, tt := range invalid {
+		if _, err := parseQuery(t, tt.query); !errors.Is(err, ErrInvalidParams) {
+			t.Errorf("%s: err = %v, want ErrInvalidParams", tt.name, err)
+		}
+	}
+}
+
+func TestParseFilters(t *testing.T) {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		query string
+		want  []condition
+	}{
+		{"status=scheduled", []condition{{"s.status", "IN", []string{"scheduled"}}}},
+		{"status=scheduled, completed,", []condition{{"s.status", "IN", []string{"scheduled", "completed"}}}},
+		{"kind=a", []condition{{"s.kind", "=", "a"}}},
+		{"client_id=5", []condition{{"s.client_id", "=", 5}}},
+		{"min_reps=8", []condition{{"s.reps", ">=", 8}}},
+		{"active=true", []condition{{"s.active", "=", true}}},
+		{"from=2024-01-01", []condition{{"s.start_time", ">=", day}}},
+		{"from=2024-01-01T07:00:00%2B07:00", []condition{{"s.start_time", ">=", time.Date(2024, 1, 1, 7, 0, 0, 0, time.FixedZone("", 7*3600))}}},
+		// ILIKE: escape \ % _ ของผู้ใช้ไม่ให้เป็น wildcard
+		{"q=50%25_off", []condition{{"s.name", "ILIKE", `%50\%\_off%`}}},
+		{`q=a\b`, []condition{{"s.name", "ILIKE", `%a\\b%`}}},
+		{"q=สมชาย", []condition{{"s.name", "ILIKE", "%สมชาย%"}}},
+		// ตัวกรองเรียงตามชื่อ; ค่าว่างและพารามิเตอร์อื่นถูกละไว้
+		{"status=&q=x&client_id=2&unknown=1", []condition{{"s.client_id", "=", 2}, {"s.name", "ILIKE", "%x%"}}},
+	}
+	for _, tt := range tests {
+		p, err := parseQuery(t, tt.query)
+		if err != nil {
+			t.Errorf("%s: %v", tt.query, err)
+			continue
+		}
+		if len(p.conds) != len(tt.want) {
+			t.Errorf("%s: conds = %+v, want %+v", tt.query, p.conds, tt.want)
+			continue
+		}
+		for i := range tt.want {
+			got, want := p.conds[i], tt.want[i]
+			if wt, ok := want.value.(time.Time); ok {
+				gt, _ := got.value.(time.Time)
+				if got.column != want.column || got.op != want.op || !gt.Equal(wt) {
+					t.Errorf("%s: cond %d = %+v, want %+v", tt.query, i, got, want)
+				}
+				continue
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: cond %d = %+v, want %+v", tt.query, i, got, want)
+			}
+		}
+	}
+}
+
+func TestParseInvalidFilters(t *testing.T) {
+	for _, query := range []string{
+		"status=pending",
+		"status=scheduled,pending",
+		"kind=c",
+		"client_id=abc",
+		"active=maybe",
+		"from=yesterday",
+		"from=01/02/2024",
+	} {
+		if _, err := parseQuery(t, query); !errors.Is(err, ErrInvalidParams) {
+			t.Errorf("%s: err = %v, want ErrInvalidParams", query, err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package listing
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Query เงื่อนไขพื้นฐานของ Repository (เช่น เฉพาะข้อมูลของผู้ใช้ที่ Login) ก่อนเพิ่มตัวกรองจาก Params
+//
+//	q := &listing.Query{}
+//	q.Where("trainer_id = " + q.Arg(trainerID))
+type Query struct {
+	where []string
+	args  []any
+}
+
+// Arg เพิ่ม argument แล้วคืน placeholder ($n)
+func (q *Query) Arg(v any) string {
+	q.args = append(q.args, v)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+func (q *Query) Where(cond string) {
+	q.where = append(q.where, cond)
+}
+
+func (q *Query) whereClause() string {
+	if len(q.where) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(q.where, " AND ")
+}
+
+// Run ดึงหนึ่งหน้า: selectFrom คือ "SELECT ... FROM ..." (ไม่มี WHERE/ORDER BY) scan อ่านหนึ่งแถว
+// โหมด offset นับ total ด้วยเงื่อนไขเดียวกันอีกหนึ่ง Query
+func Run[T any](db *sql.DB, spec *Spec[T], p *Params, selectFrom string, q *Query, scan func(*sql.Rows) (T, error)) (*Page[T], error) {
+	st := buildStatements(spec, p, selectFrom, q)
+
+	paging := Paging{Limit: p.Limit, Sort: p.Sort}
+	if st.count != "" {
+		var total int
+		if err := db.QueryRow(st.count, st.countArgs...).Scan(&total); err != nil {
+			return nil, err
+		}
+		offset := p.Offset
+		paging.Offset, paging.Total = &offset, &total
+	}
+
+	rows, err := db.Query(st.page, st.pageArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []T{}
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return newPage(spec, p, items, paging)
+}
+
+// statements SQL ของหนึ่งหน้า (count ว่าง = โหมด cursor ไม่นับ total)
+type statements struct {
+	count     string
+	countArgs []any
+	page      string
+	pageArgs  []any
+}
+
+// buildStatements เพิ่มตัวกรองจาก Params ลงใน q แล้วประกอบ SQL นับ total และ SQL ของหน้า
+func buildStatements[T any](spec *Spec[T], p *Params, selectFrom string, q *Query) statements {
+	for _, c := range p.conds {
+		switch c.op {
+		case "IN":
+			q.Where(c.column + " = ANY(" + q.Arg(pq.Array(c.value)) + ")")
+		case "ILIKE":
+			q.Where(c.column + " ILIKE " + q.Arg(c.value))
+		default:
+			q.Where(c.column + " " + c.op + " " + q.Arg(c.value))
+		}
+	}
+
+	var st statements
+	if p.cursor == nil {
+		st.count = `SELECT COUNT(*) FROM (` + selectFrom + q.whereClause() + `) AS t`
+		st.countArgs = append([]any(nil), q.args...)
+	}
+
+	key, desc := p.sortKey()
+	sortSpec := spec.Sorts[key]
+	dir, cmp := " ASC", ">"
+	if desc {
+		dir, cmp = " DESC", "<"
+	}
+	if p.cursor != nil {
+		// Keyset: แถวที่อยู่หลัง (ค่าที่เรียง, id) ของแถวสุดท้ายในหน้าก่อน
+		q.Where("(" + sortSpec.Column + ", " + spec.IDColumn + ") " + cmp + " (" + q.Arg(p.cursor.Value) + ", " + q.Arg(p.cursor.ID) + ")")
+	}
+
+	st.page = selectFrom + q.whereClause() +
+		" ORDER BY " + sortSpec.Column + dir + ", " + spec.IDColumn + dir +
+		" LIMIT " + q.Arg(p.Limit+1)
+	if p.cursor == nil && p.Offset > 0 {
+		st.page += " OFFSET " + q.Arg(p.Offset)
+	}
+	st.pageArgs = q.args
+	return st
+}
+
+// newPage ตัดแถวที่ดึงเกินมา 1 แถว (ใช้รู้ว่ายังมีหน้าถัดไป) และสร้าง cursor จากแถวสุดท้าย
+func newPage[T any](spec *Spec[T], p *Params, items []T, paging Paging) (*Page[T], error) {
+	if len(items) > p.Limit {
+		items = items[:p.Limit]
+		key, _ := p.sortKey()
+		last := items[len(items)-1]
+		next, err := encodeCursor(cursor{Sort: p.Sort, Value: spec.Sorts[key].Key(last), ID: spec.ID(last)})
+		if err != nil {
+			return nil, err
+		}
+		paging.HasMore, paging.NextCursor = true, next
+	}
+	return &Page[T]{Data: items, Paging: paging}, nil
+}
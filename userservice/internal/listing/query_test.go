@@ -0,0 +1,117 @@
+package listing
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+const testSelect = "SELECT s.id, s.name, s.start_time FROM schedules s"
+
+func baseQuery() *Query {
+	q := &Query{}
+	q.Where("s.trainer_id = " + q.Arg(3))
+	return q
+}
+
+func TestBuildStatementsOffset(t *testing.T) {
+	p, err := parseQuery(t, "limit=10&offset=20&sort=name&status=scheduled,completed&q=50%25&min_reps=8")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	st := buildStatements(testSpec, p, testSelect, baseQuery())
+
+	where := " WHERE s.trainer_id = $1 AND s.reps >= $2 AND s.name ILIKE $3 AND s.status = ANY($4)"
+	if want := "SELECT COUNT(*) FROM (" + testSelect + where + ") AS t"; st.count != want {
+		t.Errorf("count =\n%s\nwant\n%s", st.count, want)
+	}
+	wantArgs := []any{3, 8, `%50\%%`, pq.Array([]string{"scheduled", "completed"})}
+	if !reflect.DeepEqual(st.countArgs, wantArgs) {
+		t.Errorf("count args = %#v, want %#v", st.countArgs, wantArgs)
+	}
+
+	if want := testSelect + where + " ORDER BY s.name ASC, s.id ASC LIMIT $5 OFFSET $6"; st.page != want {
+		t.Errorf("page =\n%s\nwant\n%s", st.page, want)
+	}
+	// ดึงเกิน 1 แถวเพื่อรู้ว่ามีหน้าถัดไป
+	if !reflect.DeepEqual(st.pageArgs, append(wantArgs, 11, 20)) {
+		t.Errorf("page args = %#v", st.pageArgs)
+	}
+}
+
+func TestBuildStatementsFirstPageHasNoOffset(t *testing.T) {
+	p, err := parseQuery(t, "")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	st := buildStatements(testSpec, p, testSelect, &Query{})
+
+	if want := "SELECT COUNT(*) FROM (" + testSelect + ") AS t"; st.count != want {
+		t.Errorf("count = %s", st.count)
+	}
+	if want := testSelect + " ORDER BY s.start_time DESC, s.id DESC LIMIT $1"; st.page != want {
+		t.Errorf("page = %s", st.page)
+	}
+	if !reflect.DeepEqual(st.pageArgs, []any{DefaultLimit + 1}) {
+		t.Errorf("page args = %#v", st.pageArgs)
+	}
+}
+
+func TestBuildStatementsCursor(t *testing.T) {
+	tests := []struct {
+		sort, cmp, dir string
+	}{
+		{"-start_time", "<", "DESC"},
+		{"start_time", ">", "ASC"},
+	}
+	for _, tt := range tests {
+		c := mustCursor(t, cursor{Sort: tt.sort, Value: "2024-01-01T08:00:00+07:00", ID: 9})
+		p, err := parseQuery(t, "limit=5&sort="+tt.sort+"&cursor="+c)
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		st := buildStatements(testSpec, p, testSelect, baseQuery())
+
+		if st.count != "" {
+			t.Errorf("%s: cursor mode must not count total: %s", tt.sort, st.count)
+		}
+		want := testSelect + " WHERE s.trainer_id = $1 AND (s.start_time, s.id) " + tt.cmp + " ($2, $3)" +
+			" ORDER BY s.start_time " + tt.dir + ", s.id " + tt.dir + " LIMIT $4"
+		if st.page != want {
+			t.Errorf("%s: page =\n%s\nwant\n%s", tt.sort, st.page, want)
+		}
+		if !reflect.DeepEqual(st.pageArgs, []any{3, "2024-01-01T08:00:00+07:00", 9, 6}) {
+			t.Errorf("%s: page args = %#v", tt.sort, st.pageArgs)
+		}
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	items := []testItem{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}, {ID: 3, Name: "c"}}
+
+	// พอดีหน้า: ไม่มีหน้าถัดไป
+	page, err := newPage(testSpec, &Params{Limit: 3, Sort: "name"}, items, Paging{Limit: 3, Sort: "name"})
+	if err != nil {
+		t.Fatalf("newPage: %v", err)
+	}
+	if page.Paging.HasMore || page.Paging.NextCursor != "" || len(page.Data) != 3 {
+		t.Errorf("full page = %+v", page)
+	}
+
+	// ดึงเกินมา 1 แถว: ตัดทิ้งและ cursor ชี้แถวสุดท้ายที่แสดง
+	page, err = newPage(testSpec, &Params{Limit: 2, Sort: "name"}, items, Paging{Limit: 2, Sort: "name"})
+	if err != nil {
+		t.Fatalf("newPage: %v", err)
+	}
+	if !page.Paging.HasMore || len(page.Data) != 2 {
+		t.Fatalf("page = %+v", page)
+	}
+	c, err := decodeCursor(page.Paging.NextCursor)
+	if err != nil {
+		t.Fatalf("decodeCursor: %v", err)
+	}
+	if *c != (cursor{Sort: "name", Value: "b", ID: 2}) {
+		t.Errorf("cursor = %+v", *c)
+	}
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"users/internal/authz"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OwnershipGuard ตรวจว่าผู้ใช้เป็นเจ้าของข้อมูลแม่ (client/program/schedule) ก่อนเข้า Handler
+type OwnershipGuard struct {
+	policy   *authz.Policy
+	resolver authz.OwnerResolver
+}
+
+func NewOwnershipGuard(policy *authz.Policy, resolver authz.OwnerResolver) *OwnershipGuard {
+	return &OwnershipGuard{policy: policy, resolver: resolver}
+}
+
+// Require อ่าน ID จาก :param แล้วตรวจความเป็นเจ้าของตาม access
+// ไม่พบข้อมูลตอบ 404, ไม่ใช่เจ้าของตอบ 403 (ต้องใช้หลัง JWTCookieAuth)
+func (g *OwnershipGuard) Require(resource authz.Resource, param string, access authz.Access) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param(param))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid " + string(resource) + " ID"})
+			return
+		}
+
+		owner, ok := g.check(c, resource, id, access)
+		if !ok {
+			return
+		}
+		// ส่งต่อข้อมูลเจ้าของไปให้ Handler (เผื่อใช้ต่อ)
+		c.Set("resource_owner", owner)
+		c.Next()
+	}
+}
+
+// CheckClient ตรวจ client_id ที่มากับ Body (Require ตรวจได้เฉพาะ ID ใน URL) ว่าผู้ใช้เป็นเทรนเนอร์ของลูกค้าคนนี้
+// ใช้ใน Handler ก่อนสร้าง/แก้ข้อมูลที่ผูกกับลูกค้า: false = ตอบ 404/403 ไปแล้ว
+func (g *OwnershipGuard) CheckClient(c *gin.Context, clientID int) bool {
+	_, ok := g.check(c, authz.ResourceClient, clientID, authz.AccessTrainer)
+	return ok
+}
+
+func (g *OwnershipGuard) check(c *gin.Context, resource authz.Resource, id int, access authz.Access) (*authz.Owner, bool) {
+	owner, err := g.resolver.ResolveOwner(resource, id)
+	if err != nil {
+		if errors.Is(err, authz.ErrResourceNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Resource not found"})
+		} else {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify ownership"})
+		}
+		return nil, false
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok || !(owner.Allows(userID, access) || g.policy.Can(currentRole(c), authz.PermOwnershipBypass)) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You do not have access to this " + string(resource)})
+		return nil, false
+	}
+	return owner, true
+}
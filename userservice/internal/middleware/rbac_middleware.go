@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"users/internal/authz"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission ต้องใช้หลัง JWTCookieAuth (อ่าน role จาก Context)
+// ผู้ใช้ต้องมีสิทธิ์ครบทุกตัวที่ระบุ ไม่เช่นนั้นตอบ 403
+func RequirePermission(policy *authz.Policy, perms ...authz.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := currentRole(c)
+		for _, perm := range perms {
+			if !policy.Can(role, perm) {
+				abortForbidden(c, perm)
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequireSelfOrPermission ผ่านถ้า :param ตรงกับ user_id ของคนที่ Login อยู่ หรือมีสิทธิ์ perm
+// (เช่น ผู้ใช้แก้ไขข้อมูลตัวเองได้ แต่แก้ของคนอื่นต้องเป็น admin)
+func RequireSelfOrPermission(policy *authz.Policy, param string, perm authz.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if policy.Can(currentRole(c), perm) {
+			c.Next()
+			return
+		}
+
+		targetID, err := strconv.Atoi(c.Param(param))
+		userID, ok := currentUserID(c)
+		if err != nil || !ok || targetID != userID {
+			abortForbidden(c, perm)
+			return
+		}
+		c.Next()
+	}
+}
+
+func abortForbidden(c *gin.Context, perm authz.Permission) {
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"error":               "Forbidden",
+		"required_permission": perm,
+	})
+}
+
+func currentRole(c *gin.Context) string {
+	role, _ := c.Get("role")
+	s, _ := role.(string)
+	return s
+}
+
+func currentUserID(c *gin.Context) (int, bool) {
+	userID, _ := c.Get("user_id")
+	id, ok := userID.(float64)
+	return int(id), ok
+}
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS session_log_sets;
+DROP TABLE IF EXISTS session_logs;
+DROP TABLE IF EXISTS assignments;
+DROP TABLE IF EXISTS schedules;
+DROP TABLE IF EXISTS program_exercises;
+DROP TABLE IF EXISTS programs;
+DROP TABLE IF EXISTS exercises;
+DROP TABLE IF EXISTS client_notes;
+DROP TABLE IF EXISTS client_trainer_links;
+DROP TABLE IF EXISTS clients;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,140 @@
+-- 0001_init: ตารางหลักทั้งหมดที่ repository ใช้งาน
+
+CREATE TABLE users (
+    id            SERIAL PRIMARY KEY,
+    name          VARCHAR(255) NOT NULL,
+    email         VARCHAR(255) NOT NULL UNIQUE,
+    password_hash TEXT         NOT NULL DEFAULT '',
+    role          VARCHAR(20)  NOT NULL DEFAULT 'trainer',
+    avatar_url    TEXT,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE clients (
+    id                 SERIAL PRIMARY KEY,
+    trainer_id         INTEGER      NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name               VARCHAR(255) NOT NULL,
+    email              VARCHAR(255),
+    phone_number       VARCHAR(50),
+    avatar_url         TEXT,
+    birth_date         DATE,
+    gender             VARCHAR(20),
+    height_cm          NUMERIC(5, 2),
+    weight_kg          NUMERIC(5, 2),
+    goal               TEXT,
+    injuries           TEXT,
+    activity_level     VARCHAR(50),
+    medical_conditions TEXT,
+    created_at         TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_clients_trainer_id ON clients(trainer_id);
+
+CREATE TABLE client_trainer_links (
+    id         SERIAL PRIMARY KEY,
+    client_id  INTEGER     NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    trainer_id INTEGER     NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (client_id, trainer_id)
+);
+CREATE INDEX idx_client_trainer_links_trainer_id ON client_trainer_links(trainer_id);
+
+CREATE TABLE client_notes (
+    id         SERIAL PRIMARY KEY,
+    client_id  INTEGER      NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    content    TEXT         NOT NULL,
+    type       VARCHAR(50)  NOT NULL,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_client_notes_client_id ON client_notes(client_id);
+
+-- trainer_id เป็น NULL = ท่าฝึกกลาง (Global)
+CREATE TABLE exercises (
+    id                SERIAL PRIMARY KEY,
+    trainer_id        INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    name              VARCHAR(255) NOT NULL,
+    category          VARCHAR(50)  NOT NULL,
+    primary_muscles   TEXT[]       NOT NULL DEFAULT '{}',
+    secondary_muscles TEXT[]       NOT NULL DEFAULT '{}',
+    equipment         VARCHAR(100),
+    instructions      TEXT         NOT NULL DEFAULT '',
+    created_at        TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_exercises_trainer_id ON exercises(trainer_id);
+CREATE INDEX idx_exercises_category ON exercises(category);
+
+CREATE TABLE programs (
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(255) NOT NULL,
+    description TEXT         NOT NULL DEFAULT '',
+    trainer_id  INTEGER      NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    client_id   INTEGER REFERENCES clients(id) ON DELETE SET NULL,
+    is_template BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_programs_trainer_id ON programs(trainer_id);
+CREATE INDEX idx_programs_client_id ON programs(client_id);
+
+CREATE TABLE program_exercises (
+    id               SERIAL PRIMARY KEY,
+    program_id       INTEGER NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    exercise_id      INTEGER NOT NULL REFERENCES exercises(id),
+    sets             INTEGER NOT NULL DEFAULT 0,
+    reps             INTEGER NOT NULL DEFAULT 0,
+    duration_seconds INTEGER NOT NULL DEFAULT 0,
+    rest_seconds     INTEGER NOT NULL DEFAULT 0,
+    notes            TEXT    NOT NULL DEFAULT '',
+    "order"          INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX idx_program_exercises_program_id ON program_exercises(program_id);
+
+CREATE TABLE schedules (
+    id         SERIAL PRIMARY KEY,
+    title      VARCHAR(255) NOT NULL,
+    trainer_id INTEGER      NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    client_id  INTEGER      NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    start_time TIMESTAMPTZ  NOT NULL,
+    end_time   TIMESTAMPTZ  NOT NULL,
+    status     VARCHAR(20)  NOT NULL DEFAULT 'scheduled',
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_schedules_trainer_id_start_time ON schedules(trainer_id, start_time);
+CREATE INDEX idx_schedules_client_id_start_time ON schedules(client_id, start_time);
+
+CREATE TABLE assignments (
+    id          SERIAL PRIMARY KEY,
+    title       VARCHAR(255) NOT NULL,
+    description TEXT         NOT NULL DEFAULT '',
+    client_id   INTEGER      NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    trainer_id  INTEGER      NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    due_date    TIMESTAMPTZ  NOT NULL,
+    status      VARCHAR(20)  NOT NULL DEFAULT 'pending',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_assignments_trainer_id ON assignments(trainer_id);
+CREATE INDEX idx_assignments_client_id ON assignments(client_id);
+
+CREATE TABLE session_logs (
+    id          SERIAL PRIMARY KEY,
+    schedule_id INTEGER     NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    exercise_id INTEGER REFERENCES exercises(id),
+    notes       TEXT        NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_session_logs_schedule_id ON session_logs(schedule_id);
+
+CREATE TABLE session_log_sets (
+    id             SERIAL PRIMARY KEY,
+    session_log_id INTEGER       NOT NULL REFERENCES session_logs(id) ON DELETE CASCADE,
+    set_number     INTEGER       NOT NULL,
+    weight_kg      NUMERIC(6, 2) NOT NULL DEFAULT 0,
+    reps           INTEGER       NOT NULL DEFAULT 0,
+    rpe            SMALLINT      NOT NULL DEFAULT 0 CHECK (rpe BETWEEN 0 AND 10)
+);
+CREATE INDEX idx_session_log_sets_session_log_id ON session_log_sets(session_log_id);
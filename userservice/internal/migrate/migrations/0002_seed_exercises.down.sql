@@ -0,0 +1,9 @@
+DELETE FROM exercises
+WHERE trainer_id IS NULL
+  AND name IN (
+    'Barbell Back Squat', 'Conventional Deadlift', 'Barbell Bench Press', 'Overhead Press',
+    'Barbell Row', 'Pull-up', 'Dumbbell Lunge', 'Romanian Deadlift', 'Hip Thrust',
+    'Standing Calf Raise', 'Dumbbell Biceps Curl', 'Triceps Pushdown', 'Push-up', 'Plank',
+    'Kettlebell Swing', 'Burpee', 'Rowing Machine', 'Treadmill Run', 'Box Jump',
+    'World''s Greatest Stretch'
+  );
@@ -0,0 +1,23 @@
+-- 0002_seed_exercises: ท่าฝึกกลาง (Global) เริ่มต้นของคลังท่าฝึก
+
+INSERT INTO exercises (trainer_id, name, category, primary_muscles, secondary_muscles, equipment, instructions) VALUES
+    (NULL, 'Barbell Back Squat',   'strength', '{quadriceps,glutes}',  '{hamstrings,lower_back}', 'barbell',    'Brace, sit down between the hips, drive up through the mid-foot.'),
+    (NULL, 'Conventional Deadlift', 'strength', '{hamstrings,glutes}',  '{lower_back,traps,forearms}', 'barbell', 'Bar over mid-foot, hinge, keep a neutral spine and push the floor away.'),
+    (NULL, 'Barbell Bench Press',  'strength', '{chest}',              '{triceps,shoulders}',     'barbell',    'Retract the shoulder blades, lower to the lower chest, press back up.'),
+    (NULL, 'Overhead Press',       'strength', '{shoulders}',          '{triceps,traps}',         'barbell',    'Squeeze glutes, press the bar overhead in a straight line.'),
+    (NULL, 'Barbell Row',          'strength', '{back,lats}',          '{biceps,lower_back}',     'barbell',    'Hinge to roughly 45 degrees and row the bar to the lower ribs.'),
+    (NULL, 'Pull-up',              'strength', '{lats}',               '{biceps,back}',           'pull_up_bar', 'Start from a dead hang and pull the chin over the bar.'),
+    (NULL, 'Dumbbell Lunge',       'strength', '{quadriceps,glutes}',  '{hamstrings,adductors}',  'dumbbell',   'Step forward, lower the back knee toward the floor, push back to standing.'),
+    (NULL, 'Romanian Deadlift',    'strength', '{hamstrings}',         '{glutes,lower_back}',     'barbell',    'Soft knees, push the hips back until a hamstring stretch, then stand tall.'),
+    (NULL, 'Hip Thrust',           'strength', '{glutes}',             '{hamstrings}',            'barbell',    'Upper back on a bench, drive the hips up and pause at lockout.'),
+    (NULL, 'Standing Calf Raise',  'strength', '{calves}',             '{}',                      'machine',    'Full stretch at the bottom, pause at the top.'),
+    (NULL, 'Dumbbell Biceps Curl', 'strength', '{biceps}',             '{forearms}',              'dumbbell',   'Keep the elbows pinned and curl without swinging.'),
+    (NULL, 'Triceps Pushdown',     'strength', '{triceps}',            '{}',                      'cable',      'Elbows at the sides, extend fully at the bottom.'),
+    (NULL, 'Push-up',              'strength', '{chest}',              '{triceps,shoulders,abs}', NULL,         'Body in a straight line, lower the chest to the floor and press up.'),
+    (NULL, 'Plank',                'strength', '{abs}',                '{obliques,shoulders}',    NULL,         'Forearms under shoulders, hold a straight line from head to heels.'),
+    (NULL, 'Kettlebell Swing',     'conditioning', '{glutes,hamstrings}', '{lower_back,shoulders}', 'kettlebell', 'Hinge and snap the hips to float the bell to chest height.'),
+    (NULL, 'Burpee',               'conditioning', '{full_body}',     '{}',                      NULL,         'Squat, kick back to a plank, return and jump.'),
+    (NULL, 'Rowing Machine',       'cardio',   '{back,quadriceps}',    '{biceps,hamstrings}',     'rower',      'Legs, body, arms on the drive; arms, body, legs on the recovery.'),
+    (NULL, 'Treadmill Run',        'cardio',   '{quadriceps,calves}',  '{hamstrings,glutes}',     'treadmill',  'Run at the prescribed pace or heart-rate zone.'),
+    (NULL, 'Box Jump',             'plyometrics', '{quadriceps,glutes}', '{calves}',              'box',        'Swing the arms, jump and land softly on the box, step down.'),
+    (NULL, 'World''s Greatest Stretch', 'mobility', '{hamstrings,glutes}', '{adductors,back}',   NULL,         'Lunge, elbow to instep, rotate and reach to the ceiling.');
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS client_invitations;
+ALTER TABLE clients DROP COLUMN IF EXISTS user_id;
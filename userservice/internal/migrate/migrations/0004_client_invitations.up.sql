@@ -0,0 +1,16 @@
+-- 0004_client_invitations: เชื่อม clients กับบัญชีผู้ใช้ (role = client) ผ่านคำเชิญ
+
+ALTER TABLE clients ADD COLUMN user_id INTEGER UNIQUE REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE TABLE client_invitations (
+    id               SERIAL PRIMARY KEY,
+    client_id        INTEGER      NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    trainer_id       INTEGER      NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email            VARCHAR(255) NOT NULL,
+    token_hash       CHAR(64)     NOT NULL UNIQUE,
+    expires_at       TIMESTAMPTZ  NOT NULL,
+    accepted_at      TIMESTAMPTZ,
+    accepted_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at       TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_client_invitations_client_id ON client_invitations(client_id);
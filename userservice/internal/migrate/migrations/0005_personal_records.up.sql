@@ -0,0 +1,18 @@
+-- 0005_personal_records: สถิติสูงสุดส่วนตัว (PR) ของลูกค้าแต่ละท่า อ้างอิงถึงเซตที่ทำได้
+
+CREATE TABLE personal_records (
+    id                 SERIAL PRIMARY KEY,
+    client_id          INTEGER      NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    exercise_id        INTEGER      NOT NULL REFERENCES exercises(id) ON DELETE CASCADE,
+    record_type        VARCHAR(20)  NOT NULL CHECK (record_type IN ('max_weight', 'e1rm', 'reps_at_weight')),
+    value              NUMERIC(8,2) NOT NULL,
+    previous_value     NUMERIC(8,2),
+    weight_kg          NUMERIC(6,2) NOT NULL,
+    reps               INTEGER      NOT NULL,
+    session_log_set_id INTEGER      NOT NULL REFERENCES session_log_sets(id) ON DELETE CASCADE,
+    schedule_id        INTEGER      NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    achieved_at        TIMESTAMPTZ  NOT NULL,
+    created_at         TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_personal_records_client_exercise ON personal_records(client_id, exercise_id, achieved_at DESC);
+CREATE INDEX idx_personal_records_set_id ON personal_records(session_log_set_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS body_measurement_values;
+DROP TABLE IF EXISTS body_measurements;
+DROP TABLE IF EXISTS measurement_types;
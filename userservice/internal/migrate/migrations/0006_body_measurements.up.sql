@@ -0,0 +1,57 @@
+-- 0006_body_measurements: ประวัติการวัดสัดส่วนร่างกาย (แทนการเก็บ height/weight ค่าเดียวใน clients)
+
+-- ชนิดของค่าที่วัด: trainer_id = NULL คือชนิดมาตรฐานของระบบ, มีค่าคือชนิดที่เทรนเนอร์สร้างเอง
+CREATE TABLE measurement_types (
+    id         SERIAL PRIMARY KEY,
+    trainer_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    key        VARCHAR(50)  NOT NULL,
+    name       VARCHAR(100) NOT NULL,
+    unit       VARCHAR(20)  NOT NULL,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+CREATE UNIQUE INDEX idx_measurement_types_owner_key ON measurement_types(COALESCE(trainer_id, 0), key);
+
+INSERT INTO measurement_types (key, name, unit) VALUES
+    ('weight',   'Weight',   'kg'),
+    ('height',   'Height',   'cm'),
+    ('body_fat', 'Body Fat', '%'),
+    ('waist',    'Waist',    'cm'),
+    ('hip',      'Hip',      'cm'),
+    ('arm',      'Arm',      'cm'),
+    ('thigh',    'Thigh',    'cm');
+
+-- การวัด 1 ครั้ง (1 วันที่) มีได้หลายค่า
+CREATE TABLE body_measurements (
+    id          SERIAL PRIMARY KEY,
+    client_id   INTEGER     NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    measured_at TIMESTAMPTZ NOT NULL,
+    notes       TEXT        NOT NULL DEFAULT '',
+    recorded_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_body_measurements_client_measured_at ON body_measurements(client_id, measured_at DESC);
+
+CREATE TABLE body_measurement_values (
+    measurement_id INTEGER      NOT NULL REFERENCES body_measurements(id) ON DELETE CASCADE,
+    type_id        INTEGER      NOT NULL REFERENCES measurement_types(id) ON DELETE RESTRICT,
+    value          NUMERIC(7,2) NOT NULL,
+    PRIMARY KEY (measurement_id, type_id)
+);
+CREATE INDEX idx_body_measurement_values_type_id ON body_measurement_values(type_id);
+
+-- ย้ายค่า height/weight เดิมของลูกค้าเป็นการวัดครั้งแรก
+WITH seeded AS (
+    INSERT INTO body_measurements (client_id, measured_at, notes)
+    SELECT id, updated_at, 'Imported from client profile'
+    FROM clients
+    WHERE weight_kg IS NOT NULL OR height_cm IS NOT NULL
+    RETURNING id, client_id
+)
+INSERT INTO body_measurement_values (measurement_id, type_id, value)
+SELECT s.id, t.id, CASE t.key WHEN 'weight' THEN c.weight_kg ELSE c.height_cm END
+FROM seeded s
+JOIN clients c ON c.id = s.client_id
+JOIN measurement_types t ON t.trainer_id IS NULL AND t.key IN ('weight', 'height')
+WHERE (t.key = 'weight' AND c.weight_kg IS NOT NULL)
+   OR (t.key = 'height' AND c.height_cm IS NOT NULL);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_schedules_series_original_start;
+ALTER TABLE schedules
+    DROP COLUMN IF EXISTS is_exception,
+    DROP COLUMN IF EXISTS original_start,
+    DROP COLUMN IF EXISTS series_id;
+DROP TABLE IF EXISTS schedule_series;
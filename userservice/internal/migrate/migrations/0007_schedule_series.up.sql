@@ -0,0 +1,24 @@
+-- 0007_schedule_series: ตารางนัดแบบเกิดซ้ำ (RRULE ตาม RFC 5545) ขยายเป็นแถวใน schedules
+
+CREATE TABLE schedule_series (
+    id         SERIAL PRIMARY KEY,
+    title      VARCHAR(255) NOT NULL,
+    trainer_id INTEGER      NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    client_id  INTEGER      NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    rrule      TEXT         NOT NULL,
+    start_time TIMESTAMPTZ  NOT NULL, -- DTSTART ของครั้งแรก
+    end_time   TIMESTAMPTZ  NOT NULL, -- เวลาจบของครั้งแรก (ใช้หาความยาวของแต่ละครั้ง)
+    timezone   VARCHAR(64)  NOT NULL DEFAULT 'UTC',
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_schedule_series_trainer_id ON schedule_series(trainer_id);
+
+-- ลบ series แล้วครั้งที่เกิดขึ้นจริง (มี Log/เสร็จแล้ว) ยังอยู่ เป็นนัดเดี่ยว
+ALTER TABLE schedules
+    ADD COLUMN series_id      INTEGER REFERENCES schedule_series(id) ON DELETE SET NULL,
+    ADD COLUMN original_start TIMESTAMPTZ,
+    ADD COLUMN is_exception   BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- 1 series มีได้ครั้งเดียวต่อเวลาเริ่มตาม Rule (ครั้งที่ถูกแก้/ยกเลิกจะกันไม่ให้ถูกสร้างซ้ำ)
+CREATE UNIQUE INDEX idx_schedules_series_original_start ON schedules(series_id, original_start) WHERE series_id IS NOT NULL;
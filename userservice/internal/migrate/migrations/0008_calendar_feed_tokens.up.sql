@@ -0,0 +1,9 @@
+-- 0008_calendar_feed_tokens: ลิงก์ลับสำหรับ Subscribe ปฏิทิน (ICS) ผู้ใช้ละ 1 ลิงก์ เก็บเฉพาะ hash
+
+CREATE TABLE calendar_feed_tokens (
+    id               SERIAL PRIMARY KEY,
+    user_id          INTEGER     NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    token_hash       CHAR(64)    NOT NULL UNIQUE,
+    last_accessed_at TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1 @@
+ALTER TABLE programs DROP COLUMN IF EXISTS source_template_id;
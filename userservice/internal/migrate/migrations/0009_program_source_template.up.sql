@@ -0,0 +1,7 @@
+-- 0009_program_source_template: โปรแกรมของลูกค้าที่ copy มาจาก Template จำที่มาไว้
+-- (ลบ Template แล้วโปรแกรมของลูกค้ายังอยู่)
+
+ALTER TABLE programs
+    ADD COLUMN source_template_id INTEGER REFERENCES programs(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_programs_source_template_id ON programs(source_template_id);
@@ -0,0 +1,4 @@
+ALTER TABLE program_exercises DROP COLUMN IF EXISTS day_id;
+DROP TABLE IF EXISTS program_days;
+DROP TABLE IF EXISTS program_weeks;
+DROP TABLE IF EXISTS program_phases;
@@ -0,0 +1,40 @@
+-- 0010_program_structure: โปรแกรมแบบแบ่งช่วง (Phase) -> สัปดาห์ (Week) -> วันฝึก (Day) -> ท่าฝึก
+-- ท่าฝึกที่ day_id = NULL คือรายการแบบเดิม (ไม่มีโครงสร้าง)
+
+CREATE TABLE program_phases (
+    id          SERIAL PRIMARY KEY,
+    program_id  INTEGER      NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    name        VARCHAR(255) NOT NULL,
+    description TEXT         NOT NULL DEFAULT '',
+    "order"     INTEGER      NOT NULL DEFAULT 0
+);
+CREATE INDEX idx_program_phases_program_id ON program_phases(program_id);
+
+-- "order" ของสัปดาห์นับทั้งโปรแกรม (สัปดาห์ที่ = ลำดับ) ส่วน phase_id เป็นการจัดกลุ่ม
+-- override มีค่า = ใช้แทน sets/reps ของทุกท่าในสัปดาห์นั้น, intensity_pct = % ของ 1RM
+CREATE TABLE program_weeks (
+    id            SERIAL PRIMARY KEY,
+    program_id    INTEGER      NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    phase_id      INTEGER REFERENCES program_phases(id) ON DELETE SET NULL,
+    name          VARCHAR(255) NOT NULL DEFAULT '',
+    "order"       INTEGER      NOT NULL DEFAULT 0,
+    sets_override INTEGER CHECK (sets_override > 0),
+    reps_override INTEGER CHECK (reps_override > 0),
+    intensity_pct NUMERIC(5, 2) CHECK (intensity_pct > 0 AND intensity_pct <= 150),
+    notes         TEXT         NOT NULL DEFAULT ''
+);
+CREATE INDEX idx_program_weeks_program_id ON program_weeks(program_id);
+
+CREATE TABLE program_days (
+    id      SERIAL PRIMARY KEY,
+    week_id INTEGER      NOT NULL REFERENCES program_weeks(id) ON DELETE CASCADE,
+    name    VARCHAR(255) NOT NULL DEFAULT '',
+    "order" INTEGER      NOT NULL DEFAULT 0,
+    notes   TEXT         NOT NULL DEFAULT ''
+);
+CREATE INDEX idx_program_days_week_id ON program_days(week_id);
+
+ALTER TABLE program_exercises
+    ADD COLUMN day_id INTEGER REFERENCES program_days(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_program_exercises_day_id ON program_exercises(day_id);
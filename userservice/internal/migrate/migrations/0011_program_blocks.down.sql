@@ -0,0 +1,4 @@
+ALTER TABLE session_log_sets DROP COLUMN IF EXISTS round;
+ALTER TABLE session_logs DROP COLUMN IF EXISTS block_id;
+ALTER TABLE program_exercises DROP COLUMN IF EXISTS block_id;
+DROP TABLE IF EXISTS program_blocks;
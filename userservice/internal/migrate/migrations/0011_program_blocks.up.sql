@@ -0,0 +1,27 @@
+-- 0011_program_blocks: จัดกลุ่มท่าฝึกในวันฝึกเป็น Block (straight sets, superset, circuit, EMOM, AMRAP)
+-- ท่าที่ block_id = NULL คือท่าเดี่ยวในวันฝึก
+
+CREATE TABLE program_blocks (
+    id               SERIAL PRIMARY KEY,
+    day_id           INTEGER      NOT NULL REFERENCES program_days(id) ON DELETE CASCADE,
+    block_type       VARCHAR(20)  NOT NULL DEFAULT 'straight'
+                     CHECK (block_type IN ('straight', 'superset', 'circuit', 'emom', 'amrap')),
+    name             VARCHAR(255) NOT NULL DEFAULT '',
+    rounds           INTEGER      NOT NULL DEFAULT 1 CHECK (rounds > 0),
+    rest_seconds     INTEGER      NOT NULL DEFAULT 0 CHECK (rest_seconds >= 0), -- พักระหว่างรอบ
+    duration_seconds INTEGER CHECK (duration_seconds > 0),                      -- เวลาทั้ง Block (EMOM/AMRAP)
+    "order"          INTEGER      NOT NULL DEFAULT 0,
+    notes            TEXT         NOT NULL DEFAULT ''
+);
+CREATE INDEX idx_program_blocks_day_id ON program_blocks(day_id);
+
+-- ลบ Block แล้วท่าฝึกยังอยู่ในวันฝึกเป็นท่าเดี่ยว
+ALTER TABLE program_exercises
+    ADD COLUMN block_id INTEGER REFERENCES program_blocks(id) ON DELETE SET NULL;
+CREATE INDEX idx_program_exercises_block_id ON program_exercises(block_id);
+
+-- บันทึกผลตาม Block: Log อ้างอิง Block ของโปรแกรม และแต่ละเซตระบุว่าเป็นรอบที่เท่าไร
+ALTER TABLE session_logs
+    ADD COLUMN block_id INTEGER REFERENCES program_blocks(id) ON DELETE SET NULL;
+ALTER TABLE session_log_sets
+    ADD COLUMN round INTEGER CHECK (round > 0);
@@ -0,0 +1,9 @@
+ALTER TABLE program_exercises
+    DROP CONSTRAINT IF EXISTS program_exercises_reps_range,
+    DROP COLUMN IF EXISTS reps_min,
+    DROP COLUMN IF EXISTS reps_max,
+    DROP COLUMN IF EXISTS target_rpe,
+    DROP COLUMN IF EXISTS target_rir,
+    DROP COLUMN IF EXISTS percent_1rm,
+    DROP COLUMN IF EXISTS tempo,
+    DROP COLUMN IF EXISTS load_kg;
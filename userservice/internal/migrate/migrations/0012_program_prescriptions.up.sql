@@ -0,0 +1,12 @@
+-- 0012_program_prescriptions: ใบสั่งฝึกแบบละเอียด (ช่วงจำนวนครั้ง, RPE/RIR เป้าหมาย, %1RM, Tempo, น้ำหนักเป้าหมาย)
+-- ทุกคอลัมน์ nullable: null = ไม่ได้กำหนด (ใช้ sets/reps เดิม)
+
+ALTER TABLE program_exercises
+    ADD COLUMN reps_min    INTEGER CHECK (reps_min > 0),
+    ADD COLUMN reps_max    INTEGER CHECK (reps_max > 0),
+    ADD COLUMN target_rpe  NUMERIC(3, 1) CHECK (target_rpe > 0 AND target_rpe <= 10),
+    ADD COLUMN target_rir  INTEGER CHECK (target_rir >= 0 AND target_rir <= 10),
+    ADD COLUMN percent_1rm NUMERIC(5, 2) CHECK (percent_1rm > 0 AND percent_1rm <= 150),
+    ADD COLUMN tempo       VARCHAR(20),
+    ADD COLUMN load_kg     NUMERIC(6, 2) CHECK (load_kg >= 0),
+    ADD CONSTRAINT program_exercises_reps_range CHECK (reps_min IS NULL OR reps_max IS NULL OR reps_min <= reps_max);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS assignment_attachments;
+DROP INDEX IF EXISTS idx_assignments_status_due_date;
+ALTER TABLE assignments
+    DROP CONSTRAINT IF EXISTS assignments_status_check,
+    DROP COLUMN IF EXISTS submission_notes,
+    DROP COLUMN IF EXISTS submitted_at,
+    DROP COLUMN IF EXISTS feedback,
+    DROP COLUMN IF EXISTS reviewed_at;
@@ -0,0 +1,30 @@
+-- 0013_assignment_lifecycle: สถานะงานมอบหมายแบบกำหนดขั้นตอน + การส่งงานของลูกค้าและการตรวจของเทรนเนอร์
+-- pending -> in_progress -> submitted -> reviewed (ส่งกลับแก้ได้: submitted -> in_progress)
+-- pending/in_progress ที่เลย due_date -> overdue (ยังส่งงานล่าช้าได้)
+
+-- สถานะเดิมเป็น free text: แปลงค่าที่ไม่รู้จักก่อนเพิ่ม CHECK
+UPDATE assignments
+SET status = CASE WHEN status IN ('completed', 'done') THEN 'submitted' ELSE 'pending' END
+WHERE status NOT IN ('pending', 'in_progress', 'submitted', 'reviewed', 'overdue');
+
+ALTER TABLE assignments
+    ADD CONSTRAINT assignments_status_check
+        CHECK (status IN ('pending', 'in_progress', 'submitted', 'reviewed', 'overdue')),
+    ADD COLUMN submission_notes TEXT NOT NULL DEFAULT '',
+    ADD COLUMN submitted_at     TIMESTAMPTZ,
+    ADD COLUMN feedback         TEXT NOT NULL DEFAULT '',
+    ADD COLUMN reviewed_at      TIMESTAMPTZ;
+
+-- ตัวตรวจงานเลยกำหนดค้นหาจาก (status, due_date)
+CREATE INDEX idx_assignments_status_due_date ON assignments(status, due_date);
+
+-- ไฟล์แนบตอนส่งงาน (เก็บเป็นลิงก์ไฟล์ที่อัปโหลดไว้แล้ว)
+CREATE TABLE assignment_attachments (
+    id            SERIAL PRIMARY KEY,
+    assignment_id INTEGER      NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    url           TEXT         NOT NULL,
+    file_name     VARCHAR(255) NOT NULL DEFAULT '',
+    content_type  VARCHAR(100) NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_assignment_attachments_assignment_id ON assignment_attachments(assignment_id);
@@ -0,0 +1,41 @@
+-- 0014_notifications: Outbox ของการแจ้งเตือน (ส่งโดย Dispatcher เบื้องหลัง), Inbox ในแอป และการตั้งค่าของผู้ใช้
+
+-- ค่าที่ไม่มีแถว = ค่าเริ่มต้น (ภาษาตาม NOTIFY_DEFAULT_LOCALE, อีเมลเปิด, SMS ปิด)
+CREATE TABLE notification_preferences (
+    user_id       INTEGER     PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    locale        VARCHAR(5)  NOT NULL DEFAULT 'th' CHECK (locale IN ('th', 'en')),
+    email_enabled BOOLEAN     NOT NULL DEFAULT TRUE,
+    sms_enabled   BOOLEAN     NOT NULL DEFAULT FALSE,
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- 1 แถว = 1 ข้อความต่อ 1 ช่องทาง ถูกเพิ่มใน Transaction เดียวกับข้อมูลที่เป็นต้นเหตุ
+-- dedupe_key กันการแจ้งเตือนซ้ำ (เช่น เตือนนัดเดิมรอบที่สอง) ค่า NULL = ไม่ตรวจซ้ำ
+CREATE TABLE notification_outbox (
+    id              SERIAL PRIMARY KEY,
+    user_id         INTEGER      NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel         VARCHAR(20)  NOT NULL CHECK (channel IN ('email', 'sms', 'in_app')),
+    template        VARCHAR(50)  NOT NULL,
+    payload         JSONB        NOT NULL DEFAULT '{}',
+    dedupe_key      VARCHAR(255) UNIQUE,
+    status          VARCHAR(20)  NOT NULL DEFAULT 'pending'
+                    CHECK (status IN ('pending', 'sent', 'failed', 'skipped')),
+    attempts        INTEGER      NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    last_error      TEXT         NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    sent_at         TIMESTAMPTZ
+);
+CREATE INDEX idx_notification_outbox_pending ON notification_outbox(next_attempt_at) WHERE status = 'pending';
+
+-- Inbox ในแอป (ช่องทาง in_app)
+CREATE TABLE notifications (
+    id         SERIAL PRIMARY KEY,
+    user_id    INTEGER      NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    template   VARCHAR(50)  NOT NULL DEFAULT '',
+    title      VARCHAR(255) NOT NULL,
+    body       TEXT         NOT NULL DEFAULT '',
+    read_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_notifications_user_id_created_at ON notifications(user_id, created_at DESC);
@@ -0,0 +1,17 @@
+-- 0015_user_verification: ยืนยันอีเมล และ token แบบใช้ครั้งเดียวสำหรับยืนยันอีเมล/รีเซ็ตรหัสผ่าน
+
+-- บัญชีที่มีอยู่แล้วถือว่ายืนยันแล้ว (ไม่ให้ถูกล็อกเมื่อเปิด REQUIRE_EMAIL_VERIFICATION)
+ALTER TABLE users ADD COLUMN verified BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE users SET verified = TRUE;
+
+-- เก็บเฉพาะ hash ของ token; used_at ไม่เป็น NULL = ใช้แล้ว (หรือถูกแทนที่ด้วย token ใหม่)
+CREATE TABLE user_tokens (
+    id         SERIAL PRIMARY KEY,
+    user_id    INTEGER     NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose    VARCHAR(20) NOT NULL CHECK (purpose IN ('verify_email', 'reset_password')),
+    token_hash CHAR(64)    NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_user_tokens_user_id_purpose ON user_tokens(user_id, purpose);
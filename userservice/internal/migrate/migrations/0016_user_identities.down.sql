@@ -0,0 +1,2 @@
+-- รหัสผ่านตายตัวของบัญชี Google เดิมจะไม่ถูกคืนค่า
+DROP TABLE IF EXISTS user_identities;
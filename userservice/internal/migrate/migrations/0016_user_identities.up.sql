@@ -0,0 +1,20 @@
+-- 0016_user_identities: เชื่อมบัญชีกับผู้ให้บริการ OIDC ภายนอก (Google ฯลฯ) ด้วย (provider, subject)
+-- ผู้ใช้ที่สมัครผ่านผู้ให้บริการภายนอกไม่มีรหัสผ่าน (password_hash = '')
+
+CREATE TABLE user_identities (
+    id            SERIAL PRIMARY KEY,
+    user_id       INTEGER      NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider      VARCHAR(50)  NOT NULL,
+    subject       VARCHAR(255) NOT NULL,
+    email         VARCHAR(255) NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    last_login_at TIMESTAMPTZ,
+    UNIQUE (provider, subject)
+);
+CREATE INDEX idx_user_identities_user_id ON user_identities(user_id);
+
+-- บัญชี Google เดิมถูกสร้างด้วยรหัสผ่านตายตัว ซึ่งใครก็ Login ผ่าน /auth/login ได้ -> ล้างให้เป็นบัญชีไม่มีรหัสผ่าน
+-- (Login ผ่าน Google ครั้งถัดไปจะเชื่อม identity ให้จากอีเมลที่ Google ยืนยันแล้ว หรือตั้งรหัสผ่านใหม่ผ่าน forgot-password)
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+UPDATE users SET password_hash = ''
+WHERE password_hash LIKE '$2%' AND crypt('google_user_placeholder_password', password_hash) = password_hash;
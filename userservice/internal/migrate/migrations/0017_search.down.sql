@@ -0,0 +1,10 @@
+-- ไม่ลบ extension pg_trgm (อาจถูกใช้ที่อื่นในฐานข้อมูล)
+DROP INDEX IF EXISTS idx_exercises_search_trgm;
+DROP INDEX IF EXISTS idx_exercises_search_fts;
+DROP INDEX IF EXISTS idx_programs_search_trgm;
+DROP INDEX IF EXISTS idx_programs_search_fts;
+DROP INDEX IF EXISTS idx_client_notes_search_trgm;
+DROP INDEX IF EXISTS idx_client_notes_search_fts;
+DROP INDEX IF EXISTS idx_clients_search_phone;
+DROP INDEX IF EXISTS idx_clients_search_trgm;
+DROP INDEX IF EXISTS idx_clients_search_fts;
@@ -0,0 +1,26 @@
+-- 0017_search: ค้นหาข้ามลูกค้า บันทึก โปรแกรม และท่าฝึก (GET /api/v1/search)
+-- Full-text ด้วย to_tsvector('simple', ...) (ไม่ตัดรากศัพท์ ใช้ได้ทุกภาษา) + Trigram สำหรับภาษาไทยที่ไม่เว้นวรรคและคำที่พิมพ์ไม่ครบ
+-- นิพจน์ใน index ต้องตรงกับ searchSources ใน internal/repository/search_repository.go ทุกตัวอักษร
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_clients_search_fts ON clients
+    USING GIN (to_tsvector('simple', name || ' ' || coalesce(email, '') || ' ' || coalesce(phone_number, '')));
+CREATE INDEX idx_clients_search_trgm ON clients
+    USING GIN ((name || ' ' || coalesce(email, '') || ' ' || coalesce(phone_number, '')) gin_trgm_ops);
+-- เบอร์โทรเฉพาะตัวเลข (ค้น 0812345678 เจอ 081-234-5678)
+CREATE INDEX idx_clients_search_phone ON clients
+    USING GIN (regexp_replace(coalesce(phone_number, ''), '[^0-9]', '', 'g') gin_trgm_ops);
+
+CREATE INDEX idx_client_notes_search_fts ON client_notes USING GIN (to_tsvector('simple', content));
+CREATE INDEX idx_client_notes_search_trgm ON client_notes USING GIN (content gin_trgm_ops);
+
+CREATE INDEX idx_programs_search_fts ON programs
+    USING GIN (to_tsvector('simple', name || ' ' || description));
+CREATE INDEX idx_programs_search_trgm ON programs
+    USING GIN ((name || ' ' || description) gin_trgm_ops);
+
+CREATE INDEX idx_exercises_search_fts ON exercises
+    USING GIN (to_tsvector('simple', name || ' ' || category || ' ' || coalesce(equipment, '') || ' ' || instructions));
+CREATE INDEX idx_exercises_search_trgm ON exercises
+    USING GIN ((name || ' ' || category || ' ' || coalesce(equipment, '') || ' ' || instructions) gin_trgm_ops);
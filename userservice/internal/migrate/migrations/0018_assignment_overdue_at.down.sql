@@ -0,0 +1 @@
+ALTER TABLE assignments DROP COLUMN IF EXISTS overdue_at;
@@ -0,0 +1,6 @@
+-- 0018_assignment_overdue_at: จำว่างานเคยถูกเปลี่ยนเป็น overdue แล้ว
+-- งานที่เริ่มทำหลังเลยกำหนด (overdue -> in_progress) หรือถูกส่งกลับให้แก้หลังเลยกำหนด
+-- ต้องไม่ถูกตัวตรวจเปลี่ยนกลับเป็น overdue อีก (ตัวตรวจเลือกเฉพาะ overdue_at IS NULL)
+ALTER TABLE assignments ADD COLUMN overdue_at TIMESTAMPTZ;
+
+UPDATE assignments SET overdue_at = updated_at WHERE status = 'overdue';
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// PerformedSet เซตที่ถูกบันทึกแล้ว พร้อมเวลาของ Session (ใช้คำนวณสถิติ)
+type PerformedSet struct {
+	SetID       int       `json:"set_id"`
+	ScheduleID  int       `json:"schedule_id"`
+	PerformedAt time.Time `json:"performed_at"`
+	WeightKg    float64   `json:"weight_kg"`
+	Reps        int       `json:"reps"`
+	RPE         int       `json:"rpe"`
+}
+
+// ProgressPoint สถิติของท่าฝึกในช่วงเวลาหนึ่ง (1 สัปดาห์ หรือ 1 เดือน)
+type ProgressPoint struct {
+	PeriodStart    time.Time     `json:"period_start"`
+	Sessions       int           `json:"sessions"`
+	Sets           int           `json:"sets"`
+	TotalVolumeKg  float64       `json:"total_volume_kg"` // ผลรวม weight_kg * reps
+	EstimatedOneRM float64       `json:"estimated_1rm"`   // ค่า 1RM ประมาณการที่ดีที่สุดในช่วงนี้
+	BestSet        *PerformedSet `json:"best_set"`        // เซตที่ให้ค่า 1RM สูงสุด
+	AverageRPE     *float64      `json:"average_rpe"`     // null ถ้าไม่มีเซตไหนบันทึก RPE
+}
+
+// ProgressReport ผลลัพธ์ของ GET /clients/:id/progress
+type ProgressReport struct {
+	ClientID   int             `json:"client_id"`
+	ExerciseID int             `json:"exercise_id"`
+	Formula    string          `json:"formula"`
+	Bucket     string          `json:"bucket"`
+	Timezone   string          `json:"timezone"`
+	Points     []ProgressPoint `json:"points"`
+}
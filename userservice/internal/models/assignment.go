@@ -14,4 +14,36 @@ type Assignment struct {
 	Status      string    `json:"status" db:"status"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+
+	// การส่งงานของลูกค้า และผลตรวจของเทรนเนอร์
+	SubmissionNotes string     `json:"submission_notes" db:"submission_notes"`
+	SubmittedAt     *time.Time `json:"submitted_at" db:"submitted_at"`
+	Feedback        string     `json:"feedback" db:"feedback"`
+	ReviewedAt      *time.Time `json:"reviewed_at" db:"reviewed_at"`
+	// เวลาที่ตัวตรวจเปลี่ยนเป็น overdue (ครั้งเดียวต่อ due_date: เริ่มทำ/แก้งานล่าช้าแล้วจะไม่ถูกเปลี่ยนกลับ)
+	OverdueAt   *time.Time             `json:"overdue_at" db:"overdue_at"`
+	Attachments []AssignmentAttachment `json:"attachments,omitempty"`
+
+	// ข้อมูลลูกค้า (มีค่าเฉพาะเมื่ออ่านงานเดียว)
+	ClientName   string `json:"client_name,omitempty"`
+	ClientUserID *int   `json:"client_user_id,omitempty"`
+}
+
+// สถานะของงานมอบหมาย (ดูการเปลี่ยนสถานะที่อนุญาตใน service.CanTransitionAssignment)
+const (
+	AssignmentPending    = "pending"     // มอบหมายแล้ว ยังไม่เริ่ม
+	AssignmentInProgress = "in_progress" // ลูกค้ากำลังทำ (หรือถูกส่งกลับให้แก้)
+	AssignmentSubmitted  = "submitted"   // ลูกค้าส่งงานแล้ว รอตรวจ
+	AssignmentReviewed   = "reviewed"    // เทรนเนอร์ตรวจแล้ว (สิ้นสุด)
+	AssignmentOverdue    = "overdue"     // เลย DueDate โดยยังไม่ส่ง
+)
+
+// AssignmentAttachment ไฟล์แนบตอนส่งงาน (ลิงก์ไปยังไฟล์ที่อัปโหลดไว้แล้ว)
+type AssignmentAttachment struct {
+	ID           int       `json:"id" db:"id"`
+	AssignmentID int       `json:"assignment_id" db:"assignment_id"`
+	URL          string    `json:"url" db:"url" binding:"required"`
+	FileName     string    `json:"file_name" db:"file_name"`
+	ContentType  string    `json:"content_type" db:"content_type"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 }
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// CalendarFeed ลิงก์ Subscribe ปฏิทินของผู้ใช้ (เก็บเฉพาะ hash ของ token)
+type CalendarFeed struct {
+	ID             int        `json:"id" db:"id"`
+	UserID         int        `json:"user_id" db:"user_id"`
+	TokenHash      string     `json:"-" db:"token_hash"`
+	LastAccessedAt *time.Time `json:"last_accessed_at" db:"last_accessed_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
@@ -4,8 +4,9 @@ import "time"
 
 // Client Struct ที่ตรงกับตาราง clients ใน init.sql ใหม่
 type Client struct {
-	ID        int `json:"id" db:"id"`
-	TrainerID int `json:"trainer_id" db:"trainer_id"`
+	ID        int  `json:"id" db:"id"`
+	TrainerID int  `json:"trainer_id" db:"trainer_id"`
+	UserID    *int `json:"user_id" db:"user_id"` // บัญชีผู้ใช้ของลูกค้า (ถ้ารับคำเชิญแล้ว)
 
 	// ข้อมูลส่วนตัว
 	Name      string  `json:"name" db:"name" binding:"required"`
@@ -16,8 +17,8 @@ type Client struct {
 	// ข้อมูลสุขภาพ (Profile เดิม)
 	BirthDate         *time.Time `json:"birth_date" db:"birth_date"`
 	Gender            *string    `json:"gender" db:"gender"`
-	Height            *float64   `json:"height" db:"height_cm"`
-	Weight            *float64   `json:"weight" db:"weight_kg"`
+	Height            *float64   `json:"height" db:"height_cm"` // ค่าล่าสุดจาก body_measurements
+	Weight            *float64   `json:"weight" db:"weight_kg"` // ค่าล่าสุดจาก body_measurements
 	Goal              *string    `json:"goal" db:"goal"`
 	Injuries          *string    `json:"injuries" db:"injuries"`
 	ActivityLevel     *string    `json:"activity_level" db:"activity_level"`
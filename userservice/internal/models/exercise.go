@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+// หมวดหมู่ของท่าฝึก (Category)
+var ExerciseCategories = []string{
+	"strength",
+	"cardio",
+	"mobility",
+	"flexibility",
+	"plyometrics",
+	"balance",
+	"conditioning",
+}
+
+// กลุ่มกล้ามเนื้อ (Muscle Groups)
+var MuscleGroups = []string{
+	"chest",
+	"back",
+	"lats",
+	"traps",
+	"shoulders",
+	"biceps",
+	"triceps",
+	"forearms",
+	"abs",
+	"obliques",
+	"lower_back",
+	"glutes",
+	"quadriceps",
+	"hamstrings",
+	"adductors",
+	"abductors",
+	"calves",
+	"full_body",
+}
+
+// Exercise (คลังท่าฝึก)
+// TrainerID เป็น null = ท่าฝึกกลาง (Global) ที่ทุกคนเห็น, ถ้ามีค่า = ท่าฝึกส่วนตัวของเทรนเนอร์คนนั้น
+type Exercise struct {
+	ID               int       `json:"id" db:"id"`
+	TrainerID        *int      `json:"trainer_id" db:"trainer_id"`
+	Name             string    `json:"name" db:"name" binding:"required"`
+	Category         string    `json:"category" db:"category" binding:"required"`
+	PrimaryMuscles   []string  `json:"primary_muscles" db:"primary_muscles"`
+	SecondaryMuscles []string  `json:"secondary_muscles" db:"secondary_muscles"`
+	Equipment        *string   `json:"equipment" db:"equipment"`
+	Instructions     string    `json:"instructions" db:"instructions"`
+	IsGlobal         bool      `json:"is_global"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ExerciseFilter (เงื่อนไขค้นหาท่าฝึก)
+type ExerciseFilter struct {
+	Search    string // ค้นหาจากชื่อ
+	Category  string
+	Muscle    string // ตรงกับ primary หรือ secondary
+	Equipment string
+	Scope     string // all (default), global, mine
+}
+
+// IsValidExerciseCategory ตรวจสอบว่า category อยู่ในรายการที่รองรับ
+func IsValidExerciseCategory(category string) bool {
+	for _, c := range ExerciseCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidMuscleGroup ตรวจสอบว่ากลุ่มกล้ามเนื้ออยู่ในรายการที่รองรับ
+func IsValidMuscleGroup(muscle string) bool {
+	for _, m := range MuscleGroups {
+		if m == muscle {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// ClientInvitation (คำเชิญให้ลูกค้าสมัครบัญชีและเชื่อมกับข้อมูล Client ของเทรนเนอร์)
+type ClientInvitation struct {
+	ID             int        `json:"id" db:"id"`
+	ClientID       int        `json:"client_id" db:"client_id"`
+	TrainerID      int        `json:"trainer_id" db:"trainer_id"`
+	Email          string     `json:"email" db:"email"`
+	TokenHash      string     `json:"-" db:"token_hash"`
+	ExpiresAt      time.Time  `json:"expires_at" db:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at" db:"accepted_at"`
+	AcceptedUserID *int       `json:"accepted_user_id" db:"accepted_user_id"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+
+	// ข้อมูลประกอบสำหรับหน้ารับคำเชิญ (ตอน GET)
+	ClientName  string `json:"client_name,omitempty"`
+	TrainerName string `json:"trainer_name,omitempty"`
+}
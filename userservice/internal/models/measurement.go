@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// ชนิดการวัดมาตรฐานที่ถูกสะท้อนกลับไปที่โปรไฟล์ลูกค้า (clients.weight_kg / height_cm)
+const (
+	MeasurementWeight  = "weight"
+	MeasurementHeight  = "height"
+	MeasurementBodyFat = "body_fat"
+)
+
+// MeasurementType ชนิดของค่าที่วัด (TrainerID = nil คือชนิดมาตรฐานของระบบ)
+type MeasurementType struct {
+	ID        int       `json:"id" db:"id"`
+	TrainerID *int      `json:"trainer_id" db:"trainer_id"`
+	Key       string    `json:"key" db:"key" binding:"required"`
+	Name      string    `json:"name" db:"name" binding:"required"`
+	Unit      string    `json:"unit" db:"unit" binding:"required"`
+	IsBuiltIn bool      `json:"is_built_in"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// BodyMeasurement การวัด 1 ครั้ง: Values เป็น map จาก key ของชนิด -> ค่า เช่น {"weight": 72.5, "waist": 80}
+type BodyMeasurement struct {
+	ID         int                `json:"id" db:"id"`
+	ClientID   int                `json:"client_id" db:"client_id"`
+	MeasuredAt time.Time          `json:"measured_at" db:"measured_at" binding:"required"`
+	Notes      string             `json:"notes" db:"notes"`
+	RecordedBy *int               `json:"recorded_by" db:"recorded_by"`
+	Values     map[string]float64 `json:"values" binding:"required,min=1"`
+	CreatedAt  time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// MeasurementPoint จุดข้อมูลของกราฟ (time series)
+type MeasurementPoint struct {
+	MeasurementID int       `json:"measurement_id"`
+	MeasuredAt    time.Time `json:"measured_at"`
+	Value         float64   `json:"value"`
+}
+
+// LatestMeasurement ค่าล่าสุดของแต่ละชนิด
+type LatestMeasurement struct {
+	Key        string    `json:"key"`
+	Name       string    `json:"name"`
+	Unit       string    `json:"unit"`
+	Value      float64   `json:"value"`
+	MeasuredAt time.Time `json:"measured_at"`
+}
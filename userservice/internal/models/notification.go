@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Notification ข้อความใน Inbox ของแอป
+type Notification struct {
+	ID        int        `json:"id" db:"id"`
+	UserID    int        `json:"user_id" db:"user_id"`
+	Template  string     `json:"template" db:"template"`
+	Title     string     `json:"title" db:"title"`
+	Body      string     `json:"body" db:"body"`
+	ReadAt    *time.Time `json:"read_at" db:"read_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// NotificationPreferences การตั้งค่าการแจ้งเตือนของผู้ใช้ (Inbox ในแอปเปิดเสมอ)
+type NotificationPreferences struct {
+	UserID       int       `json:"user_id" db:"user_id"`
+	Locale       string    `json:"locale" db:"locale"`
+	EmailEnabled bool      `json:"email_enabled" db:"email_enabled"`
+	SMSEnabled   bool      `json:"sms_enabled" db:"sms_enabled"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// OutboxMessage เหตุการณ์ที่ต้องแจ้ง user (ถูกกระจายเป็น 1 แถวต่อช่องทางที่ผู้ใช้เปิดไว้)
+// DedupeKey ว่าง = ไม่ตรวจซ้ำ
+type OutboxMessage struct {
+	UserID    int
+	Template  string
+	Payload   map[string]any
+	DedupeKey string
+
+	// ระบุช่องทางเอง โดยไม่สนการตั้งค่าของผู้ใช้ (เช่น อีเมลยืนยันตัวตน) ว่าง = ตามการตั้งค่า
+	Channels []string
+}
+
+// OutboxDelivery แถวใน outbox ที่ Dispatcher จองไว้ส่ง พร้อมข้อมูลผู้รับ
+type OutboxDelivery struct {
+	ID       int
+	UserID   int
+	Channel  string
+	Template string
+	Payload  map[string]any
+	Attempts int
+
+	Name   string
+	Email  string
+	Phone  string
+	Locale string // ว่าง = ยังไม่ได้ตั้งค่า
+}
@@ -13,8 +13,100 @@ type Program struct {
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
 
+	// Template ต้นฉบับ (มีค่าเมื่อโปรแกรมนี้ถูก assign จาก Template)
+	SourceTemplateID *int `json:"source_template_id" db:"source_template_id"`
+
 	// (Optional) อาจจะมี Exercises []ProgramExercise มาด้วยตอน GET Detail
+	// (เฉพาะท่าที่ไม่ได้อยู่ในวันฝึกใด)
 	Exercises []ProgramExercise `json:"exercises,omitempty"`
+
+	// โครงสร้างแบบแบ่งช่วง (ตอน GET Detail): Phases -> Weeks -> Days -> Exercises
+	// สัปดาห์ที่ไม่ได้อยู่ใน Phase ใดอยู่ใน Weeks
+	Phases []ProgramPhase `json:"phases,omitempty"`
+	Weeks  []ProgramWeek  `json:"weeks,omitempty"`
+}
+
+// ProgramPhase ช่วงของโปรแกรม เช่น "Hypertrophy", "Strength", "Deload"
+type ProgramPhase struct {
+	ID          int    `json:"id" db:"id"`
+	ProgramID   int    `json:"program_id" db:"program_id"`
+	Name        string `json:"name" db:"name" binding:"required"`
+	Description string `json:"description" db:"description"`
+	Order       int    `json:"order" db:"order"`
+
+	Weeks []ProgramWeek `json:"weeks"`
+}
+
+// ProgramWeek สัปดาห์ในโปรแกรม (Order = สัปดาห์ที่ นับทั้งโปรแกรม เริ่มที่ 1)
+// Override มีค่า = ใช้แทน sets/reps ของทุกท่าในสัปดาห์นี้, IntensityPct = % ของ 1RM
+type ProgramWeek struct {
+	ID           int      `json:"id" db:"id"`
+	ProgramID    int      `json:"program_id" db:"program_id"`
+	PhaseID      *int     `json:"phase_id" db:"phase_id"`
+	Name         string   `json:"name" db:"name"`
+	Order        int      `json:"order" db:"order"`
+	SetsOverride *int     `json:"sets_override" db:"sets_override"`
+	RepsOverride *int     `json:"reps_override" db:"reps_override"`
+	IntensityPct *float64 `json:"intensity_pct" db:"intensity_pct"`
+	Notes        string   `json:"notes" db:"notes"`
+
+	Days []ProgramDay `json:"days"`
+}
+
+// ProgramDay วันฝึกในสัปดาห์ เช่น "Day 2: Lower body"
+type ProgramDay struct {
+	ID     int    `json:"id" db:"id"`
+	WeekID int    `json:"week_id" db:"week_id"`
+	Name   string `json:"name" db:"name"`
+	Order  int    `json:"order" db:"order"`
+	Notes  string `json:"notes" db:"notes"`
+
+	// ท่าเดี่ยว (ไม่อยู่ใน Block)
+	Exercises []ProgramExercise `json:"exercises"`
+	Blocks    []ProgramBlock    `json:"blocks"`
+}
+
+// ชนิดของ Block
+const (
+	BlockStraight = "straight" // ทำทีละท่าจนครบเซต
+	BlockSuperset = "superset" // สลับท่าในกลุ่มต่อเนื่อง แล้วพัก
+	BlockCircuit  = "circuit"  // วนทุกท่าในกลุ่มเป็นรอบ
+	BlockEMOM     = "emom"     // Every Minute On the Minute
+	BlockAMRAP    = "amrap"    // As Many Rounds As Possible ภายในเวลา
+)
+
+var BlockTypes = []string{BlockStraight, BlockSuperset, BlockCircuit, BlockEMOM, BlockAMRAP}
+
+func IsValidBlockType(t string) bool {
+	for _, v := range BlockTypes {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+// ProgramBlock กลุ่มท่าฝึกในวันฝึก (Superset/Circuit/EMOM/AMRAP) ทำ Rounds รอบ พัก RestSeconds ระหว่างรอบ
+type ProgramBlock struct {
+	ID              int    `json:"id" db:"id"`
+	DayID           int    `json:"day_id" db:"day_id"`
+	BlockType       string `json:"block_type" db:"block_type"`
+	Name            string `json:"name" db:"name"`
+	Rounds          int    `json:"rounds" db:"rounds"`
+	RestSeconds     int    `json:"rest_seconds" db:"rest_seconds"`
+	DurationSeconds *int   `json:"duration_seconds" db:"duration_seconds"` // เวลาทั้ง Block (EMOM/AMRAP)
+	Order           int    `json:"order" db:"order"`
+	Notes           string `json:"notes" db:"notes"`
+
+	Exercises []ProgramExercise `json:"exercises"`
+}
+
+// ProgramAssignment โปรแกรมของลูกค้าที่ copy มาจาก Template (GET /programs/:id/assignments)
+type ProgramAssignment struct {
+	ProgramID  int       `json:"program_id" db:"program_id"`
+	ClientID   int       `json:"client_id" db:"client_id"`
+	ClientName string    `json:"client_name" db:"client_name"`
+	AssignedAt time.Time `json:"assigned_at" db:"assigned_at"`
 }
 
 // Program Exercise (Detail)
@@ -28,4 +120,28 @@ type ProgramExercise struct {
 	RestSeconds     int    `json:"rest_seconds" db:"rest_seconds"`
 	Notes           string `json:"notes" db:"notes"`
 	Order           int    `json:"order" db:"order"`
+	DayID           *int   `json:"day_id" db:"day_id"`     // null = ไม่ได้อยู่ในวันฝึกใด
+	BlockID         *int   `json:"block_id" db:"block_id"` // null = ท่าเดี่ยว (ไม่อยู่ใน Block)
+
+	// ใบสั่งฝึกแบบละเอียด (null = ไม่ได้กำหนด)
+	RepsMin    *int     `json:"reps_min" db:"reps_min"` // ช่วงจำนวนครั้ง เช่น 8-12
+	RepsMax    *int     `json:"reps_max" db:"reps_max"`
+	TargetRPE  *float64 `json:"target_rpe" db:"target_rpe"`   // 1-10 (กำหนดได้อย่างใดอย่างหนึ่งกับ target_rir)
+	TargetRIR  *int     `json:"target_rir" db:"target_rir"`   // จำนวนครั้งที่เหลือในถัง (Reps In Reserve)
+	Percent1RM *float64 `json:"percent_1rm" db:"percent_1rm"` // % ของ 1RM ของลูกค้า
+	Tempo      *string  `json:"tempo" db:"tempo"`             // เช่น "3-1-X-0" (ลง-ค้าง-ขึ้น-ค้าง)
+	LoadKg     *float64 `json:"load_kg" db:"load_kg"`         // น้ำหนักเป้าหมายแบบตายตัว
+
+	// ค่าที่ใช้จริงหลังรวม Override ของสัปดาห์ (Response เท่านั้น; มีค่าเมื่อท่าอยู่ในวันฝึก)
+	EffectiveSets *int     `json:"effective_sets,omitempty"`
+	EffectiveReps *int     `json:"effective_reps,omitempty"`
+	IntensityPct  *float64 `json:"intensity_pct,omitempty"`
+
+	// น้ำหนักที่คำนวณจาก %1RM (ของท่าหรือของสัปดาห์) กับ 1RM ประมาณการจากประวัติของลูกค้า
+	// (Response เท่านั้น; มีค่าเมื่อโปรแกรมเป็นของลูกค้าและลูกค้าเคยบันทึกท่านี้)
+	Estimated1RM   *float64 `json:"estimated_1rm,omitempty"`
+	ResolvedLoadKg *float64 `json:"resolved_load_kg,omitempty"`
+
+	// รายละเอียดท่าฝึกจากคลังท่าฝึก (ตอน GET)
+	Exercise *Exercise `json:"exercise,omitempty"`
 }
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// ประเภทของสถิติสูงสุดส่วนตัว (PR)
+const (
+	RecordMaxWeight    = "max_weight"     // น้ำหนักมากที่สุด
+	RecordE1RM         = "e1rm"           // ค่า 1RM ประมาณการสูงสุด (สูตร Epley)
+	RecordRepsAtWeight = "reps_at_weight" // จำนวนครั้งมากที่สุดที่น้ำหนักเดียวกัน
+)
+
+// PersonalRecord สถิติสูงสุดส่วนตัวของลูกค้าในท่าหนึ่ง (อ้างอิงถึงเซตที่ทำได้)
+type PersonalRecord struct {
+	ID              int       `json:"id" db:"id"`
+	ClientID        int       `json:"client_id" db:"client_id"`
+	ExerciseID      int       `json:"exercise_id" db:"exercise_id"`
+	ExerciseName    string    `json:"exercise_name,omitempty"`
+	RecordType      string    `json:"record_type" db:"record_type"`
+	Value           float64   `json:"value" db:"value"`                   // kg สำหรับ max_weight/e1rm, จำนวนครั้งสำหรับ reps_at_weight
+	PreviousValue   *float64  `json:"previous_value" db:"previous_value"` // null = PR ครั้งแรกของท่านี้
+	WeightKg        float64   `json:"weight_kg" db:"weight_kg"`
+	Reps            int       `json:"reps" db:"reps"`
+	SessionLogSetID int       `json:"session_log_set_id" db:"session_log_set_id"`
+	ScheduleID      int       `json:"schedule_id" db:"schedule_id"`
+	AchievedAt      time.Time `json:"achieved_at" db:"achieved_at"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+
+	// ตำแหน่งของเซตใน WorkoutLog ที่กำลังบันทึก (ใช้หา session_log_set_id หลัง INSERT)
+	LogIndex int `json:"-"`
+	SetIndex int `json:"-"`
+}
+
+// RepMax จำนวนครั้งมากที่สุดที่เคยทำได้ในน้ำหนักหนึ่ง (ใช้หา PR)
+type RepMax struct {
+	ExerciseID int
+	WeightKg   float64
+	Reps       int
+}
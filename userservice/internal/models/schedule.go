@@ -13,4 +13,46 @@ type Schedule struct {
 	Status    string    `json:"status" db:"status"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+
+	// ข้อมูลของนัดที่มาจาก series (null = นัดเดี่ยว)
+	SeriesID      *int       `json:"series_id" db:"series_id"`
+	OriginalStart *time.Time `json:"original_start" db:"original_start"` // เวลาเริ่มตาม Rule ก่อนถูกแก้
+	IsException   bool       `json:"is_exception" db:"is_exception"`     // ถูกแก้/ยกเลิกเฉพาะครั้งนี้
+
+	// นัดอื่นที่ชนกัน (Response เท่านั้น; มีค่าเมื่อบันทึกด้วย ?conflicts=warn)
+	Conflicts []ScheduleConflict `json:"conflicts,omitempty"`
+}
+
+// ScheduleConflict นัดที่ชนกัน: นัดของเทรนเนอร์คนเดียวกันมีรายละเอียดครบ (ฟิลด์ของ Schedule)
+// นัดของเทรนเนอร์คนอื่นที่ลูกค้าคนนี้มีอยู่ Schedule = nil เห็นเฉพาะช่วงเวลาที่ไม่ว่าง
+type ScheduleConflict struct {
+	*Schedule
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// ScheduleSeries นัดแบบเกิดซ้ำ (RRULE) เช่น "FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=12"
+// StartTime/EndTime คือครั้งแรก (ความยาวของทุกครั้ง = EndTime - StartTime)
+type ScheduleSeries struct {
+	ID        int       `json:"id" db:"id"`
+	Title     string    `json:"title" db:"title" binding:"required"`
+	TrainerID int       `json:"trainer_id" db:"trainer_id"`
+	ClientID  int       `json:"client_id" db:"client_id" binding:"required"`
+	RRule     string    `json:"rrule" db:"rrule" binding:"required"`
+	StartTime time.Time `json:"start_time" db:"start_time" binding:"required"`
+	EndTime   time.Time `json:"end_time" db:"end_time" binding:"required"`
+	Timezone  string    `json:"timezone" db:"timezone"` // IANA เช่น "Asia/Bangkok" (ค่าเริ่มต้น UTC)
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	Occurrences []Schedule         `json:"occurrences,omitempty"`
+	Conflicts   []ScheduleConflict `json:"conflicts,omitempty"`
+}
+
+// ScheduleUpdate Body ของ PUT /schedules/:id
+// RRule/Timezone ใช้เฉพาะ scope=following|all (เปลี่ยน Rule ของ series)
+type ScheduleUpdate struct {
+	Schedule
+	RRule    string `json:"rrule"`
+	Timezone string `json:"timezone"`
 }
@@ -0,0 +1,45 @@
+package models
+
+// ชนิดของผลการค้นหา (GET /api/v1/search?types=...) เรียงตามลำดับกลุ่มในผลลัพธ์
+const (
+	SearchTypeClients   = "clients"
+	SearchTypeNotes     = "notes"
+	SearchTypePrograms  = "programs"
+	SearchTypeExercises = "exercises"
+)
+
+var SearchTypes = []string{SearchTypeClients, SearchTypeNotes, SearchTypePrograms, SearchTypeExercises}
+
+// SearchResult ผลการค้นหาหนึ่งรายการ
+// ClientID ลูกค้าที่เกี่ยวข้อง (ลูกค้าเอง เจ้าของบันทึก หรือลูกค้าของโปรแกรม) null = ไม่มี
+type SearchResult struct {
+	Type     string  `json:"type"`
+	ID       int     `json:"id"`
+	Title    string  `json:"title"`
+	Subtitle string  `json:"subtitle"`
+	ClientID *int    `json:"client_id"`
+	Rank     float64 `json:"rank"`
+
+	// ข้อความที่ใช้ทำ Highlight (ไม่ส่งออก)
+	Body string `json:"-"`
+
+	Highlight SearchHighlight `json:"highlight"`
+}
+
+// SearchHighlight ข้อความที่ escape HTML แล้ว คำที่ตรงกับคำค้นครอบด้วย <mark></mark>
+type SearchHighlight struct {
+	Title   string `json:"title"`
+	Snippet string `json:"snippet"`
+}
+
+// SearchGroup ผลการค้นหาของชนิดหนึ่ง (Total = จำนวนที่ตรงทั้งหมด Results = อันดับต้นๆ ตาม limit)
+type SearchGroup struct {
+	Type    string         `json:"type"`
+	Total   int            `json:"total"`
+	Results []SearchResult `json:"results"`
+}
+
+type SearchResponse struct {
+	Query  string        `json:"query"`
+	Groups []SearchGroup `json:"groups"`
+}
@@ -7,10 +7,15 @@ type SessionLog struct {
 	ID         int       `json:"id" db:"id"`
 	ScheduleID int       `json:"schedule_id" db:"schedule_id"`
 	ExerciseID *int      `json:"exercise_id" db:"exercise_id"` // อาจจะ null ได้
+	BlockID    *int      `json:"block_id" db:"block_id"`       // Block ของโปรแกรมที่ท่านี้อยู่ (Superset/Circuit ฯลฯ)
 	Notes      string    `json:"notes" db:"notes"`
 	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 
-	// (Optional) อาจจะมี Sets []SessionLogSet มาด้วยตอน GET แต่ตอน DB แยกตาราง
+	// รายละเอียดท่าฝึกจากคลังท่าฝึก (ตอน GET)
+	Exercise *Exercise `json:"exercise,omitempty"`
+
+	// เซตของท่านี้ (ตาราง session_log_sets)
+	Sets []SessionLogSet `json:"sets"`
 }
 
 // Session Log Set (รายละเอียดแต่ละเซต)
@@ -21,4 +26,15 @@ type SessionLogSet struct {
 	WeightKg     float64 `json:"weight_kg" db:"weight_kg"`
 	Reps         int     `json:"reps" db:"reps"`
 	RPE          int     `json:"rpe" db:"rpe"`
+	Round        *int    `json:"round" db:"round"` // รอบที่เท่าไรของ Block (null = ไม่ได้ฝึกเป็น Block)
+}
+
+// WorkoutLog (บันทึกผลการฝึกทั้ง Session: หลายท่า แต่ละท่ามีหลายเซต)
+// ใช้ทั้งเป็น Request Body ของ POST /sessions/:id/logs และ Response
+type WorkoutLog struct {
+	ScheduleID int          `json:"schedule_id"`
+	Exercises  []SessionLog `json:"exercises" binding:"required,min=1"`
+
+	// PR ใหม่ที่เกิดขึ้นใน Session นี้ (Response เท่านั้น)
+	NewPRs []PersonalRecord `json:"new_prs"`
 }
@@ -11,4 +11,16 @@ type User struct {
 	PasswordHash string    `db:"password_hash" json:"-"` // json:"-" คือ ห้ามส่งฟิลด์นี้กลับไปใน JSON
 	Role         string    `db:"role" json:"role"`
 	AvatarURL    *string   `db:"avatar_url" json:"avatar_url"`
+	Verified     bool      `db:"verified" json:"verified"` // ยืนยันอีเมลแล้ว
+}
+
+// UserIdentity บัญชีของผู้ใช้ที่ผู้ให้บริการ OIDC ภายนอก (provider, subject ไม่ซ้ำกัน)
+type UserIdentity struct {
+	ID          int        `db:"id" json:"id"`
+	UserID      int        `db:"user_id" json:"user_id"`
+	Provider    string     `db:"provider" json:"provider"`
+	Subject     string     `db:"subject" json:"-"`
+	Email       string     `db:"email" json:"email"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	LastLoginAt *time.Time `db:"last_login_at" json:"last_login_at"`
 }
@@ -0,0 +1,23 @@
+package notify
+
+import "context"
+
+// InboxStore ที่เก็บ Inbox ในแอป (implement ใน repository)
+type InboxStore interface {
+	CreateNotification(userID int, template, title, body string) error
+}
+
+// InAppChannel บันทึกข้อความลง Inbox ของผู้ใช้ (ดูผ่าน GET /api/v1/notifications)
+type InAppChannel struct {
+	store InboxStore
+}
+
+func NewInAppChannel(store InboxStore) *InAppChannel {
+	return &InAppChannel{store: store}
+}
+
+func (c *InAppChannel) Name() string { return ChannelInApp }
+
+func (c *InAppChannel) Send(_ context.Context, to Recipient, msg Message) error {
+	return c.store.CreateNotification(to.UserID, msg.Template, msg.Subject, msg.Body)
+}
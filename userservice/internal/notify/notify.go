@@ -0,0 +1,39 @@
+// Package notify ช่องทางส่งการแจ้งเตือน (SMTP, SMS ผ่าน HTTP, Inbox ในแอป) และ Template ภาษาไทย/อังกฤษ
+// ทุกช่องทาง implement Channel จึงเพิ่มช่องทางใหม่ได้โดยไม่ต้องแก้ Dispatcher
+package notify
+
+import (
+	"context"
+	"errors"
+)
+
+// ชื่อช่องทาง (ตรงกับ notification_outbox.channel)
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelInApp = "in_app"
+)
+
+// ErrUndeliverable ส่งไม่ได้และลองใหม่ก็ไม่สำเร็จ (เช่น ผู้รับไม่มีอีเมล/เบอร์โทร) Dispatcher จะไม่ retry
+var ErrUndeliverable = errors.New("notification is undeliverable")
+
+// Recipient ผู้รับ
+type Recipient struct {
+	UserID int
+	Name   string
+	Email  string
+	Phone  string
+}
+
+// Message ข้อความที่ render แล้ว
+type Message struct {
+	Template string
+	Subject  string
+	Body     string
+}
+
+// Channel ช่องทางส่ง
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, to Recipient, msg Message) error
+}
@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// SMSConfig ผู้ให้บริการ SMS แบบ HTTP ทั่วไป (ใช้ stand-in ในเครื่องได้ เช่น HTTP server ที่ log request)
+type SMSConfig struct {
+	URL    string // endpoint ที่รับ POST JSON
+	APIKey string // ส่งเป็น Authorization: Bearer <APIKey> (ว่าง = ไม่ส่ง)
+	Sender string
+}
+
+// SMSChannel POST {"to": "...", "sender": "...", "message": "..."} ไปยัง URL ถือว่าสำเร็จเมื่อได้ 2xx
+type SMSChannel struct {
+	cfg    SMSConfig
+	client *http.Client
+}
+
+func NewSMSChannel(cfg SMSConfig) *SMSChannel {
+	return &SMSChannel{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (c *SMSChannel) Name() string { return ChannelSMS }
+
+func (c *SMSChannel) Send(ctx context.Context, to Recipient, msg Message) error {
+	if to.Phone == "" {
+		return fmt.Errorf("%w: recipient has no phone number", ErrUndeliverable)
+	}
+	payload, err := json.Marshal(map[string]string{
+		"to":      to.Phone,
+		"sender":  c.cfg.Sender,
+		"message": msg.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.APIKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		err := fmt.Errorf("sms provider returned %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+		// 4xx (ยกเว้น 429) คือ request ผิด ลองใหม่ก็ไม่ผ่าน
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return fmt.Errorf("%w: %v", ErrUndeliverable, err)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSMSChannelSend(t *testing.T) {
+	var got struct {
+		auth, contentType string
+		payload           map[string]string
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		got.auth = r.Header.Get("Authorization")
+		got.contentType = r.Header.Get("Content-Type")
+		if err := json.NewDecoder(r.Body).Decode(&got.payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	ch := NewSMSChannel(SMSConfig{URL: srv.URL, APIKey: "secret", Sender: "FITNESS"})
+	err := ch.Send(context.Background(), Recipient{Phone: "0812345678"}, Message{Subject: "ignored", Body: "นัดฝึกพรุ่งนี้ 08:00"})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got.auth != "Bearer secret" {
+		t.Errorf("Authorization = %q", got.auth)
+	}
+	if got.contentType != "application/json" {
+		t.Errorf("Content-Type = %q", got.contentType)
+	}
+	want := map[string]string{"to": "0812345678", "sender": "FITNESS", "message": "นัดฝึกพรุ่งนี้ 08:00"}
+	for k, v := range want {
+		if got.payload[k] != v {
+			t.Errorf("payload[%q] = %q, want %q", k, got.payload[k], v)
+		}
+	}
+}
+
+func TestSMSChannelNoAPIKey(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("Authorization = %q, want none", auth)
+		}
+	}))
+	defer srv.Close()
+
+	if err := NewSMSChannel(SMSConfig{URL: srv.URL}).Send(context.Background(), Recipient{Phone: "0812345678"}, Message{Body: "hi"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+}
+
+// 4xx (ยกเว้น 429) ส่งซ้ำไม่ได้ ส่วน 429/5xx ให้ Dispatcher ลองใหม่
+func TestSMSChannelStatusMapping(t *testing.T) {
+	tests := []struct {
+		status        int
+		wantErr       bool
+		undeliverable bool
+	}{
+		{http.StatusOK, false, false},
+		{http.StatusNoContent, false, false},
+		{http.StatusBadRequest, true, true},
+		{http.StatusUnauthorized, true, true},
+		{http.StatusUnprocessableEntity, true, true},
+		{http.StatusTooManyRequests, true, false},
+		{http.StatusInternalServerError, true, false},
+		{http.StatusServiceUnavailable, true, false},
+		{http.StatusMovedPermanently, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// 301 ต้องไม่มี Location ไม่เช่นนั้น http.Client จะตาม redirect
+				w.WriteHeader(tt.status)
+				w.Write([]byte(" provider says no \n"))
+			}))
+			defer srv.Close()
+
+			err := NewSMSChannel(SMSConfig{URL: srv.URL}).Send(context.Background(), Recipient{Phone: "0812345678"}, Message{Body: "hi"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrUndeliverable) != tt.undeliverable {
+				t.Fatalf("errors.Is(err, ErrUndeliverable) = %v, want %v (err = %v)", !tt.undeliverable, tt.undeliverable, err)
+			}
+		})
+	}
+}
+
+func TestSMSChannelErrors(t *testing.T) {
+	t.Run("no phone", func(t *testing.T) {
+		err := NewSMSChannel(SMSConfig{URL: "http://127.0.0.1:1"}).Send(context.Background(), Recipient{Email: "a@example.com"}, Message{})
+		if !errors.Is(err, ErrUndeliverable) {
+			t.Fatalf("err = %v, want ErrUndeliverable", err)
+		}
+	})
+
+	t.Run("provider unreachable is retryable", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		url := srv.URL
+		srv.Close()
+
+		err := NewSMSChannel(SMSConfig{URL: url}).Send(context.Background(), Recipient{Phone: "0812345678"}, Message{})
+		if err == nil || errors.Is(err, ErrUndeliverable) {
+			t.Fatalf("err = %v, want a retryable error", err)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPConfig ใช้กับ SMTP server จริง หรือ stand-in ในเครื่อง (เช่น MailHog ที่ localhost:1025 ไม่ต้องมี Username)
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string // เช่น "Fitness <no-reply@example.com>"
+}
+
+// SMTPChannel ส่งอีเมล text/plain (UTF-8) ผ่าน net/smtp (ใช้ STARTTLS อัตโนมัติถ้า server รองรับ)
+type SMTPChannel struct {
+	cfg  SMTPConfig
+	from *mail.Address
+}
+
+func NewSMTPChannel(cfg SMTPConfig) (*SMTPChannel, error) {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP from address %q: %w", cfg.From, err)
+	}
+	return &SMTPChannel{cfg: cfg, from: from}, nil
+}
+
+func (c *SMTPChannel) Name() string { return ChannelEmail }
+
+func (c *SMTPChannel) Send(ctx context.Context, to Recipient, msg Message) error {
+	if to.Email == "" {
+		return fmt.Errorf("%w: recipient has no email", ErrUndeliverable)
+	}
+	rcpt := &mail.Address{Name: to.Name, Address: to.Email}
+
+	var auth smtp.Auth
+	if c.cfg.Username != "" {
+		auth = smtp.PlainAuth("", c.cfg.Username, c.cfg.Password, c.cfg.Host)
+	}
+	addr := net.JoinHostPort(c.cfg.Host, strconv.Itoa(c.cfg.Port))
+
+	// net/smtp ไม่รับ context: ส่งใน goroutine แล้วเลิกรอเมื่อ context ถูกยกเลิก
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, c.from.Address, []string{rcpt.Address}, c.buildMessage(rcpt, msg))
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// buildMessage สร้างอีเมลตาม RFC 5322 (Subject แบบ encoded-word, เนื้อหา base64 เพื่อรองรับภาษาไทย)
+func (c *SMTPChannel) buildMessage(to *mail.Address, msg Message) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", c.from.String())
+	fmt.Fprintf(&b, "To: %s\r\n", to.String())
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+	return b.Bytes()
+}
@@ -0,0 +1,194 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpStub SMTP server ในเครื่องแบบย่อ (ไม่มี STARTTLS/AUTH) เก็บ envelope และข้อมูลของอีเมลที่ได้รับ
+type smtpStub struct {
+	ln       net.Listener
+	rcptCode string // ตอบ RCPT TO (ค่าเริ่มต้น 250)
+
+	from string
+	rcpt []string
+	data chan string
+}
+
+func newSMTPStub(t *testing.T) *smtpStub {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &smtpStub{ln: ln, rcptCode: "250", data: make(chan string, 1)}
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *smtpStub) config() SMTPConfig {
+	addr := s.ln.Addr().(*net.TCPAddr)
+	return SMTPConfig{Host: "127.0.0.1", Port: addr.Port, From: "Fitness <no-reply@example.com>"}
+}
+
+// serveOne รับการเชื่อมต่อเดียว
+func (s *smtpStub) serveOne() {
+	conn, err := s.ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 stub ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		switch verb := strings.ToUpper(strings.SplitN(cmd, " ", 2)[0]); {
+		case verb == "EHLO" || verb == "HELO":
+			reply("250 stub")
+		case strings.HasPrefix(strings.ToUpper(cmd), "MAIL FROM:"):
+			s.from = cmd[len("MAIL FROM:"):]
+			reply("250 OK")
+		case strings.HasPrefix(strings.ToUpper(cmd), "RCPT TO:"):
+			s.rcpt = append(s.rcpt, cmd[len("RCPT TO:"):])
+			reply(s.rcptCode + " rcpt")
+		case verb == "DATA":
+			reply("354 go ahead")
+			var b strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				b.WriteString(strings.TrimPrefix(l, "."))
+			}
+			s.data <- b.String()
+			reply("250 queued")
+		case verb == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestSMTPChannelSend(t *testing.T) {
+	stub := newSMTPStub(t)
+	go stub.serveOne()
+
+	ch, err := NewSMTPChannel(stub.config())
+	if err != nil {
+		t.Fatalf("NewSMTPChannel: %v", err)
+	}
+	msg := Message{Template: TemplateScheduleReminder, Subject: "เตือนนัดฝึก: Leg day", Body: "สวัสดีคุณ สมชาย\nคุณมีนัดฝึก " + strings.Repeat("ยาว", 40)}
+	if err := ch.Send(context.Background(), Recipient{UserID: 1, Name: "สมชาย", Email: "somchai@example.com"}, msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	var raw string
+	select {
+	case raw = <-stub.data:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stub did not receive DATA")
+	}
+	if stub.from != "<no-reply@example.com>" {
+		t.Errorf("MAIL FROM = %q", stub.from)
+	}
+	if len(stub.rcpt) != 1 || stub.rcpt[0] != "<somchai@example.com>" {
+		t.Errorf("RCPT TO = %q", stub.rcpt)
+	}
+
+	m, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject"))
+	if err != nil || subject != msg.Subject {
+		t.Errorf("Subject = %q (%v), want %q", subject, err, msg.Subject)
+	}
+	to, err := mail.ParseAddress(m.Header.Get("To"))
+	if err != nil || to.Name != "สมชาย" || to.Address != "somchai@example.com" {
+		t.Errorf("To = %q (%v)", m.Header.Get("To"), err)
+	}
+	if ct := m.Header.Get("Content-Type"); ct != "text/plain; charset=UTF-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	encoded, _ := io.ReadAll(m.Body)
+	for _, line := range strings.Split(strings.TrimRight(string(encoded), "\r\n"), "\r\n") {
+		if len(line) > 76 {
+			t.Errorf("body line longer than 76 characters: %d", len(line))
+		}
+	}
+	body, err := base64.StdEncoding.DecodeString(strings.NewReplacer("\r", "", "\n", "").Replace(string(encoded)))
+	if err != nil || string(body) != msg.Body {
+		t.Errorf("body = %q (%v), want %q", body, err, msg.Body)
+	}
+}
+
+func TestSMTPChannelSendErrors(t *testing.T) {
+	t.Run("no email", func(t *testing.T) {
+		ch, _ := NewSMTPChannel(SMTPConfig{Host: "127.0.0.1", Port: 1, From: "no-reply@example.com"})
+		err := ch.Send(context.Background(), Recipient{UserID: 1}, Message{})
+		if !errors.Is(err, ErrUndeliverable) {
+			t.Fatalf("err = %v, want ErrUndeliverable", err)
+		}
+	})
+
+	t.Run("rejected recipient is retryable", func(t *testing.T) {
+		stub := newSMTPStub(t)
+		stub.rcptCode = "450"
+		go stub.serveOne()
+
+		ch, _ := NewSMTPChannel(stub.config())
+		err := ch.Send(context.Background(), Recipient{Email: "a@example.com"}, Message{Subject: "s", Body: "b"})
+		if err == nil || errors.Is(err, ErrUndeliverable) {
+			t.Fatalf("err = %v, want a retryable error", err)
+		}
+	})
+
+	t.Run("context deadline", func(t *testing.T) {
+		// server รับการเชื่อมต่อแต่ไม่ตอบ
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen: %v", err)
+		}
+		defer ln.Close()
+		go func() {
+			if conn, err := ln.Accept(); err == nil {
+				defer conn.Close()
+				io.Copy(io.Discard, conn)
+			}
+		}()
+
+		ch, _ := NewSMTPChannel(SMTPConfig{Host: "127.0.0.1", Port: ln.Addr().(*net.TCPAddr).Port, From: "no-reply@example.com"})
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if err := ch.Send(ctx, Recipient{Email: "a@example.com"}, Message{}); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+		}
+	})
+
+	t.Run("invalid from", func(t *testing.T) {
+		if _, err := NewSMTPChannel(SMTPConfig{From: "not an address"}); err == nil {
+			t.Fatal("expected error for invalid from address")
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+)
+
+// ภาษาที่รองรับ
+const (
+	LocaleTH = "th"
+	LocaleEN = "en"
+)
+
+// ชื่อ Template (ตรงกับ notification_outbox.template)
+const (
+	TemplateScheduleReminder    = "schedule_reminder"
+	TemplateAssignmentDue       = "assignment_due"
+	TemplateAssignmentSubmitted = "assignment_submitted"
+	TemplateAssignmentReviewed  = "assignment_reviewed"
+	TemplateVerifyEmail         = "verify_email"
+	TemplateResetPassword       = "reset_password"
+)
+
+type messageTemplate struct {
+	Subject string
+	Body    string
+}
+
+// templates[ชื่อ][ภาษา] ข้อมูลใน Template มาจาก payload ของ outbox + .name (ชื่อผู้รับ)
+var templates = map[string]map[string]messageTemplate{
+	TemplateScheduleReminder: {
+		LocaleTH: {
+			Subject: "เตือนนัดฝึก: {{.title}}",
+			Body:    "สวัสดีคุณ {{.name}}\nคุณมีนัดฝึก \"{{.title}}\" กับ {{.trainer_name}} วันที่ {{datetime .start_time}}",
+		},
+		LocaleEN: {
+			Subject: "Upcoming session: {{.title}}",
+			Body:    "Hi {{.name}},\nYou have a training session \"{{.title}}\" with {{.trainer_name}} on {{datetime .start_time}}.",
+		},
+	},
+	TemplateAssignmentDue: {
+		LocaleTH: {
+			Subject: "งานใกล้ครบกำหนด: {{.title}}",
+			Body:    "สวัสดีคุณ {{.name}}\nงาน \"{{.title}}\" จะครบกำหนดส่งวันที่ {{datetime .due_date}}",
+		},
+		LocaleEN: {
+			Subject: "Assignment due soon: {{.title}}",
+			Body:    "Hi {{.name}},\nYour assignment \"{{.title}}\" is due on {{datetime .due_date}}.",
+		},
+	},
+	TemplateAssignmentSubmitted: {
+		LocaleTH: {
+			Subject: "{{.client_name}} ส่งงานแล้ว: {{.title}}",
+			Body:    "สวัสดีคุณ {{.name}}\n{{.client_name}} ส่งงาน \"{{.title}}\" แล้ว รอการตรวจ",
+		},
+		LocaleEN: {
+			Subject: "{{.client_name}} submitted: {{.title}}",
+			Body:    "Hi {{.name}},\n{{.client_name}} has submitted \"{{.title}}\" and it is waiting for your review.",
+		},
+	},
+	TemplateAssignmentReviewed: {
+		LocaleTH: {
+			Subject: "{{if .revision_requested}}งานถูกส่งกลับให้แก้ไข{{else}}งานได้รับการตรวจแล้ว{{end}}: {{.title}}",
+			Body:    "สวัสดีคุณ {{.name}}\nเทรนเนอร์ตรวจงาน \"{{.title}}\" แล้ว{{if .feedback}}\nความเห็น: {{.feedback}}{{end}}",
+		},
+		LocaleEN: {
+			Subject: "{{if .revision_requested}}Revision requested{{else}}Assignment reviewed{{end}}: {{.title}}",
+			Body:    "Hi {{.name}},\nYour trainer has reviewed \"{{.title}}\".{{if .feedback}}\nFeedback: {{.feedback}}{{end}}",
+		},
+	},
+	TemplateVerifyEmail: {
+		LocaleTH: {
+			Subject: "ยืนยันอีเมลของคุณ",
+			Body:    "สวัสดีคุณ {{.name}}\nกรุณายืนยันอีเมลโดยเปิดลิงก์ด้านล่าง (ใช้ได้ภายใน {{.expires_hours}} ชั่วโมง)\n{{.link}}",
+		},
+		LocaleEN: {
+			Subject: "Verify your email address",
+			Body:    "Hi {{.name}},\nPlease verify your email address by opening the link below (valid for {{.expires_hours}} hours).\n{{.link}}",
+		},
+	},
+	TemplateResetPassword: {
+		LocaleTH: {
+			Subject: "รีเซ็ตรหัสผ่าน",
+			Body:    "สวัสดีคุณ {{.name}}\nเปิดลิงก์ด้านล่างเพื่อตั้งรหัสผ่านใหม่ (ใช้ได้ภายใน {{.expires_hours}} ชั่วโมง)\n{{.link}}\nหากคุณไม่ได้ขอรีเซ็ตรหัสผ่าน ไม่ต้องทำอะไร",
+		},
+		LocaleEN: {
+			Subject: "Reset your password",
+			Body:    "Hi {{.name}},\nOpen the link below to choose a new password (valid for {{.expires_hours}} hours).\n{{.link}}\nIf you did not request a password reset, you can ignore this email.",
+		},
+	},
+}
+
+// Renderer แปลง Template + ข้อมูลเป็นข้อความตามภาษาของผู้รับ (เวลาแสดงตาม Time Zone ที่กำหนด)
+type Renderer struct {
+	defaultLocale string
+	parsed        map[string]map[string][2]*template.Template
+}
+
+func NewRenderer(defaultLocale string, loc *time.Location) (*Renderer, error) {
+	if _, ok := templates[TemplateScheduleReminder][defaultLocale]; !ok {
+		return nil, fmt.Errorf("unsupported locale: %s", defaultLocale)
+	}
+	funcs := template.FuncMap{
+		// datetime แปลงเวลา RFC3339 ใน payload เป็นเวลาท้องถิ่น
+		"datetime": func(v any) string {
+			s, _ := v.(string)
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				return s
+			}
+			return t.In(loc).Format("02/01/2006 15:04")
+		},
+	}
+
+	r := &Renderer{defaultLocale: defaultLocale, parsed: map[string]map[string][2]*template.Template{}}
+	for name, locales := range templates {
+		r.parsed[name] = map[string][2]*template.Template{}
+		for locale, mt := range locales {
+			subject, err := template.New(name + ".subject").Funcs(funcs).Parse(mt.Subject)
+			if err != nil {
+				return nil, err
+			}
+			body, err := template.New(name + ".body").Funcs(funcs).Parse(mt.Body)
+			if err != nil {
+				return nil, err
+			}
+			r.parsed[name][locale] = [2]*template.Template{subject, body}
+		}
+	}
+	return r, nil
+}
+
+// Render ภาษาที่ไม่รองรับจะใช้ภาษาเริ่มต้น; Template ที่ไม่รู้จักคืน ErrUndeliverable
+func (r *Renderer) Render(name, locale string, data map[string]any) (Message, error) {
+	locales, ok := r.parsed[name]
+	if !ok {
+		return Message{}, fmt.Errorf("%w: unknown template %q", ErrUndeliverable, name)
+	}
+	t, ok := locales[locale]
+	if !ok {
+		t = locales[r.defaultLocale]
+	}
+
+	var subject, body bytes.Buffer
+	if err := t[0].Execute(&subject, data); err != nil {
+		return Message{}, fmt.Errorf("%w: %v", ErrUndeliverable, err)
+	}
+	if err := t[1].Execute(&body, data); err != nil {
+		return Message{}, fmt.Errorf("%w: %v", ErrUndeliverable, err)
+	}
+	return Message{Template: name, Subject: subject.String(), Body: body.String()}, nil
+}
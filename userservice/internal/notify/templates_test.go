@@ -0,0 +1,172 @@
+package notify
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestRenderer(t *testing.T, locale string) *Renderer {
+	t.Helper()
+	loc, err := time.LoadLocation("Asia/Bangkok")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	r, err := NewRenderer(locale, loc)
+	if err != nil {
+		t.Fatalf("NewRenderer: %v", err)
+	}
+	return r
+}
+
+func TestTemplatesHaveAllLocales(t *testing.T) {
+	for name, locales := range templates {
+		for _, locale := range []string{LocaleTH, LocaleEN} {
+			mt, ok := locales[locale]
+			if !ok {
+				t.Errorf("template %s has no %s version", name, locale)
+				continue
+			}
+			if mt.Subject == "" || mt.Body == "" {
+				t.Errorf("template %s/%s has an empty subject or body", name, locale)
+			}
+		}
+	}
+}
+
+func TestRendererRender(t *testing.T) {
+	r := newTestRenderer(t, LocaleTH)
+
+	tests := []struct {
+		name        string
+		template    string
+		locale      string
+		data        map[string]any
+		wantSubject string
+		wantBody    []string
+	}{
+		{
+			name:        "schedule reminder th",
+			template:    TemplateScheduleReminder,
+			locale:      LocaleTH,
+			data:        map[string]any{"name": "สมชาย", "title": "Leg day", "trainer_name": "โค้ชเอ", "start_time": "2024-03-04T01:30:00Z"},
+			wantSubject: "เตือนนัดฝึก: Leg day",
+			// 01:30 UTC = 08:30 ที่กรุงเทพฯ
+			wantBody: []string{"สวัสดีคุณ สมชาย", "\"Leg day\" กับ โค้ชเอ", "04/03/2024 08:30"},
+		},
+		{
+			name:        "schedule reminder en",
+			template:    TemplateScheduleReminder,
+			locale:      LocaleEN,
+			data:        map[string]any{"name": "Somchai", "title": "Leg day", "trainer_name": "Coach A", "start_time": "2024-03-03T18:00:00Z"},
+			wantSubject: "Upcoming session: Leg day",
+			// ข้ามวันตามเวลาท้องถิ่น
+			wantBody: []string{"Hi Somchai,", "with Coach A on 04/03/2024 01:00."},
+		},
+		{
+			name:        "assignment due th",
+			template:    TemplateAssignmentDue,
+			locale:      LocaleTH,
+			data:        map[string]any{"name": "สมชาย", "title": "บันทึกอาหาร", "due_date": "2024-03-10T10:00:00Z"},
+			wantSubject: "งานใกล้ครบกำหนด: บันทึกอาหาร",
+			wantBody:    []string{"10/03/2024 17:00"},
+		},
+		{
+			name:        "assignment submitted en",
+			template:    TemplateAssignmentSubmitted,
+			locale:      LocaleEN,
+			data:        map[string]any{"name": "Coach A", "title": "Food log", "client_name": "Somchai"},
+			wantSubject: "Somchai submitted: Food log",
+			wantBody:    []string{"Somchai has submitted \"Food log\""},
+		},
+		{
+			name:        "assignment reviewed th revision",
+			template:    TemplateAssignmentReviewed,
+			locale:      LocaleTH,
+			data:        map[string]any{"name": "สมชาย", "title": "บันทึกอาหาร", "revision_requested": true, "feedback": "เพิ่มมื้อเย็น"},
+			wantSubject: "งานถูกส่งกลับให้แก้ไข: บันทึกอาหาร",
+			wantBody:    []string{"ความเห็น: เพิ่มมื้อเย็น"},
+		},
+		{
+			name:        "assignment reviewed en without feedback",
+			template:    TemplateAssignmentReviewed,
+			locale:      LocaleEN,
+			data:        map[string]any{"name": "Somchai", "title": "Food log", "revision_requested": false},
+			wantSubject: "Assignment reviewed: Food log",
+			wantBody:    []string{"Your trainer has reviewed \"Food log\"."},
+		},
+		{
+			name:        "verify email th",
+			template:    TemplateVerifyEmail,
+			locale:      LocaleTH,
+			data:        map[string]any{"name": "สมชาย", "expires_hours": 24, "link": "https://app.example.com/verify?token=abc"},
+			wantSubject: "ยืนยันอีเมลของคุณ",
+			wantBody:    []string{"ภายใน 24 ชั่วโมง", "https://app.example.com/verify?token=abc"},
+		},
+		{
+			name:        "reset password en",
+			template:    TemplateResetPassword,
+			locale:      LocaleEN,
+			data:        map[string]any{"name": "Somchai", "expires_hours": 1, "link": "https://app.example.com/reset?token=abc"},
+			wantSubject: "Reset your password",
+			wantBody:    []string{"valid for 1 hours", "https://app.example.com/reset?token=abc"},
+		},
+		{
+			name:        "unsupported locale falls back to default",
+			template:    TemplateVerifyEmail,
+			locale:      "jp",
+			data:        map[string]any{"name": "สมชาย", "expires_hours": 24, "link": "x"},
+			wantSubject: "ยืนยันอีเมลของคุณ",
+		},
+		{
+			name:        "unparseable time is shown as is",
+			template:    TemplateAssignmentDue,
+			locale:      LocaleEN,
+			data:        map[string]any{"name": "Somchai", "title": "Food log", "due_date": "tomorrow"},
+			wantSubject: "Assignment due soon: Food log",
+			wantBody:    []string{"is due on tomorrow."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := r.Render(tt.template, tt.locale, tt.data)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if msg.Template != tt.template {
+				t.Errorf("Template = %q, want %q", msg.Template, tt.template)
+			}
+			if msg.Subject != tt.wantSubject {
+				t.Errorf("Subject = %q, want %q", msg.Subject, tt.wantSubject)
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(msg.Body, want) {
+					t.Errorf("Body = %q, want it to contain %q", msg.Body, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRendererDefaultLocaleEN(t *testing.T) {
+	r := newTestRenderer(t, LocaleEN)
+	msg, err := r.Render(TemplateVerifyEmail, "", map[string]any{"name": "Somchai", "expires_hours": 24, "link": "x"})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if msg.Subject != "Verify your email address" {
+		t.Errorf("Subject = %q", msg.Subject)
+	}
+}
+
+func TestRendererErrors(t *testing.T) {
+	if _, err := NewRenderer("jp", time.UTC); err == nil {
+		t.Error("NewRenderer with unsupported locale: expected error")
+	}
+
+	r := newTestRenderer(t, LocaleTH)
+	if _, err := r.Render("no_such_template", LocaleTH, nil); !errors.Is(err, ErrUndeliverable) {
+		t.Errorf("unknown template: err = %v, want ErrUndeliverable", err)
+	}
+}
@@ -0,0 +1,197 @@
+// Package oidc Login ผ่านผู้ให้บริการ OpenID Connect ใดๆ (Google, Keycloak, Auth0, mock issuer ในเครื่อง ฯลฯ)
+// ค้นหา endpoint จาก Discovery (/.well-known/openid-configuration) และตรวจลายเซ็น ID Token ด้วย JWKS ของ issuer
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+// ErrInvalidIDToken ID Token ไม่ผ่านการตรวจ (ลายเซ็น, iss, aud, exp, nonce)
+var ErrInvalidIDToken = errors.New("invalid id token")
+
+// GoogleIssuer issuer ของ Google
+const GoogleIssuer = "https://accounts.google.com"
+
+// ยอมให้นาฬิกาของ issuer กับ server ต่างกันได้
+const clockSkew = time.Minute
+
+// Config ค่าของผู้ให้บริการแต่ละราย (Name ใช้ใน URL /auth/oidc/:provider และเก็บใน user_identities.provider)
+type Config struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	// ว่าง = openid, email, profile
+	Scopes []string
+}
+
+// Identity ข้อมูลผู้ใช้จาก ID Token ที่ตรวจแล้ว
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// metadata ส่วนที่ใช้จาก Discovery document
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider ค้นหา endpoint ครั้งแรกที่ใช้งาน (ไม่ทำให้ server start ไม่ได้ถ้า issuer ล่ม) แล้วเก็บไว้
+type Provider struct {
+	cfg    Config
+	client *http.Client
+
+	mu     sync.Mutex
+	meta   *metadata
+	oauth2 *oauth2.Config
+	keys   *keySet
+}
+
+func NewProvider(cfg Config) *Provider {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	return &Provider{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+// AuthCodeURL URL หน้า Login ของผู้ให้บริการ พร้อม state, nonce และ PKCE (S256) จาก verifier
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	conf, _, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return conf.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier), oauth2.SetAuthURLParam("nonce", nonce)), nil
+}
+
+// Exchange แลก code เป็น token แล้วตรวจ ID Token (ต้องมี nonce ตรงกับที่ส่งไปตอนเริ่ม Login)
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Identity, error) {
+	conf, keys, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.client)
+	token, err := conf.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("oidc %s: exchange code: %w", p.cfg.Name, err)
+	}
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return nil, fmt.Errorf("%w: token response has no id_token", ErrInvalidIDToken)
+	}
+	return p.verify(ctx, keys, rawIDToken, nonce)
+}
+
+// idTokenClaims email_verified บางรายส่งเป็น string ("true")
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce         string `json:"nonce"`
+	Email         string `json:"email"`
+	EmailVerified any    `json:"email_verified"`
+	Name          string `json:"name"`
+}
+
+func (p *Provider) verify(ctx context.Context, keys *keySet, raw, nonce string) (*Identity, error) {
+	var claims idTokenClaims
+	_, err := jwt.ParseWithClaims(raw, &claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return keys.get(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(p.cfg.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if nonce == "" || claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing sub", ErrInvalidIDToken)
+	}
+
+	verified := false
+	switch v := claims.EmailVerified.(type) {
+	case bool:
+		verified = v
+	case string:
+		verified = v == "true"
+	}
+	return &Identity{
+		Provider:      p.cfg.Name,
+		Subject:       claims.Subject,
+		Email:         strings.TrimSpace(claims.Email),
+		EmailVerified: verified,
+		Name:          strings.TrimSpace(claims.Name),
+	}, nil
+}
+
+// discover โหลด Discovery document (ครั้งแรก หรือหลังครั้งก่อนล้มเหลว)
+func (p *Provider) discover(ctx context.Context) (*oauth2.Config, *keySet, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.meta != nil {
+		return p.oauth2, p.keys, nil
+	}
+
+	wellKnown := strings.TrimSuffix(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, wellKnown, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("oidc %s: discovery: %w", p.cfg.Name, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("oidc %s: discovery: unexpected status %d", p.cfg.Name, res.StatusCode)
+	}
+
+	var meta metadata
+	if err := json.NewDecoder(res.Body).Decode(&meta); err != nil {
+		return nil, nil, fmt.Errorf("oidc %s: discovery: %w", p.cfg.Name, err)
+	}
+	// issuer ต้องตรงกับที่ตั้งค่าไว้ทุกตัวอักษร (OIDC Discovery 4.3) ไม่อย่างนั้นตรวจ iss ใน ID Token ไม่ผ่านอยู่ดี
+	if meta.Issuer != p.cfg.Issuer {
+		return nil, nil, fmt.Errorf("oidc %s: discovery: issuer %q does not match configured %q", p.cfg.Name, meta.Issuer, p.cfg.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, nil, fmt.Errorf("oidc %s: discovery: missing required endpoints", p.cfg.Name)
+	}
+
+	p.meta = &meta
+	p.oauth2 = &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.cfg.RedirectURL,
+		Scopes:       p.cfg.Scopes,
+		Endpoint:     oauth2.Endpoint{AuthURL: meta.AuthorizationEndpoint, TokenURL: meta.TokenEndpoint},
+	}
+	p.keys = newKeySet(meta.JWKSURI, p.client)
+	return p.oauth2, p.keys, nil
+}
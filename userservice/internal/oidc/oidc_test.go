@@ -0,0 +1,371 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID = "fitness-app"
+	testCode     = "auth-code-123"
+	testKid      = "key-1"
+)
+
+// testIssuer ผู้ให้บริการ OIDC จำลอง: Discovery, JWKS และ Token endpoint
+// claims ของ ID Token ที่ Token endpoint คืน กำหนดได้ต่อการทดสอบผ่าน idClaims
+type testIssuer struct {
+	srv *httptest.Server
+	key *rsa.PrivateKey
+
+	mu           sync.Mutex
+	idClaims     jwt.MapClaims
+	signWith     *rsa.PrivateKey // nil = key ของ issuer (อื่น = ลายเซ็นปลอม)
+	gotVerifier  string
+	gotCode      string
+	discoveryIss string // "" = URL ของ server
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	is := &testIssuer{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		is.mu.Lock()
+		iss := is.discoveryIss
+		is.mu.Unlock()
+		if iss == "" {
+			iss = is.srv.URL
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 iss,
+			"authorization_endpoint": is.srv.URL + "/authorize",
+			"token_endpoint":         is.srv.URL + "/token",
+			"jwks_uri":               is.srv.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		pub := is.key.PublicKey
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kid": testKid,
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		code := r.Form.Get("code")
+		is.mu.Lock()
+		is.gotCode = code
+		is.gotVerifier = r.Form.Get("code_verifier")
+		claims, signer := is.idClaims, is.signWith
+		is.mu.Unlock()
+
+		if code != testCode {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		if signer == nil {
+			signer = is.key
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = testKid
+		idToken, err := token.SignedString(signer)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	})
+	is.srv = httptest.NewServer(mux)
+	t.Cleanup(is.srv.Close)
+	return is
+}
+
+func (is *testIssuer) provider() *Provider {
+	return NewProvider(Config{
+		Name:         "mock",
+		Issuer:       is.srv.URL,
+		ClientID:     testClientID,
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8080/auth/oidc/mock/callback",
+	})
+}
+
+// validClaims ID Token ที่ถูกต้องสำหรับ nonce ที่ระบุ
+func (is *testIssuer) validClaims(nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            is.srv.URL,
+		"aud":            testClientID,
+		"sub":            "user-42",
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"nonce":          nonce,
+		"email":          " somchai@example.com ",
+		"email_verified": true,
+		"name":           "Somchai",
+	}
+}
+
+func (is *testIssuer) setClaims(claims jwt.MapClaims) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+	is.idClaims = claims
+}
+
+func TestExchangeValidLogin(t *testing.T) {
+	is := newTestIssuer(t)
+	is.setClaims(is.validClaims("nonce-1"))
+
+	identity, err := is.provider().Exchange(context.Background(), testCode, "verifier-1", "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	want := Identity{Provider: "mock", Subject: "user-42", Email: "somchai@example.com", EmailVerified: true, Name: "Somchai"}
+	if *identity != want {
+		t.Errorf("identity = %+v, want %+v", *identity, want)
+	}
+}
+
+func TestExchangeEmailVerifiedClaim(t *testing.T) {
+	tests := []struct {
+		value any
+		want  bool
+	}{
+		{true, true},
+		{"true", true},
+		{false, false},
+		{"false", false},
+		{nil, false},
+	}
+	is := newTestIssuer(t)
+	p := is.provider()
+	for _, tt := range tests {
+		claims := is.validClaims("n")
+		if tt.value == nil {
+			delete(claims, "email_verified")
+		} else {
+			claims["email_verified"] = tt.value
+		}
+		is.setClaims(claims)
+
+		identity, err := p.Exchange(context.Background(), testCode, "v", "n")
+		if err != nil {
+			t.Fatalf("email_verified=%v: Exchange: %v", tt.value, err)
+		}
+		if identity.EmailVerified != tt.want {
+			t.Errorf("email_verified=%v: EmailVerified = %v, want %v", tt.value, identity.EmailVerified, tt.want)
+		}
+	}
+}
+
+func TestExchangeRejectsInvalidIDToken(t *testing.T) {
+	is := newTestIssuer(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(jwt.MapClaims)
+		forged bool
+		nonce  string
+	}{
+		{name: "wrong issuer", mutate: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }},
+		{name: "wrong audience", mutate: func(c jwt.MapClaims) { c["aud"] = "another-app" }},
+		{name: "nonce mismatch", mutate: func(c jwt.MapClaims) { c["nonce"] = "attacker-nonce" }},
+		{name: "missing nonce", mutate: func(c jwt.MapClaims) { delete(c, "nonce") }},
+		{name: "empty expected nonce", nonce: "-"},
+		{name: "expired", mutate: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{name: "missing exp", mutate: func(c jwt.MapClaims) { delete(c, "exp") }},
+		{name: "missing sub", mutate: func(c jwt.MapClaims) { delete(c, "sub") }},
+		{name: "forged signature", forged: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := is.validClaims("nonce-1")
+			if tt.mutate != nil {
+				tt.mutate(claims)
+			}
+			is.setClaims(claims)
+			is.mu.Lock()
+			is.signWith = nil
+			if tt.forged {
+				is.signWith = otherKey
+			}
+			is.mu.Unlock()
+
+			nonce := "nonce-1"
+			if tt.nonce == "-" {
+				nonce = ""
+			}
+			_, err := is.provider().Exchange(context.Background(), testCode, "v", nonce)
+			if !errors.Is(err, ErrInvalidIDToken) {
+				t.Fatalf("err = %v, want ErrInvalidIDToken", err)
+			}
+		})
+	}
+}
+
+func TestExchangeRejectedCode(t *testing.T) {
+	is := newTestIssuer(t)
+	is.setClaims(is.validClaims("n"))
+
+	_, err := is.provider().Exchange(context.Background(), "wrong-code", "v", "n")
+	if err == nil || errors.Is(err, ErrInvalidIDToken) {
+		t.Fatalf("err = %v, want an exchange error", err)
+	}
+}
+
+func TestPKCEVerifierPassThrough(t *testing.T) {
+	is := newTestIssuer(t)
+	is.setClaims(is.validClaims("nonce-1"))
+	p := is.provider()
+
+	state, err := NewLoginState("mock", time.Minute)
+	if err != nil {
+		t.Fatalf("NewLoginState: %v", err)
+	}
+	authURL, err := p.AuthCodeURL(context.Background(), state.State, state.Nonce, state.Verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parse auth url: %v", err)
+	}
+	q := u.Query()
+	if !strings.HasPrefix(authURL, is.srv.URL+"/authorize?") {
+		t.Errorf("auth url = %s", authURL)
+	}
+	sum := sha256.Sum256([]byte(state.Verifier))
+	wantParams := map[string]string{
+		"client_id":             testClientID,
+		"response_type":         "code",
+		"state":                 state.State,
+		"nonce":                 state.Nonce,
+		"code_challenge":        base64.RawURLEncoding.EncodeToString(sum[:]),
+		"code_challenge_method": "S256",
+		"scope":                 "openid email profile",
+	}
+	for k, v := range wantParams {
+		if q.Get(k) != v {
+			t.Errorf("%s = %q, want %q", k, q.Get(k), v)
+		}
+	}
+	if q.Get("code_verifier") != "" {
+		t.Error("code_verifier must not be sent to the authorization endpoint")
+	}
+
+	is.setClaims(is.validClaims(state.Nonce))
+	if _, err := p.Exchange(context.Background(), testCode, state.Verifier, state.Nonce); err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	is.mu.Lock()
+	defer is.mu.Unlock()
+	if is.gotVerifier != state.Verifier {
+		t.Errorf("token endpoint got code_verifier %q, want %q", is.gotVerifier, state.Verifier)
+	}
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	is := newTestIssuer(t)
+	is.mu.Lock()
+	is.discoveryIss = "https://evil.example.com"
+	is.mu.Unlock()
+
+	if _, err := is.provider().AuthCodeURL(context.Background(), "s", "n", "v"); err == nil {
+		t.Fatal("expected discovery error for issuer mismatch")
+	}
+}
+
+func TestLoginState(t *testing.T) {
+	key := []byte("test-key")
+	state, err := NewLoginState("mock", time.Minute)
+	if err != nil {
+		t.Fatalf("NewLoginState: %v", err)
+	}
+	sealed, err := state.Seal(key)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	got, err := OpenLoginState(key, sealed, "mock", state.State)
+	if err != nil {
+		t.Fatalf("OpenLoginState: %v", err)
+	}
+	if *got != *state {
+		t.Errorf("opened state = %+v, want %+v", *got, *state)
+	}
+
+	expired := *state
+	expired.Expires = time.Now().Add(-time.Second).Unix()
+	sealedExpired, _ := expired.Seal(key)
+
+	encoded, _, _ := strings.Cut(sealed, ".")
+	tampered := *state
+	tampered.Verifier = "attacker"
+	payload, _ := json.Marshal(tampered)
+	_, mac, _ := strings.Cut(sealed, ".")
+
+	tests := []struct {
+		name, value, provider, state string
+	}{
+		{"empty cookie", "", "mock", state.State},
+		{"no signature", encoded, "mock", state.State},
+		{"signed with another key", mustSeal(t, state, []byte("other-key")), "mock", state.State},
+		{"tampered payload", base64.RawURLEncoding.EncodeToString(payload) + "." + mac, "mock", state.State},
+		{"other provider", sealed, "google", state.State},
+		{"state mismatch", sealed, "mock", "other-state"},
+		{"empty state", sealed, "mock", ""},
+		{"expired", sealedExpired, "mock", state.State},
+	}
+	for _, tt := range tests {
+		if _, err := OpenLoginState(key, tt.value, tt.provider, tt.state); !errors.Is(err, ErrInvalidState) {
+			t.Errorf("%s: err = %v, want ErrInvalidState", tt.name, err)
+		}
+	}
+}
+
+func mustSeal(t *testing.T, s *LoginState, key []byte) string {
+	t.Helper()
+	sealed, err := s.Seal(key)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	return sealed
+}
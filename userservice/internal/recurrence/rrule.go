@@ -0,0 +1,298 @@
+// Package recurrence แปลงและขยาย Recurrence Rule ตามรูปแบบ RFC 5545 (RRULE)
+// รองรับเฉพาะส่วนที่ใช้กับตารางฝึก: FREQ=DAILY|WEEKLY|MONTHLY, INTERVAL, BYDAY, BYMONTHDAY, COUNT, UNTIL
+// และต้องมี COUNT หรือ UNTIL เสมอ (ไม่รองรับ series ที่ไม่มีวันจบ)
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxOccurrences จำนวนครั้งสูงสุดที่ขยายได้ต่อ 1 series (กันการสร้างแถวจำนวนมากเกินไป)
+const MaxOccurrences = 500
+
+// maxPeriods จำนวนรอบ (วัน/สัปดาห์/เดือน) สูงสุดที่วนหา กันลูปไม่จบเมื่อ Rule ไม่มีวันที่ตรงเลย
+// เช่น FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=31 ที่เริ่มเดือน ก.พ.
+const maxPeriods = 10000
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// Rule RRULE ที่แปลงแล้ว
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+	Count      int        // 0 = ไม่ได้กำหนด
+	Until      *time.Time // nil = ไม่ได้กำหนด (นับรวมเวลา Until ด้วย)
+}
+
+// Parse แปลงข้อความ เช่น "FREQ=WEEKLY;BYDAY=MO,WE,FR;UNTIL=20241231T235959Z" (มี "RRULE:" นำหน้าได้)
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return nil, fmt.Errorf("%w: empty rule", ErrInvalidRule)
+	}
+
+	r := &Rule{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRule, part)
+		}
+		key, value := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+		if seen[key] {
+			return nil, fmt.Errorf("%w: duplicate %s", ErrInvalidRule, key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			switch Frequency(value) {
+			case Daily, Weekly, Monthly:
+				r.Freq = Frequency(value)
+			default:
+				return nil, fmt.Errorf("%w: unsupported FREQ %s", ErrInvalidRule, value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: INTERVAL must be a positive integer", ErrInvalidRule)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: COUNT must be a positive integer", ErrInvalidRule)
+			}
+			r.Count = n
+		case "UNTIL":
+			t, err := parseUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			r.Until = &t
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				day, ok := weekdayCodes[code]
+				if !ok {
+					// ไม่รองรับแบบมีลำดับ เช่น 1MO, -1FR
+					return nil, fmt.Errorf("%w: unsupported BYDAY value %s", ErrInvalidRule, code)
+				}
+				r.ByDay = append(r.ByDay, day)
+			}
+		case "BYMONTHDAY":
+			for _, v := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(v)
+				if err != nil || n < 1 || n > 31 {
+					return nil, fmt.Errorf("%w: BYMONTHDAY must be between 1 and 31", ErrInvalidRule)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		case "WKST":
+			// สัปดาห์เริ่มวันจันทร์เสมอ
+			if value != "MO" {
+				return nil, fmt.Errorf("%w: only WKST=MO is supported", ErrInvalidRule)
+			}
+		default:
+			return nil, fmt.Errorf("%w: unsupported part %s", ErrInvalidRule, key)
+		}
+	}
+
+	switch {
+	case r.Freq == "":
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	case r.Count > 0 && r.Until != nil:
+		return nil, fmt.Errorf("%w: COUNT and UNTIL must not be used together", ErrInvalidRule)
+	case r.Count == 0 && r.Until == nil:
+		return nil, fmt.Errorf("%w: COUNT or UNTIL is required", ErrInvalidRule)
+	case r.Count > MaxOccurrences:
+		return nil, fmt.Errorf("%w: COUNT must not exceed %d", ErrInvalidRule, MaxOccurrences)
+	case len(r.ByMonthDay) > 0 && r.Freq != Monthly:
+		return nil, fmt.Errorf("%w: BYMONTHDAY is only supported with FREQ=MONTHLY", ErrInvalidRule)
+	}
+	return r, nil
+}
+
+// parseUntil รับทั้งแบบวันที่ (YYYYMMDD = ทั้งวันตาม UTC) และวันเวลา UTC (YYYYMMDDTHHMMSSZ)
+func parseUntil(v string) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", v); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("20060102", v); err == nil {
+		return t.Add(24*time.Hour - time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("%w: UNTIL must be YYYYMMDD or YYYYMMDDTHHMMSSZ", ErrInvalidRule)
+}
+
+// String แปลงกลับเป็นข้อความ RRULE (ไม่มี "RRULE:" นำหน้า)
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			codes[i] = strings.ToUpper(d.String()[:2])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			days[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// All ขยาย Rule เป็นเวลาเริ่มของทุกครั้ง โดยใช้เวลาในวันและ Time Zone ของ dtstart
+// (ครั้งที่อยู่ก่อน dtstart ไม่นับ; dtstart ที่ไม่ตรงกับ Rule จะไม่ถูกนับเป็นครั้งแรก)
+func (r *Rule) All(dtstart time.Time) []time.Time {
+	var out []time.Time
+	limit := r.Count
+	if limit == 0 || limit > MaxOccurrences {
+		limit = MaxOccurrences
+	}
+
+	emit := func(t time.Time) bool {
+		if t.Before(dtstart) {
+			return true
+		}
+		if r.Until != nil && t.After(*r.Until) {
+			return false
+		}
+		out = append(out, t)
+		return len(out) < limit
+	}
+
+	loc := dtstart.Location()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0, loc)
+	}
+
+	switch r.Freq {
+	case Daily:
+		allowed := weekdaySet(r.ByDay)
+		day := at(dtstart.Year(), dtstart.Month(), dtstart.Day())
+		for i := 0; i < maxPeriods; i, day = i+1, at(day.Year(), day.Month(), day.Day()+r.Interval) {
+			if r.Until != nil && day.After(*r.Until) {
+				break
+			}
+			if allowed != nil && !allowed[day.Weekday()] {
+				continue
+			}
+			if !emit(day) {
+				break
+			}
+		}
+
+	case Weekly:
+		days := sortedWeekdays(r.ByDay, dtstart.Weekday())
+		offset := (int(dtstart.Weekday()) + 6) % 7 // จันทร์ = 0
+		monday := at(dtstart.Year(), dtstart.Month(), dtstart.Day()-offset)
+	weeks:
+		for i := 0; i < maxPeriods; i, monday = i+1, at(monday.Year(), monday.Month(), monday.Day()+7*r.Interval) {
+			if r.Until != nil && monday.After(*r.Until) {
+				break
+			}
+			for _, d := range days {
+				if !emit(at(monday.Year(), monday.Month(), monday.Day()+(int(d)+6)%7)) {
+					break weeks
+				}
+			}
+		}
+
+	case Monthly:
+		allowed := weekdaySet(r.ByDay)
+		monthDays := r.ByMonthDay
+		if len(monthDays) == 0 && allowed == nil {
+			monthDays = []int{dtstart.Day()}
+		}
+		sort.Ints(monthDays)
+		first := time.Date(dtstart.Year(), dtstart.Month(), 1, 0, 0, 0, 0, loc)
+	months:
+		for i := 0; i < maxPeriods; i, first = i+1, first.AddDate(0, r.Interval, 0) {
+			if r.Until != nil && first.After(*r.Until) {
+				break
+			}
+			daysInMonth := time.Date(first.Year(), first.Month()+1, 0, 0, 0, 0, 0, loc).Day()
+			for d := 1; d <= daysInMonth; d++ {
+				// วันที่ไม่มีในเดือนนั้น (เช่น 31 ก.พ.) จะถูกข้ามตาม RFC 5545
+				if len(monthDays) > 0 && !containsInt(monthDays, d) {
+					continue
+				}
+				t := at(first.Year(), first.Month(), d)
+				if allowed != nil && !allowed[t.Weekday()] {
+					continue
+				}
+				if !emit(t) {
+					break months
+				}
+			}
+		}
+	}
+	return out
+}
+
+func weekdaySet(days []time.Weekday) map[time.Weekday]bool {
+	if len(days) == 0 {
+		return nil
+	}
+	set := map[time.Weekday]bool{}
+	for _, d := range days {
+		set[d] = true
+	}
+	return set
+}
+
+// sortedWeekdays วันในสัปดาห์เรียงจากจันทร์ -> อาทิตย์ (ไม่ระบุ = วันเดียวกับ dtstart)
+func sortedWeekdays(days []time.Weekday, fallback time.Weekday) []time.Weekday {
+	if len(days) == 0 {
+		return []time.Weekday{fallback}
+	}
+	set := weekdaySet(days)
+	var out []time.Weekday
+	for i := 0; i < 7; i++ {
+		d := time.Weekday((i + 1) % 7)
+		if set[d] {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func containsInt(list []int, v int) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
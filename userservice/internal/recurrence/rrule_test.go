@@ -0,0 +1,210 @@
+package recurrence
+
+import (
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string // String() หลังแปลง
+	}{
+		{"FREQ=WEEKLY;BYDAY=MO,WE,FR;UNTIL=20241231T235959Z", "FREQ=WEEKLY;BYDAY=MO,WE,FR;UNTIL=20241231T235959Z"},
+		{"RRULE:FREQ=DAILY;COUNT=10", "FREQ=DAILY;COUNT=10"},
+		{"freq=daily;interval=2;count=5", "FREQ=DAILY;INTERVAL=2;COUNT=5"},
+		{"FREQ=WEEKLY;INTERVAL=1;COUNT=3;WKST=MO", "FREQ=WEEKLY;COUNT=3"},
+		// UNTIL แบบวันที่ = ทั้งวันตาม UTC
+		{"FREQ=MONTHLY;BYMONTHDAY=1,15;UNTIL=20240630", "FREQ=MONTHLY;BYMONTHDAY=1,15;UNTIL=20240630T235959Z"},
+		{"FREQ=MONTHLY;BYMONTHDAY=31;COUNT=4", "FREQ=MONTHLY;BYMONTHDAY=31;COUNT=4"},
+		{"FREQ=DAILY;COUNT=500", "FREQ=DAILY;COUNT=500"},
+	}
+	for _, tt := range tests {
+		r, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if got := r.String(); got != tt.want {
+			t.Errorf("Parse(%q).String() = %q, want %q", tt.in, got, tt.want)
+		}
+		// String() ต้องแปลงกลับได้ค่าเดิม
+		again, err := Parse(r.String())
+		if err != nil || again.String() != r.String() {
+			t.Errorf("round trip of %q: %v, %v", tt.in, again, err)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"RRULE:",
+		"COUNT=3",
+		"FREQ=YEARLY;COUNT=3",
+		"FREQ=WEEKLY",
+		"FREQ=WEEKLY;COUNT=3;UNTIL=20241231",
+		"FREQ=WEEKLY;COUNT=0",
+		"FREQ=WEEKLY;COUNT=501",
+		"FREQ=WEEKLY;COUNT=x",
+		"FREQ=WEEKLY;INTERVAL=0;COUNT=3",
+		"FREQ=WEEKLY;BYDAY=1MO;COUNT=3",
+		"FREQ=WEEKLY;BYDAY=XX;COUNT=3",
+		"FREQ=WEEKLY;BYMONTHDAY=1;COUNT=3",
+		"FREQ=MONTHLY;BYMONTHDAY=32;COUNT=3",
+		"FREQ=MONTHLY;BYMONTHDAY=0;COUNT=3",
+		"FREQ=WEEKLY;WKST=SU;COUNT=3",
+		"FREQ=WEEKLY;COUNT=3;COUNT=4",
+		"FREQ=WEEKLY;BYHOUR=9;COUNT=3",
+		"FREQ=WEEKLY;COUNT",
+		"FREQ=WEEKLY;UNTIL=2024-12-31",
+	}
+	for _, in := range tests {
+		if _, err := Parse(in); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("Parse(%q) err = %v, want ErrInvalidRule", in, err)
+		}
+	}
+}
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("load location %s: %v", name, err)
+	}
+	return loc
+}
+
+func TestAll(t *testing.T) {
+	bangkok := mustLoad(t, "Asia/Bangkok")
+	newYork := mustLoad(t, "America/New_York")
+	const layout = "2006-01-02 15:04 MST"
+
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		want    []string // เวลาท้องถิ่นตาม layout
+	}{
+		{
+			name:    "weekly byday until",
+			rule:    "FREQ=WEEKLY;BYDAY=MO,WE,FR;UNTIL=20240112",
+			dtstart: time.Date(2024, 1, 1, 8, 0, 0, 0, bangkok),
+			want: []string{
+				"2024-01-01 08:00 +07", "2024-01-03 08:00 +07", "2024-01-05 08:00 +07",
+				"2024-01-08 08:00 +07", "2024-01-10 08:00 +07", "2024-01-12 08:00 +07",
+			},
+		},
+		{
+			// UNTIL นับรวมเวลานั้นพอดี
+			name:    "until is inclusive",
+			rule:    "FREQ=DAILY;UNTIL=20240103T010000Z",
+			dtstart: time.Date(2024, 1, 1, 8, 0, 0, 0, bangkok),
+			want:    []string{"2024-01-01 08:00 +07", "2024-01-02 08:00 +07", "2024-01-03 08:00 +07"},
+		},
+		{
+			name:    "daily count with interval",
+			rule:    "FREQ=DAILY;INTERVAL=2;COUNT=3",
+			dtstart: time.Date(2024, 2, 27, 18, 30, 0, 0, time.UTC),
+			want:    []string{"2024-02-27 18:30 UTC", "2024-02-29 18:30 UTC", "2024-03-02 18:30 UTC"},
+		},
+		{
+			name:    "weekly count without byday uses dtstart weekday",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;COUNT=3",
+			dtstart: time.Date(2024, 1, 4, 7, 0, 0, 0, bangkok),
+			want:    []string{"2024-01-04 07:00 +07", "2024-01-18 07:00 +07", "2024-02-01 07:00 +07"},
+		},
+		{
+			// เดือนที่ไม่มีวันที่ 31 ถูกข้าม
+			name:    "monthly bymonthday 31",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=31;COUNT=4",
+			dtstart: time.Date(2024, 1, 31, 9, 0, 0, 0, bangkok),
+			want:    []string{"2024-01-31 09:00 +07", "2024-03-31 09:00 +07", "2024-05-31 09:00 +07", "2024-07-31 09:00 +07"},
+		},
+		{
+			name:    "monthly defaults to dtstart day",
+			rule:    "FREQ=MONTHLY;COUNT=3",
+			dtstart: time.Date(2024, 1, 15, 9, 0, 0, 0, bangkok),
+			want:    []string{"2024-01-15 09:00 +07", "2024-02-15 09:00 +07", "2024-03-15 09:00 +07"},
+		},
+		{
+			// ทุกวันจันทร์ในเดือน
+			name:    "monthly byday",
+			rule:    "FREQ=MONTHLY;BYDAY=MO;COUNT=5",
+			dtstart: time.Date(2024, 1, 1, 9, 0, 0, 0, bangkok),
+			want: []string{
+				"2024-01-01 09:00 +07", "2024-01-08 09:00 +07", "2024-01-15 09:00 +07",
+				"2024-01-22 09:00 +07", "2024-01-29 09:00 +07",
+			},
+		},
+		{
+			// เวลาท้องถิ่นคงเดิมข้ามวันเปลี่ยนเวลา (DST เริ่ม 10 มี.ค. 2024)
+			name:    "weekly across dst start",
+			rule:    "FREQ=WEEKLY;COUNT=3",
+			dtstart: time.Date(2024, 3, 3, 9, 0, 0, 0, newYork),
+			want:    []string{"2024-03-03 09:00 EST", "2024-03-10 09:00 EDT", "2024-03-17 09:00 EDT"},
+		},
+		{
+			// DST สิ้นสุด 3 พ.ย. 2024
+			name:    "daily across dst end",
+			rule:    "FREQ=DAILY;COUNT=3",
+			dtstart: time.Date(2024, 11, 2, 9, 0, 0, 0, newYork),
+			want:    []string{"2024-11-02 09:00 EDT", "2024-11-03 09:00 EST", "2024-11-04 09:00 EST"},
+		},
+		{
+			// dtstart วันอังคารไม่ตรงกับ BYDAY: ไม่นับเป็นครั้งแรก
+			name:    "dtstart not matching byday",
+			rule:    "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=3",
+			dtstart: time.Date(2024, 1, 2, 8, 0, 0, 0, bangkok),
+			want:    []string{"2024-01-03 08:00 +07", "2024-01-08 08:00 +07", "2024-01-10 08:00 +07"},
+		},
+		{
+			name:    "dtstart not matching bymonthday",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=10;COUNT=2",
+			dtstart: time.Date(2024, 1, 20, 8, 0, 0, 0, bangkok),
+			want:    []string{"2024-02-10 08:00 +07", "2024-03-10 08:00 +07"},
+		},
+		{
+			name:    "daily byday skips weekends",
+			rule:    "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;COUNT=3",
+			dtstart: time.Date(2024, 1, 5, 6, 0, 0, 0, bangkok),
+			want:    []string{"2024-01-05 06:00 +07", "2024-01-08 06:00 +07", "2024-01-09 06:00 +07"},
+		},
+		{
+			// ไม่มีวันที่ตรงเลย: ต้องจบเอง (ไม่วนไม่รู้จบ)
+			name:    "no matching dates",
+			rule:    "FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=31;UNTIL=20300101",
+			dtstart: time.Date(2024, 2, 1, 8, 0, 0, 0, bangkok),
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			got := r.All(tt.dtstart)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d occurrences %v, want %d", len(got), got, len(tt.want))
+			}
+			for i := range got {
+				if s := got[i].Format(layout); s != tt.want[i] {
+					t.Errorf("occurrence %d = %s, want %s", i, s, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAllCappedAtMaxOccurrences(t *testing.T) {
+	r, err := Parse("FREQ=DAILY;UNTIL=20991231")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := r.All(time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)); len(got) != MaxOccurrences {
+		t.Fatalf("got %d occurrences, want %d", len(got), MaxOccurrences)
+	}
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+	"users/internal/models"
+)
+
+var (
+	ErrAssignmentNotFound = errors.New("assignment not found")
+	// สถานะถูกเปลี่ยนไปแล้วระหว่างอ่านกับบันทึก (เช่น ตัวตรวจ overdue ทำงานพร้อมกัน)
+	ErrAssignmentStatusChanged = errors.New("assignment status has changed, please reload")
+)
+
+type AssignmentRepository interface {
+	// งานมอบหมายพร้อมไฟล์แนบ
+	GetAssignmentByID(id int) (*models.Assignment, error)
+	// เปลี่ยนสถานะ (พร้อมข้อมูลการส่งงาน/ผลตรวจใน a) เฉพาะเมื่อสถานะปัจจุบันยังเป็น from
+	// แล้วเพิ่มไฟล์แนบและการแจ้งเตือน (outbox) ใน Transaction เดียวกัน
+	TransitionAssignment(a *models.Assignment, from string, attachments []models.AssignmentAttachment, notifications []models.OutboxMessage) error
+	// เปลี่ยนงาน pending/in_progress ที่เลย due_date ก่อน now เป็น overdue พร้อมบันทึก overdue_at คืนงานที่ถูกเปลี่ยน
+	// ข้ามงานที่เคยเป็น overdue แล้ว (overdue_at) และงานที่เคยส่งแล้ว (submitted_at: ถูกส่งกลับให้แก้)
+	MarkOverdueAssignments(now time.Time) ([]models.Assignment, error)
+}
+
+type assignmentRepository struct {
+	db *sql.DB
+}
+
+func NewAssignmentRepository(db *sql.DB) AssignmentRepository {
+	return &assignmentRepository{db: db}
+}
+
+const assignmentColumns = `id, title, description, client_id, trainer_id, due_date, status, created_at, updated_at,
+	submission_notes, submitted_at, feedback, reviewed_at, overdue_at`
+
+func scanAssignment(row rowScanner, a *models.Assignment) error {
+	return row.Scan(&a.ID, &a.Title, &a.Description, &a.ClientID, &a.TrainerID, &a.DueDate, &a.Status,
+		&a.CreatedAt, &a.UpdatedAt, &a.SubmissionNotes, &a.SubmittedAt, &a.Feedback, &a.ReviewedAt, &a.OverdueAt)
+}
+
+func (r *assignmentRepository) GetAssignmentByID(id int) (*models.Assignment, error) {
+	var a models.Assignment
+	query := `
+		SELECT a.*, c.name, c.user_id
+		FROM (SELECT ` + assignmentColumns + ` FROM assignments WHERE id = $1) a
+		JOIN clients c ON c.id = a.client_id`
+	err := r.db.QueryRow(query, id).Scan(&a.ID, &a.Title, &a.Description, &a.ClientID, &a.TrainerID, &a.DueDate, &a.Status,
+		&a.CreatedAt, &a.UpdatedAt, &a.SubmissionNotes, &a.SubmittedAt, &a.Feedback, &a.ReviewedAt, &a.OverdueAt, &a.ClientName, &a.ClientUserID)
+	if err == sql.ErrNoRows {
+		return nil, ErrAssignmentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`
+		SELECT id, assignment_id, url, file_name, content_type, created_at
+		FROM assignment_attachments WHERE assignment_id = $1 ORDER BY id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	a.Attachments = []models.AssignmentAttachment{}
+	for rows.Next() {
+		var f models.AssignmentAttachment
+		if err := rows.Scan(&f.ID, &f.AssignmentID, &f.URL, &f.FileName, &f.ContentType, &f.CreatedAt); err != nil {
+			return nil, err
+		}
+		a.Attachments = append(a.Attachments, f)
+	}
+	return &a, rows.Err()
+}
+
+func (r *assignmentRepository) TransitionAssignment(a *models.Assignment, from string, attachments []models.AssignmentAttachment, notifications []models.OutboxMessage) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		UPDATE assignments
+		SET status = $1, submission_notes = $2, submitted_at = $3, feedback = $4, reviewed_at = $5, updated_at = NOW()
+		WHERE id = $6 AND status = $7
+		RETURNING updated_at`,
+		a.Status, a.SubmissionNotes, a.SubmittedAt, a.Feedback, a.ReviewedAt, a.ID, from,
+	).Scan(&a.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrAssignmentStatusChanged
+	}
+	if err != nil {
+		return err
+	}
+
+	for i := range attachments {
+		f := &attachments[i]
+		f.AssignmentID = a.ID
+		if err := tx.QueryRow(`
+			INSERT INTO assignment_attachments (assignment_id, url, file_name, content_type)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, created_at`,
+			f.AssignmentID, f.URL, f.FileName, f.ContentType,
+		).Scan(&f.ID, &f.CreatedAt); err != nil {
+			return err
+		}
+	}
+	for _, m := range notifications {
+		if err := enqueueNotification(tx, m); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *assignmentRepository) MarkOverdueAssignments(now time.Time) ([]models.Assignment, error) {
+	rows, err := r.db.Query(`
+		UPDATE assignments
+		SET status = $1, overdue_at = $4, updated_at = NOW()
+		WHERE status IN ($2, $3) AND due_date < $4
+		  AND overdue_at IS NULL AND submitted_at IS NULL
+		RETURNING `+assignmentColumns,
+		models.AssignmentOverdue, models.AssignmentPending, models.AssignmentInProgress, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var assignments []models.Assignment
+	for rows.Next() {
+		var a models.Assignment
+		if err := scanAssignment(rows, &a); err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, a)
+	}
+	return assignments, rows.Err()
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"users/internal/models"
+)
+
+var ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+
+type CalendarFeedRepository interface {
+	// สร้างหรือแทนที่ token เดิมของผู้ใช้ (ลิงก์เก่าใช้ไม่ได้ทันที)
+	UpsertCalendarFeed(f *models.CalendarFeed) error
+	GetCalendarFeedByUserID(userID int) (*models.CalendarFeed, error)
+	// หาเจ้าของจาก hash ของ token (คืน role ด้วย เพื่อเลือกนัดของเทรนเนอร์/ลูกค้า) และบันทึกเวลาที่ถูกเรียก
+	GetCalendarFeedOwner(tokenHash string) (*models.User, error)
+	DeleteCalendarFeed(userID int) error
+}
+
+type calendarFeedRepository struct {
+	db *sql.DB
+}
+
+func NewCalendarFeedRepository(db *sql.DB) CalendarFeedRepository {
+	return &calendarFeedRepository{db: db}
+}
+
+func (r *calendarFeedRepository) UpsertCalendarFeed(f *models.CalendarFeed) error {
+	query := `
+		INSERT INTO calendar_feed_tokens (user_id, token_hash)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET token_hash = EXCLUDED.token_hash, last_accessed_at = NULL, created_at = NOW()
+		RETURNING id, last_accessed_at, created_at`
+	return r.db.QueryRow(query, f.UserID, f.TokenHash).Scan(&f.ID, &f.LastAccessedAt, &f.CreatedAt)
+}
+
+func (r *calendarFeedRepository) GetCalendarFeedByUserID(userID int) (*models.CalendarFeed, error) {
+	query := `SELECT id, user_id, token_hash, last_accessed_at, created_at FROM calendar_feed_tokens WHERE user_id = $1`
+	var f models.CalendarFeed
+	err := r.db.QueryRow(query, userID).Scan(&f.ID, &f.UserID, &f.TokenHash, &f.LastAccessedAt, &f.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrCalendarFeedNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *calendarFeedRepository) GetCalendarFeedOwner(tokenHash string) (*models.User, error) {
+	query := `
+		UPDATE calendar_feed_tokens f SET last_accessed_at = NOW()
+		FROM users u
+		WHERE f.token_hash = $1 AND u.id = f.user_id
+		RETURNING u.id, u.name, u.email, u.role`
+	var u models.User
+	err := r.db.QueryRow(query, tokenHash).Scan(&u.ID, &u.Name, &u.Email, &u.Role)
+	if err == sql.ErrNoRows {
+		return nil, ErrCalendarFeedNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *calendarFeedRepository) DeleteCalendarFeed(userID int) error {
+	res, err := r.db.Exec(`DELETE FROM calendar_feed_tokens WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return ErrCalendarFeedNotFound
+	}
+	return nil
+}
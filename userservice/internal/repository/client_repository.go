@@ -3,6 +3,7 @@ package repository
 import (
 	"database/sql"
 	"errors"
+	"users/internal/listing"
 	"users/internal/models"
 )
 
@@ -14,7 +15,7 @@ type ClientRepository interface {
 	DeleteClient(id int, trainerID int) error
 
 	// Note methods
-	GetNotesByClientID(clientID int) ([]models.ClientNote, error)
+	ListNotes(clientID int, p *listing.Params) (*listing.Page[models.ClientNote], error)
 	CreateNote(note *models.ClientNote) error
 }
 
@@ -32,7 +33,7 @@ func NewClientRepository(db *sql.DB) ClientRepository {
 // 1. Get All Clients
 func (r *clientRepository) GetAllClients(trainerID int) ([]models.Client, error) {
 	query := `
-		SELECT id, trainer_id, name, email, phone_number, avatar_url, 
+		SELECT id, trainer_id, user_id, name, email, phone_number, avatar_url, 
 		       birth_date, gender, height_cm, weight_kg, goal, 
 		       injuries, activity_level, medical_conditions, created_at
 		FROM clients 
@@ -49,7 +50,7 @@ func (r *clientRepository) GetAllClients(trainerID int) ([]models.Client, error)
 	for rows.Next() {
 		var c models.Client
 		if err := rows.Scan(
-			&c.ID, &c.TrainerID, &c.Name, &c.Email, &c.Phone, &c.AvatarURL,
+			&c.ID, &c.TrainerID, &c.UserID, &c.Name, &c.Email, &c.Phone, &c.AvatarURL,
 			&c.BirthDate, &c.Gender, &c.Height, &c.Weight, &c.Goal,
 			&c.Injuries, &c.ActivityLevel, &c.MedicalConditions, &c.CreatedAt,
 		); err != nil {
@@ -60,8 +61,14 @@ func (r *clientRepository) GetAllClients(trainerID int) ([]models.Client, error)
 	return clients, nil
 }
 
-// 2. Create Client
+// 2. Create Client (น้ำหนัก/ส่วนสูงที่กรอกมาถูกบันทึกเป็นการวัดครั้งแรกด้วย)
 func (r *clientRepository) CreateClient(client *models.Client) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
 		INSERT INTO clients (
 			trainer_id, name, email, phone_number, 
@@ -71,18 +78,25 @@ func (r *clientRepository) CreateClient(client *models.Client) error {
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING id, created_at
 	`
-	return r.db.QueryRow(
+	err = tx.QueryRow(
 		query,
 		client.TrainerID, client.Name, client.Email, client.Phone,
 		client.Gender, client.Height, client.Weight, client.Goal, client.BirthDate,
 		client.Injuries, client.ActivityLevel, client.MedicalConditions, client.AvatarURL,
 	).Scan(&client.ID, &client.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if err := insertInitialMeasurement(tx, client); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // 3. Get Client By ID
 func (r *clientRepository) GetClientByID(id int, trainerID int) (*models.Client, error) {
 	query := `
-		SELECT id, trainer_id, name, email, phone_number, avatar_url, 
+		SELECT id, trainer_id, user_id, name, email, phone_number, avatar_url, 
 		       birth_date, gender, height_cm, weight_kg, goal, 
 		       injuries, activity_level, medical_conditions, created_at
 		FROM clients 
@@ -90,7 +104,7 @@ func (r *clientRepository) GetClientByID(id int, trainerID int) (*models.Client,
 	`
 	var c models.Client
 	err := r.db.QueryRow(query, id, trainerID).Scan(
-		&c.ID, &c.TrainerID, &c.Name, &c.Email, &c.Phone, &c.AvatarURL,
+		&c.ID, &c.TrainerID, &c.UserID, &c.Name, &c.Email, &c.Phone, &c.AvatarURL,
 		&c.BirthDate, &c.Gender, &c.Height, &c.Weight, &c.Goal,
 		&c.Injuries, &c.ActivityLevel, &c.MedicalConditions, &c.CreatedAt,
 	)
@@ -143,28 +157,32 @@ func (r *clientRepository) DeleteClient(id int, trainerID int) error {
 
 // --- Notes Implementation ---
 
-func (r *clientRepository) GetNotesByClientID(clientID int) ([]models.ClientNote, error) {
-	query := `
-		SELECT id, client_id, content, type, created_by, created_at 
-		FROM client_notes 
-		WHERE client_id = $1 
-		ORDER BY created_at DESC
-	`
-	rows, err := r.db.Query(query, clientID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+// NoteList ตัวกรอง/การเรียงของ GET /clients/:id/notes
+var NoteList = &listing.Spec[models.ClientNote]{
+	Sorts: map[string]listing.Sort[models.ClientNote]{
+		"created_at": {Column: "created_at", Key: func(n models.ClientNote) any { return n.CreatedAt }},
+	},
+	DefaultSort: "-created_at",
+	IDColumn:    "id",
+	ID:          func(n models.ClientNote) int { return n.ID },
+	Filters: map[string]listing.Filter{
+		"type": {Column: "type", Type: listing.TextList},
+		"q":    {Column: "content", Type: listing.Search},
+		"from": {Column: "created_at", Type: listing.Time, Op: ">="},
+		"to":   {Column: "created_at", Type: listing.Time, Op: "<"},
+	},
+}
 
-	var notes []models.ClientNote
-	for rows.Next() {
+func (r *clientRepository) ListNotes(clientID int, p *listing.Params) (*listing.Page[models.ClientNote], error) {
+	q := &listing.Query{}
+	q.Where("client_id = " + q.Arg(clientID))
+
+	selectFrom := `SELECT id, client_id, content, type, created_by, created_at FROM client_notes`
+	return listing.Run(r.db, NoteList, p, selectFrom, q, func(rows *sql.Rows) (models.ClientNote, error) {
 		var n models.ClientNote
-		if err := rows.Scan(&n.ID, &n.ClientID, &n.Content, &n.Type, &n.CreatedBy, &n.CreatedAt); err != nil {
-			return nil, err
-		}
-		notes = append(notes, n)
-	}
-	return notes, nil
+		err := rows.Scan(&n.ID, &n.ClientID, &n.Content, &n.Type, &n.CreatedBy, &n.CreatedAt)
+		return n, err
+	})
 }
 
 func (r *clientRepository) CreateNote(note *models.ClientNote) error {
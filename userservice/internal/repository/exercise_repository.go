@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"users/internal/models"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrExerciseNotFound = errors.New("exercise not found")
+	ErrExerciseInUse    = errors.New("exercise is in use")
+)
+
+type ExerciseRepository interface {
+	GetExercises(trainerID int, filter models.ExerciseFilter) ([]models.Exercise, error)
+	GetExerciseByID(id int, trainerID int) (*models.Exercise, error)
+	// ไม่จำกัดเจ้าของ (ผู้มีสิทธิ์ ownership bypass เช่น admin)
+	GetAnyExerciseByID(id int) (*models.Exercise, error)
+	CreateExercise(e *models.Exercise) error
+	UpdateExercise(e *models.Exercise) error
+	DeleteExercise(id int, trainerID *int) error
+}
+
+type exerciseRepository struct {
+	db *sql.DB
+}
+
+func NewExerciseRepository(db *sql.DB) ExerciseRepository {
+	return &exerciseRepository{db: db}
+}
+
+// คอลัมน์ที่ใช้ SELECT ท่าฝึก (ใช้ร่วมกับ scanExercise)
+const exerciseColumns = `id, trainer_id, name, category, primary_muscles, secondary_muscles, equipment, instructions, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanExercise(row rowScanner) (*models.Exercise, error) {
+	var e models.Exercise
+	err := row.Scan(
+		&e.ID, &e.TrainerID, &e.Name, &e.Category,
+		(*pq.StringArray)(&e.PrimaryMuscles), (*pq.StringArray)(&e.SecondaryMuscles),
+		&e.Equipment, &e.Instructions, &e.CreatedAt, &e.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	e.IsGlobal = e.TrainerID == nil
+	return &e, nil
+}
+
+// 1. ดึงรายการท่าฝึก (ท่ากลาง + ท่าส่วนตัวของเทรนเนอร์คนนี้) พร้อมตัวกรอง
+func (r *exerciseRepository) GetExercises(trainerID int, filter models.ExerciseFilter) ([]models.Exercise, error) {
+	var conditions []string
+	args := []interface{}{trainerID}
+
+	switch filter.Scope {
+	case "global":
+		conditions = append(conditions, "trainer_id IS NULL")
+	case "mine":
+		conditions = append(conditions, "trainer_id = $1")
+	default:
+		conditions = append(conditions, "(trainer_id IS NULL OR trainer_id = $1)")
+	}
+
+	if filter.Search != "" {
+		args = append(args, "%"+filter.Search+"%")
+		conditions = append(conditions, fmt.Sprintf("name ILIKE $%d", len(args)))
+	}
+	if filter.Category != "" {
+		args = append(args, filter.Category)
+		conditions = append(conditions, fmt.Sprintf("category = $%d", len(args)))
+	}
+	if filter.Muscle != "" {
+		args = append(args, filter.Muscle)
+		conditions = append(conditions, fmt.Sprintf("($%d = ANY(primary_muscles) OR $%d = ANY(secondary_muscles))", len(args), len(args)))
+	}
+	if filter.Equipment != "" {
+		args = append(args, filter.Equipment)
+		conditions = append(conditions, fmt.Sprintf("equipment = $%d", len(args)))
+	}
+
+	query := `SELECT ` + exerciseColumns + ` FROM exercises WHERE ` + strings.Join(conditions, " AND ") + ` ORDER BY name ASC`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exercises []models.Exercise
+	for rows.Next() {
+		e, err := scanExercise(rows)
+		if err != nil {
+			return nil, err
+		}
+		exercises = append(exercises, *e)
+	}
+	return exercises, rows.Err()
+}
+
+// 2. ดึงท่าฝึกตาม ID (ต้องเป็นท่ากลาง หรือท่าของเทรนเนอร์คนนี้)
+func (r *exerciseRepository) GetExerciseByID(id int, trainerID int) (*models.Exercise, error) {
+	query := `SELECT ` + exerciseColumns + ` FROM exercises WHERE id = $1 AND (trainer_id IS NULL OR trainer_id = $2)`
+	e, err := scanExercise(r.db.QueryRow(query, id, trainerID))
+	if err == sql.ErrNoRows {
+		return nil, ErrExerciseNotFound
+	}
+	return e, err
+}
+
+func (r *exerciseRepository) GetAnyExerciseByID(id int) (*models.Exercise, error) {
+	query := `SELECT ` + exerciseColumns + ` FROM exercises WHERE id = $1`
+	e, err := scanExercise(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrExerciseNotFound
+	}
+	return e, err
+}
+
+// 3. สร้างท่าฝึกใหม่
+func (r *exerciseRepository) CreateExercise(e *models.Exercise) error {
+	query := `
+		INSERT INTO exercises (trainer_id, name, category, primary_muscles, secondary_muscles, equipment, instructions)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at`
+	err := r.db.QueryRow(
+		query,
+		e.TrainerID, e.Name, e.Category,
+		pq.Array(e.PrimaryMuscles), pq.Array(e.SecondaryMuscles),
+		e.Equipment, e.Instructions,
+	).Scan(&e.ID, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	e.IsGlobal = e.TrainerID == nil
+	return nil
+}
+
+// 4. แก้ไขท่าฝึก (แก้ได้เฉพาะท่าของตัวเอง; TrainerID = nil คือแก้ท่ากลาง ใช้กับ admin)
+func (r *exerciseRepository) UpdateExercise(e *models.Exercise) error {
+	query := `
+		UPDATE exercises
+		SET name=$1, category=$2, primary_muscles=$3, secondary_muscles=$4, equipment=$5, instructions=$6, updated_at=NOW()
+		WHERE id=$7 AND trainer_id IS NOT DISTINCT FROM $8
+		RETURNING created_at, updated_at`
+	err := r.db.QueryRow(
+		query,
+		e.Name, e.Category,
+		pq.Array(e.PrimaryMuscles), pq.Array(e.SecondaryMuscles),
+		e.Equipment, e.Instructions,
+		e.ID, e.TrainerID,
+	).Scan(&e.CreatedAt, &e.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrExerciseNotFound
+	}
+	e.IsGlobal = e.TrainerID == nil
+	return err
+}
+
+// 5. ลบท่าฝึก (ลบได้เฉพาะท่าของตัวเอง; trainerID = nil คือลบท่ากลาง ใช้กับ admin)
+func (r *exerciseRepository) DeleteExercise(id int, trainerID *int) error {
+	res, err := r.db.Exec(`DELETE FROM exercises WHERE id=$1 AND trainer_id IS NOT DISTINCT FROM $2`, id, trainerID)
+	if err != nil {
+		// 23503 = foreign_key_violation (ท่านี้ถูกใช้อยู่ในโปรแกรมหรือบันทึกการฝึก)
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return ErrExerciseInUse
+		}
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return ErrExerciseNotFound
+	}
+	return nil
+}
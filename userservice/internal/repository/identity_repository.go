@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"users/internal/models"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrIdentityNotFound = errors.New("identity not found")
+	// (provider, subject) ถูกเชื่อมกับผู้ใช้อื่นไปแล้ว
+	ErrIdentityAlreadyLinked = errors.New("identity is already linked to a user")
+)
+
+type IdentityRepository interface {
+	// ผู้ใช้ที่เชื่อมกับ (provider, subject) พร้อมบันทึกเวลา Login
+	GetUserByIdentity(provider, subject string) (*models.User, error)
+	// เชื่อม identity กับผู้ใช้เดิม (emailVerified = ผู้ให้บริการยืนยันอีเมลแล้ว -> ตั้ง users.verified ด้วย)
+	LinkIdentity(identity *models.UserIdentity, emailVerified bool) error
+	// สร้างผู้ใช้ใหม่แบบไม่มีรหัสผ่านพร้อม identity ใน Transaction เดียว
+	CreateUserWithIdentity(user models.User, identity *models.UserIdentity) (*models.User, error)
+}
+
+type identityRepository struct {
+	db *sql.DB
+}
+
+func NewIdentityRepository(db *sql.DB) IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+func (r *identityRepository) GetUserByIdentity(provider, subject string) (*models.User, error) {
+	var u models.User
+	err := r.db.QueryRow(`
+		WITH i AS (
+			UPDATE user_identities SET last_login_at = NOW()
+			WHERE provider = $1 AND subject = $2
+			RETURNING user_id
+		)
+		SELECT u.id, u.name, u.email, u.role, u.verified, u.created_at, u.updated_at
+		FROM users u JOIN i ON i.user_id = u.id`, provider, subject,
+	).Scan(&u.ID, &u.Name, &u.Email, &u.Role, &u.Verified, &u.CreatedAt, &u.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrIdentityNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *identityRepository) LinkIdentity(identity *models.UserIdentity, emailVerified bool) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertIdentity(tx, identity); err != nil {
+		return err
+	}
+	if emailVerified {
+		if _, err := tx.Exec(`UPDATE users SET verified = TRUE, updated_at = NOW() WHERE id = $1 AND NOT verified`, identity.UserID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *identityRepository) CreateUserWithIdentity(user models.User, identity *models.UserIdentity) (*models.User, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// password_hash = '' คือไม่มีรหัสผ่าน (Login ด้วยรหัสผ่านไม่ได้จนกว่าจะตั้งผ่าน forgot-password)
+	var u models.User
+	err = tx.QueryRow(
+		`INSERT INTO users (name, email, password_hash, role, verified) VALUES ($1, $2, '', $3, $4)
+		 RETURNING id, name, email, role, verified, created_at, updated_at`,
+		user.Name, user.Email, user.Role, user.Verified,
+	).Scan(&u.ID, &u.Name, &u.Email, &u.Role, &u.Verified, &u.CreatedAt, &u.UpdatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, ErrEmailAlreadyExists
+		}
+		return nil, err
+	}
+
+	identity.UserID = u.ID
+	if err := insertIdentity(tx, identity); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func insertIdentity(tx *sql.Tx, identity *models.UserIdentity) error {
+	err := tx.QueryRow(`
+		INSERT INTO user_identities (user_id, provider, subject, email, last_login_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING id, created_at, last_login_at`,
+		identity.UserID, identity.Provider, identity.Subject, identity.Email,
+	).Scan(&identity.ID, &identity.CreatedAt, &identity.LastLoginAt)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrIdentityAlreadyLinked
+	}
+	return err
+}
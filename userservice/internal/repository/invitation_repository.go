@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"users/internal/models"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrInvitationNotFound  = errors.New("invitation not found")
+	ErrInvitationUsed      = errors.New("invitation already accepted")
+	ErrClientAlreadyLinked = errors.New("client is already linked to a user")
+	ErrEmailAlreadyExists  = errors.New("email already exists")
+)
+
+type InvitationRepository interface {
+	CreateInvitation(inv *models.ClientInvitation) error
+	GetInvitationByTokenHash(tokenHash string) (*models.ClientInvitation, error)
+	AcceptInvitation(invitationID int, user models.User, hashedPassword string) (*models.User, error)
+}
+
+type invitationRepository struct {
+	db *sql.DB
+}
+
+func NewInvitationRepository(db *sql.DB) InvitationRepository {
+	return &invitationRepository{db: db}
+}
+
+func (r *invitationRepository) CreateInvitation(inv *models.ClientInvitation) error {
+	query := `
+		INSERT INTO client_invitations (client_id, trainer_id, email, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+	return r.db.QueryRow(query, inv.ClientID, inv.TrainerID, inv.Email, inv.TokenHash, inv.ExpiresAt).
+		Scan(&inv.ID, &inv.CreatedAt)
+}
+
+func (r *invitationRepository) GetInvitationByTokenHash(tokenHash string) (*models.ClientInvitation, error) {
+	query := `
+		SELECT i.id, i.client_id, i.trainer_id, i.email, i.token_hash, i.expires_at,
+		       i.accepted_at, i.accepted_user_id, i.created_at, c.name, u.name
+		FROM client_invitations i
+		JOIN clients c ON c.id = i.client_id
+		JOIN users u ON u.id = i.trainer_id
+		WHERE i.token_hash = $1`
+	var inv models.ClientInvitation
+	err := r.db.QueryRow(query, tokenHash).Scan(
+		&inv.ID, &inv.ClientID, &inv.TrainerID, &inv.Email, &inv.TokenHash, &inv.ExpiresAt,
+		&inv.AcceptedAt, &inv.AcceptedUserID, &inv.CreatedAt, &inv.ClientName, &inv.TrainerName,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrInvitationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// AcceptInvitation สร้าง User (role = client), เชื่อมกับ clients.user_id และปิดคำเชิญ ใน Transaction เดียว
+func (r *invitationRepository) AcceptInvitation(invitationID int, user models.User, hashedPassword string) (*models.User, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// 1. Lock คำเชิญกันการกดรับซ้อนกัน
+	var clientID int
+	var acceptedAt sql.NullTime
+	err = tx.QueryRow(`SELECT client_id, accepted_at FROM client_invitations WHERE id = $1 FOR UPDATE`, invitationID).
+		Scan(&clientID, &acceptedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrInvitationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if acceptedAt.Valid {
+		return nil, ErrInvitationUsed
+	}
+
+	// 2. Client ต้องยังไม่ถูกเชื่อมกับบัญชีใด
+	var linkedUserID sql.NullInt64
+	if err := tx.QueryRow(`SELECT user_id FROM clients WHERE id = $1 FOR UPDATE`, clientID).Scan(&linkedUserID); err != nil {
+		return nil, err
+	}
+	if linkedUserID.Valid {
+		return nil, ErrClientAlreadyLinked
+	}
+
+	// 3. สร้าง User (ยังไม่ยืนยันอีเมล: ผู้ที่ได้ลิงก์คำเชิญอาจไม่ใช่เจ้าของอีเมล)
+	var u models.User
+	err = tx.QueryRow(
+		`INSERT INTO users (name, email, password_hash, role, verified) VALUES ($1, $2, $3, $4, FALSE)
+		 RETURNING id, name, email, role, verified, created_at, updated_at`,
+		user.Name, user.Email, hashedPassword, user.Role,
+	).Scan(&u.ID, &u.Name, &u.Email, &u.Role, &u.Verified, &u.CreatedAt, &u.UpdatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, ErrEmailAlreadyExists
+		}
+		return nil, err
+	}
+
+	// 4. เชื่อม Client กับ User และปิดคำเชิญ
+	if _, err := tx.Exec(`UPDATE clients SET user_id = $1, updated_at = NOW() WHERE id = $2`, u.ID, clientID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`UPDATE client_invitations SET accepted_at = NOW(), accepted_user_id = $1 WHERE id = $2`, u.ID, invitationID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
@@ -0,0 +1,352 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+	"users/internal/models"
+
+	"github.com/lib/pq"
+)
+
+type MeasurementRepository interface {
+	// ชนิดการวัด (มาตรฐาน + ของเทรนเนอร์คนนี้)
+	GetMeasurementTypes(trainerID int) ([]models.MeasurementType, error)
+	// ชนิดที่ลูกค้า (ผู้ใช้ role = client) เห็น: ชนิดมาตรฐาน + ชนิดของเทรนเนอร์ของตัวเอง (clients.trainer_id)
+	GetMeasurementTypesForClientUser(userID int) ([]models.MeasurementType, error)
+	CreateMeasurementType(t *models.MeasurementType) error
+	DeleteMeasurementType(id, trainerID int) error
+
+	// การวัดของลูกค้า (typeIDs: key -> measurement_types.id ที่ Service ตรวจแล้ว)
+	GetMeasurements(clientID int, from, to *time.Time) ([]models.BodyMeasurement, error)
+	GetMeasurementByID(clientID, id int) (*models.BodyMeasurement, error)
+	CreateMeasurement(m *models.BodyMeasurement, typeIDs map[string]int) error
+	UpdateMeasurement(m *models.BodyMeasurement, typeIDs map[string]int) error
+	DeleteMeasurement(clientID, id int) error
+
+	GetMeasurementSeries(clientID int, key string, from, to *time.Time) ([]models.MeasurementPoint, error)
+	GetLatestMeasurements(clientID int) ([]models.LatestMeasurement, error)
+}
+
+var (
+	ErrMeasurementNotFound     = errors.New("measurement not found")
+	ErrMeasurementTypeNotFound = errors.New("measurement type not found")
+	ErrMeasurementTypeExists   = errors.New("measurement type key already exists")
+	ErrMeasurementTypeInUse    = errors.New("measurement type is used by existing measurements")
+)
+
+type measurementRepository struct {
+	db *sql.DB
+}
+
+func NewMeasurementRepository(db *sql.DB) MeasurementRepository {
+	return &measurementRepository{db: db}
+}
+
+// ชนิดที่ลูกค้าคนหนึ่งใช้ได้: ชนิดมาตรฐาน หรือชนิดที่เทรนเนอร์ของลูกค้าสร้างเอง
+const visibleTypeCondition = `(t.trainer_id IS NULL OR t.trainer_id = (SELECT trainer_id FROM clients WHERE id = $1))`
+
+func (r *measurementRepository) GetMeasurementTypes(trainerID int) ([]models.MeasurementType, error) {
+	return r.getMeasurementTypes(`trainer_id = $1`, trainerID)
+}
+
+func (r *measurementRepository) GetMeasurementTypesForClientUser(userID int) ([]models.MeasurementType, error) {
+	return r.getMeasurementTypes(`trainer_id IN (SELECT trainer_id FROM clients WHERE user_id = $1)`, userID)
+}
+
+// getMeasurementTypes ชนิดมาตรฐาน + ชนิดของเทรนเนอร์ที่ตรงกับ ownerCondition
+func (r *measurementRepository) getMeasurementTypes(ownerCondition string, arg int) ([]models.MeasurementType, error) {
+	query := `
+		SELECT id, trainer_id, key, name, unit, created_at
+		FROM measurement_types
+		WHERE trainer_id IS NULL OR ` + ownerCondition + `
+		ORDER BY trainer_id NULLS FIRST, id`
+	rows, err := r.db.Query(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types := []models.MeasurementType{}
+	for rows.Next() {
+		var t models.MeasurementType
+		if err := rows.Scan(&t.ID, &t.TrainerID, &t.Key, &t.Name, &t.Unit, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		t.IsBuiltIn = t.TrainerID == nil
+		types = append(types, t)
+	}
+	return types, rows.Err()
+}
+
+func (r *measurementRepository) CreateMeasurementType(t *models.MeasurementType) error {
+	query := `
+		INSERT INTO measurement_types (trainer_id, key, name, unit)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+	err := r.db.QueryRow(query, t.TrainerID, t.Key, t.Name, t.Unit).Scan(&t.ID, &t.CreatedAt)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrMeasurementTypeExists
+	}
+	return err
+}
+
+// DeleteMeasurementType ลบได้เฉพาะชนิดที่เทรนเนอร์สร้างเอง และยังไม่มีค่าที่อ้างอิงอยู่
+func (r *measurementRepository) DeleteMeasurementType(id, trainerID int) error {
+	res, err := r.db.Exec(`DELETE FROM measurement_types WHERE id = $1 AND trainer_id = $2`, id, trainerID)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return ErrMeasurementTypeInUse
+	}
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return ErrMeasurementTypeNotFound
+	}
+	return nil
+}
+
+func (r *measurementRepository) GetMeasurements(clientID int, from, to *time.Time) ([]models.BodyMeasurement, error) {
+	return r.getMeasurements(`m.client_id = $1
+		  AND ($2::timestamptz IS NULL OR m.measured_at >= $2)
+		  AND ($3::timestamptz IS NULL OR m.measured_at < $3)`, clientID, from, to)
+}
+
+func (r *measurementRepository) GetMeasurementByID(clientID, id int) (*models.BodyMeasurement, error) {
+	list, err := r.getMeasurements(`m.client_id = $1 AND m.id = $2`, clientID, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, ErrMeasurementNotFound
+	}
+	return &list[0], nil
+}
+
+// getMeasurements ดึงการวัดพร้อมค่าทั้งหมด (ล่าสุดก่อน) ในคำสั่งเดียว
+func (r *measurementRepository) getMeasurements(where string, args ...interface{}) ([]models.BodyMeasurement, error) {
+	query := `
+		SELECT m.id, m.client_id, m.measured_at, m.notes, m.recorded_by, m.created_at, m.updated_at, t.key, v.value
+		FROM body_measurements m
+		LEFT JOIN body_measurement_values v ON v.measurement_id = m.id
+		LEFT JOIN measurement_types t ON t.id = v.type_id
+		WHERE ` + where + `
+		ORDER BY m.measured_at DESC, m.id DESC`
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.BodyMeasurement{}
+	for rows.Next() {
+		var (
+			m     models.BodyMeasurement
+			key   sql.NullString
+			value sql.NullFloat64
+		)
+		if err := rows.Scan(&m.ID, &m.ClientID, &m.MeasuredAt, &m.Notes, &m.RecordedBy, &m.CreatedAt, &m.UpdatedAt, &key, &value); err != nil {
+			return nil, err
+		}
+		// แถวของการวัดเดียวกันจะอยู่ติดกันเพราะ ORDER BY m.id
+		if n := len(list); n == 0 || list[n-1].ID != m.ID {
+			m.Values = map[string]float64{}
+			list = append(list, m)
+		}
+		if key.Valid {
+			list[len(list)-1].Values[key.String] = value.Float64
+		}
+	}
+	return list, rows.Err()
+}
+
+func (r *measurementRepository) CreateMeasurement(m *models.BodyMeasurement, typeIDs map[string]int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO body_measurements (client_id, measured_at, notes, recorded_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at`
+	if err := tx.QueryRow(query, m.ClientID, m.MeasuredAt, m.Notes, m.RecordedBy).Scan(&m.ID, &m.CreatedAt, &m.UpdatedAt); err != nil {
+		return err
+	}
+	if err := insertMeasurementValues(tx, m, typeIDs); err != nil {
+		return err
+	}
+	if err := syncClientProfile(tx, m.ClientID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateMeasurement แก้วันที่/โน้ต และแทนที่ค่าทั้งหมดด้วย m.Values
+func (r *measurementRepository) UpdateMeasurement(m *models.BodyMeasurement, typeIDs map[string]int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE body_measurements SET measured_at=$1, notes=$2, updated_at=NOW()
+		WHERE id=$3 AND client_id=$4
+		RETURNING recorded_by, created_at, updated_at`
+	err = tx.QueryRow(query, m.MeasuredAt, m.Notes, m.ID, m.ClientID).Scan(&m.RecordedBy, &m.CreatedAt, &m.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrMeasurementNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM body_measurement_values WHERE measurement_id = $1`, m.ID); err != nil {
+		return err
+	}
+	if err := insertMeasurementValues(tx, m, typeIDs); err != nil {
+		return err
+	}
+	if err := syncClientProfile(tx, m.ClientID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *measurementRepository) DeleteMeasurement(clientID, id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM body_measurements WHERE id=$1 AND client_id=$2`, id, clientID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return ErrMeasurementNotFound
+	}
+	if err := syncClientProfile(tx, clientID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertMeasurementValues(tx *sql.Tx, m *models.BodyMeasurement, typeIDs map[string]int) error {
+	stmt, err := tx.Prepare(`INSERT INTO body_measurement_values (measurement_id, type_id, value) VALUES ($1, $2, $3)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for key, value := range m.Values {
+		if _, err := stmt.Exec(m.ID, typeIDs[key], value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertInitialMeasurement บันทึกน้ำหนัก/ส่วนสูงที่กรอกตอนสร้างลูกค้าเป็นการวัดครั้งแรก
+// (เหมือนที่ migration 0006 ย้ายค่าเดิมของโปรไฟล์) ไม่มีทั้งสองค่า = ไม่บันทึก
+func insertInitialMeasurement(tx *sql.Tx, client *models.Client) error {
+	if client.Weight == nil && client.Height == nil {
+		return nil
+	}
+	var measurementID int
+	err := tx.QueryRow(`
+		INSERT INTO body_measurements (client_id, measured_at, notes, recorded_by)
+		VALUES ($1, $2, 'Initial client profile', $3)
+		RETURNING id`,
+		client.ID, client.CreatedAt, client.TrainerID,
+	).Scan(&measurementID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO body_measurement_values (measurement_id, type_id, value)
+		SELECT $1, t.id, CASE t.key WHEN $2 THEN $4::numeric ELSE $5::numeric END
+		FROM measurement_types t
+		WHERE t.trainer_id IS NULL
+		  AND ((t.key = $2 AND $4::numeric IS NOT NULL) OR (t.key = $3 AND $5::numeric IS NOT NULL))`,
+		measurementID, models.MeasurementWeight, models.MeasurementHeight, client.Weight, client.Height)
+	return err
+}
+
+// syncClientProfile สะท้อนน้ำหนัก/ส่วนสูงล่าสุดกลับไปที่ clients.weight_kg / height_cm
+// (ถ้าไม่มีการวัดเหลืออยู่ จะคงค่าเดิมของโปรไฟล์ไว้)
+func syncClientProfile(tx *sql.Tx, clientID int) error {
+	query := `
+		WITH latest AS (
+			SELECT DISTINCT ON (t.key) t.key, v.value
+			FROM body_measurements m
+			JOIN body_measurement_values v ON v.measurement_id = m.id
+			JOIN measurement_types t ON t.id = v.type_id
+			WHERE m.client_id = $1 AND t.trainer_id IS NULL AND t.key IN ($2, $3)
+			ORDER BY t.key, m.measured_at DESC, m.id DESC
+		)
+		UPDATE clients SET
+			weight_kg  = COALESCE((SELECT value FROM latest WHERE key = $2), weight_kg),
+			height_cm  = COALESCE((SELECT value FROM latest WHERE key = $3), height_cm),
+			updated_at = NOW()
+		WHERE id = $1`
+	_, err := tx.Exec(query, clientID, models.MeasurementWeight, models.MeasurementHeight)
+	return err
+}
+
+func (r *measurementRepository) GetMeasurementSeries(clientID int, key string, from, to *time.Time) ([]models.MeasurementPoint, error) {
+	query := `
+		SELECT m.id, m.measured_at, v.value
+		FROM body_measurements m
+		JOIN body_measurement_values v ON v.measurement_id = m.id
+		JOIN measurement_types t ON t.id = v.type_id
+		WHERE m.client_id = $1 AND t.key = $2 AND ` + visibleTypeCondition + `
+		  AND ($3::timestamptz IS NULL OR m.measured_at >= $3)
+		  AND ($4::timestamptz IS NULL OR m.measured_at < $4)
+		ORDER BY m.measured_at ASC, m.id ASC`
+	rows, err := r.db.Query(query, clientID, key, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := []models.MeasurementPoint{}
+	for rows.Next() {
+		var p models.MeasurementPoint
+		if err := rows.Scan(&p.MeasurementID, &p.MeasuredAt, &p.Value); err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+func (r *measurementRepository) GetLatestMeasurements(clientID int) ([]models.LatestMeasurement, error) {
+	query := `
+		SELECT DISTINCT ON (t.id) t.key, t.name, t.unit, v.value, m.measured_at
+		FROM body_measurements m
+		JOIN body_measurement_values v ON v.measurement_id = m.id
+		JOIN measurement_types t ON t.id = v.type_id
+		WHERE m.client_id = $1
+		ORDER BY t.id, m.measured_at DESC, m.id DESC`
+	rows, err := r.db.Query(query, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	latest := []models.LatestMeasurement{}
+	for rows.Next() {
+		var l models.LatestMeasurement
+		if err := rows.Scan(&l.Key, &l.Name, &l.Unit, &l.Value, &l.MeasuredAt); err != nil {
+			return nil, err
+		}
+		latest = append(latest, l)
+	}
+	return latest, rows.Err()
+}
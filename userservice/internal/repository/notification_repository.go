@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"users/internal/models"
+
+	"github.com/lib/pq"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+type NotificationRepository interface {
+	// เพิ่มเหตุการณ์ลง outbox (กระจายตามช่องทางที่ผู้รับเปิดไว้)
+	Enqueue(m models.OutboxMessage) error
+	// เตือนนัด scheduled / งาน pending,in_progress ที่เริ่ม/ครบกำหนดในช่วง (from, to] คืนจำนวนแถวที่เพิ่ม
+	// ใช้ dedupe_key ตาม id + เวลา จึงเรียกซ้ำได้ (เลื่อนนัดแล้วจะเตือนใหม่)
+	EnqueueScheduleReminders(from, to time.Time) (int64, error)
+	EnqueueAssignmentReminders(from, to time.Time) (int64, error)
+
+	// จองแถวที่ถึงเวลาส่ง (เลื่อน next_attempt_at ออกไป lease กันส่งซ้ำ ถ้า process ตายระหว่างส่งจะถูกจองใหม่เมื่อหมด lease)
+	ClaimOutbox(now time.Time, lease time.Duration, limit int) ([]models.OutboxDelivery, error)
+	MarkOutboxSent(id int) error
+	MarkOutboxRetry(id int, nextAttempt time.Time, lastError string) error
+	// ปิดแถวโดยไม่ส่งอีก (status = failed หรือ skipped)
+	MarkOutboxDone(id int, status, lastError string) error
+
+	// Inbox ในแอป
+	CreateNotification(userID int, template, title, body string) error
+	GetNotifications(userID int, unreadOnly bool, limit int) ([]models.Notification, error)
+	CountUnreadNotifications(userID int) (int, error)
+	MarkNotificationRead(userID, id int) error
+	MarkAllNotificationsRead(userID int) (int64, error)
+
+	// nil = ยังไม่เคยตั้งค่า
+	GetPreferences(userID int) (*models.NotificationPreferences, error)
+	UpsertPreferences(p *models.NotificationPreferences) error
+}
+
+type notificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// sqlExecer *sql.DB หรือ *sql.Tx (ให้ Repository อื่นเพิ่ม outbox ใน Transaction ของตัวเองได้)
+type sqlExecer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// outboxFanout กระจายเหตุการณ์ (user_id, template, payload, dedupe_key) จาก %s เป็น 1 แถวต่อช่องทาง
+// ตามการตั้งค่าของผู้รับ (ไม่มีการตั้งค่า: in_app + email)
+const outboxFanout = `
+	INSERT INTO notification_outbox (user_id, channel, template, payload, dedupe_key)
+	SELECT e.user_id, ch.channel, e.template, e.payload, e.dedupe_key || ':' || ch.channel
+	FROM (%s) AS e(user_id, template, payload, dedupe_key)
+	LEFT JOIN notification_preferences p ON p.user_id = e.user_id
+	CROSS JOIN LATERAL (VALUES
+		('in_app', TRUE),
+		('email', COALESCE(p.email_enabled, TRUE)),
+		('sms', COALESCE(p.sms_enabled, FALSE))
+	) AS ch(channel, enabled)
+	WHERE ch.enabled
+	ON CONFLICT (dedupe_key) DO NOTHING`
+
+func enqueueNotification(ex sqlExecer, m models.OutboxMessage) error {
+	payload, err := json.Marshal(m.Payload)
+	if err != nil {
+		return err
+	}
+	if len(m.Channels) > 0 {
+		_, err = ex.Exec(`
+			INSERT INTO notification_outbox (user_id, channel, template, payload, dedupe_key)
+			SELECT $1, ch, $2, $3, NULLIF($4::text, '') || ':' || ch
+			FROM unnest($5::text[]) AS ch
+			ON CONFLICT (dedupe_key) DO NOTHING`,
+			m.UserID, m.Template, string(payload), m.DedupeKey, pq.Array(m.Channels))
+		return err
+	}
+	_, err = ex.Exec(fmt.Sprintf(outboxFanout, `SELECT $1::int, $2::text, $3::jsonb, NULLIF($4::text, '')`),
+		m.UserID, m.Template, string(payload), m.DedupeKey)
+	return err
+}
+
+func (r *notificationRepository) Enqueue(m models.OutboxMessage) error {
+	return enqueueNotification(r.db, m)
+}
+
+func (r *notificationRepository) EnqueueScheduleReminders(from, to time.Time) (int64, error) {
+	events := `
+		SELECT c.user_id, 'schedule_reminder',
+		       jsonb_build_object('title', s.title, 'start_time', s.start_time, 'end_time', s.end_time, 'trainer_name', t.name),
+		       'schedule-reminder:' || s.id || ':' || EXTRACT(EPOCH FROM s.start_time)::bigint
+		FROM schedules s
+		JOIN clients c ON c.id = s.client_id
+		JOIN users t ON t.id = s.trainer_id
+		WHERE s.status = 'scheduled' AND c.user_id IS NOT NULL
+		  AND s.start_time > $1 AND s.start_time <= $2`
+	return r.execCount(fmt.Sprintf(outboxFanout, events), from, to)
+}
+
+func (r *notificationRepository) EnqueueAssignmentReminders(from, to time.Time) (int64, error) {
+	events := `
+		SELECT c.user_id, 'assignment_due',
+		       jsonb_build_object('title', a.title, 'due_date', a.due_date),
+		       'assignment-due:' || a.id || ':' || EXTRACT(EPOCH FROM a.due_date)::bigint
+		FROM assignments a
+		JOIN clients c ON c.id = a.client_id
+		WHERE a.status IN ('pending', 'in_progress') AND c.user_id IS NOT NULL
+		  AND a.due_date > $1 AND a.due_date <= $2`
+	return r.execCount(fmt.Sprintf(outboxFanout, events), from, to)
+}
+
+func (r *notificationRepository) execCount(query string, args ...any) (int64, error) {
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *notificationRepository) ClaimOutbox(now time.Time, lease time.Duration, limit int) ([]models.OutboxDelivery, error) {
+	query := `
+		UPDATE notification_outbox o
+		SET attempts = o.attempts + 1, next_attempt_at = $2
+		FROM users u
+		WHERE u.id = o.user_id AND o.id IN (
+			SELECT id FROM notification_outbox
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at, id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING o.id, o.user_id, o.channel, o.template, o.payload, o.attempts, u.name, u.email,
+		          COALESCE((SELECT phone_number FROM clients WHERE user_id = u.id AND phone_number <> '' ORDER BY id LIMIT 1), ''),
+		          COALESCE((SELECT locale FROM notification_preferences WHERE user_id = u.id), '')`
+	rows, err := r.db.Query(query, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []models.OutboxDelivery
+	for rows.Next() {
+		var d models.OutboxDelivery
+		var payload []byte
+		if err := rows.Scan(&d.ID, &d.UserID, &d.Channel, &d.Template, &payload, &d.Attempts,
+			&d.Name, &d.Email, &d.Phone, &d.Locale); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(payload, &d.Payload); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *notificationRepository) MarkOutboxSent(id int) error {
+	_, err := r.db.Exec(`UPDATE notification_outbox SET status = 'sent', sent_at = NOW(), last_error = '' WHERE id = $1`, id)
+	return err
+}
+
+func (r *notificationRepository) MarkOutboxRetry(id int, nextAttempt time.Time, lastError string) error {
+	_, err := r.db.Exec(`UPDATE notification_outbox SET next_attempt_at = $2, last_error = $3 WHERE id = $1`, id, nextAttempt, lastError)
+	return err
+}
+
+func (r *notificationRepository) MarkOutboxDone(id int, status, lastError string) error {
+	_, err := r.db.Exec(`UPDATE notification_outbox SET status = $2, last_error = $3 WHERE id = $1`, id, status, lastError)
+	return err
+}
+
+func (r *notificationRepository) CreateNotification(userID int, template, title, body string) error {
+	_, err := r.db.Exec(`INSERT INTO notifications (user_id, template, title, body) VALUES ($1, $2, $3, $4)`,
+		userID, template, title, body)
+	return err
+}
+
+func (r *notificationRepository) GetNotifications(userID int, unreadOnly bool, limit int) ([]models.Notification, error) {
+	query := `
+		SELECT id, user_id, template, title, body, read_at, created_at
+		FROM notifications
+		WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3`
+	rows, err := r.db.Query(query, userID, unreadOnly, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Template, &n.Title, &n.Body, &n.ReadAt, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+func (r *notificationRepository) CountUnreadNotifications(userID int) (int, error) {
+	var n int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID).Scan(&n)
+	return n, err
+}
+
+func (r *notificationRepository) MarkNotificationRead(userID, id int) error {
+	res, err := r.db.Exec(`UPDATE notifications SET read_at = COALESCE(read_at, NOW()) WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (r *notificationRepository) MarkAllNotificationsRead(userID int) (int64, error) {
+	return r.execCount(`UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`, userID)
+}
+
+func (r *notificationRepository) GetPreferences(userID int) (*models.NotificationPreferences, error) {
+	var p models.NotificationPreferences
+	err := r.db.QueryRow(`
+		SELECT user_id, locale, email_enabled, sms_enabled, updated_at
+		FROM notification_preferences WHERE user_id = $1`, userID,
+	).Scan(&p.UserID, &p.Locale, &p.EmailEnabled, &p.SMSEnabled, &p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *notificationRepository) UpsertPreferences(p *models.NotificationPreferences) error {
+	query := `
+		INSERT INTO notification_preferences (user_id, locale, email_enabled, sms_enabled)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET locale = EXCLUDED.locale, email_enabled = EXCLUDED.email_enabled,
+		    sms_enabled = EXCLUDED.sms_enabled, updated_at = NOW()
+		RETURNING updated_at`
+	return r.db.QueryRow(query, p.UserID, p.Locale, p.EmailEnabled, p.SMSEnabled).Scan(&p.UpdatedAt)
+}
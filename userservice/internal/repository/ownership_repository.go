@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"users/internal/authz"
+)
+
+type ownershipRepository struct {
+	db *sql.DB
+}
+
+// NewOwnershipRepository ใช้กับ middleware.OwnershipGuard เพื่อตรวจว่าใครเป็นเจ้าของข้อมูล
+func NewOwnershipRepository(db *sql.DB) authz.OwnerResolver {
+	return &ownershipRepository{db: db}
+}
+
+func (r *ownershipRepository) ResolveOwner(resource authz.Resource, id int) (*authz.Owner, error) {
+	var query string
+	switch resource {
+	case authz.ResourceClient:
+		query = `SELECT trainer_id, user_id FROM clients WHERE id = $1`
+	case authz.ResourceProgram:
+		query = `SELECT p.trainer_id, c.user_id
+                 FROM programs p LEFT JOIN clients c ON c.id = p.client_id
+                 WHERE p.id = $1`
+	case authz.ResourceSchedule:
+		query = `SELECT s.trainer_id, c.user_id
+                 FROM schedules s LEFT JOIN clients c ON c.id = s.client_id
+                 WHERE s.id = $1`
+	case authz.ResourceSeries:
+		query = `SELECT s.trainer_id, c.user_id
+                 FROM schedule_series s LEFT JOIN clients c ON c.id = s.client_id
+                 WHERE s.id = $1`
+	case authz.ResourceAssignment:
+		query = `SELECT a.trainer_id, c.user_id
+                 FROM assignments a JOIN clients c ON c.id = a.client_id
+                 WHERE a.id = $1`
+	default:
+		return nil, fmt.Errorf("unknown resource type: %s", resource)
+	}
+
+	var owner authz.Owner
+	err := r.db.QueryRow(query, id).Scan(&owner.TrainerID, &owner.ClientUserID)
+	if err == sql.ErrNoRows {
+		return nil, authz.ErrResourceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &owner, nil
+}
@@ -2,21 +2,66 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"users/internal/listing"
 	"users/internal/models"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrProgramNotFound       = errors.New("program not found")
+	ErrProgramClientNotFound = errors.New("client not found")
+
+	ErrProgramExerciseNotFound = errors.New("program exercise not found")
+	// รายการที่ส่งมาเรียงใหม่ไม่ตรงกับท่าฝึกทั้งหมดในวันฝึกนั้น (ขาด/เกิน/ซ้ำ)
+	ErrInvalidExerciseOrder = errors.New("exercise_ids must list every exercise of the day exactly once")
 )
 
 type ProgramRepository interface {
 	// Program CRUD
 	CreateProgram(p *models.Program) error
-	GetProgramsByTrainerID(trainerID int) ([]models.Program, error)
+	// เทรนเนอร์เห็นโปรแกรมที่ตัวเองสร้าง ลูกค้า (role = client) เห็นเฉพาะโปรแกรมของตัวเอง (ตัวกรอง/การเรียงตาม ProgramList)
+	ListPrograms(userID int, role string, p *listing.Params) (*listing.Page[models.Program], error)
 	GetProgramByID(id int) (*models.Program, error)
 	UpdateProgram(p *models.Program) error
 	DeleteProgram(id int, trainerID int) error
 
+	// Template -> โปรแกรมของลูกค้า (copy โปรแกรม + ท่าฝึกทั้งหมดให้ลูกค้าแต่ละคนใน Transaction เดียว)
+	AssignProgram(templateID, trainerID int, clientIDs []int) ([]models.Program, error)
+	GetProgramAssignments(templateID int) ([]models.ProgramAssignment, error)
+
+	// Program Structure: Phase -> Week -> Day (weekID/dayID ต้องอยู่ใน programID ไม่เช่นนั้นคืน Err...NotFound)
+	GetPhases(programID int) ([]models.ProgramPhase, error)
+	GetWeeks(programID int) ([]models.ProgramWeek, error)
+	GetWeek(programID, weekID int) (*models.ProgramWeek, error)
+	GetDay(programID, dayID int) (*models.ProgramDay, error)
+	CreatePhase(ph *models.ProgramPhase) error
+	CreateWeek(w *models.ProgramWeek) error
+	UpdateWeek(w *models.ProgramWeek) error
+	DeleteWeek(programID, weekID int) error
+	MoveWeek(programID, weekID, position int) error
+	DuplicateWeek(programID, weekID int) (int, error)
+	CreateDay(programID int, d *models.ProgramDay) error
+	DeleteDay(programID, dayID int) error
+	MoveDay(programID, dayID, weekID, position int) error
+	DuplicateDay(programID, dayID, weekID int) (int, error)
+
+	// Block (Superset/Circuit/EMOM/AMRAP) ในวันฝึก
+	GetBlock(programID, blockID int) (*models.ProgramBlock, error)
+	GetBlockWithTrainer(blockID int) (*models.ProgramBlock, int, error)
+	CreateBlock(programID int, b *models.ProgramBlock) error
+	UpdateBlock(programID int, b *models.ProgramBlock) error
+	DeleteBlock(programID, blockID int) error
+
 	// Program Exercises
 	AddExercise(pe *models.ProgramExercise) error
 	GetExercisesByProgramID(programID int) ([]models.ProgramExercise, error)
-	DeleteExerciseFromProgram(id int) error
+	UpdateProgramExercise(pe *models.ProgramExercise) error
+	DeleteExerciseFromProgram(programID, id int) error
+	// เรียงท่าฝึกในวันฝึก dayID ใหม่ตาม ids (nil = ท่าที่ไม่อยู่ในวันฝึกใด) ใน Transaction เดียว
+	ReorderProgramExercises(programID int, dayID *int, ids []int) error
 }
 
 type programRepository struct {
@@ -38,48 +83,170 @@ func (r *programRepository) CreateProgram(p *models.Program) error {
 		Scan(&p.ID, &p.CreatedAt)
 }
 
-func (r *programRepository) GetProgramsByTrainerID(trainerID int) ([]models.Program, error) {
-	query := `SELECT id, name, description, trainer_id, client_id, is_template, created_at FROM programs WHERE trainer_id = $1 ORDER BY created_at DESC`
-	rows, err := r.db.Query(query, trainerID)
+// ProgramList ตัวกรอง/การเรียงของ GET /programs
+var ProgramList = &listing.Spec[models.Program]{
+	Sorts: map[string]listing.Sort[models.Program]{
+		"name":       {Column: "name", Key: func(p models.Program) any { return p.Name }},
+		"created_at": {Column: "created_at", Key: func(p models.Program) any { return p.CreatedAt }},
+	},
+	DefaultSort: "-created_at",
+	IDColumn:    "id",
+	ID:          func(p models.Program) int { return p.ID },
+	Filters: map[string]listing.Filter{
+		"q":           {Column: "name", Type: listing.Search},
+		"is_template": {Column: "is_template", Type: listing.Bool},
+		"client_id":   {Column: "client_id", Type: listing.Int},
+	},
+}
+
+func (r *programRepository) ListPrograms(userID int, role string, p *listing.Params) (*listing.Page[models.Program], error) {
+	q := &listing.Query{}
+	if role == "client" {
+		// ลูกค้าที่ Login ด้วยบัญชี role = client (เชื่อมผ่าน clients.user_id)
+		q.Where("client_id IN (SELECT id FROM clients WHERE user_id = " + q.Arg(userID) + ")")
+	} else {
+		q.Where("trainer_id = " + q.Arg(userID))
+	}
+
+	selectFrom := `SELECT id, name, description, trainer_id, client_id, is_template, source_template_id, created_at FROM programs`
+	return listing.Run(r.db, ProgramList, p, selectFrom, q, func(rows *sql.Rows) (models.Program, error) {
+		var pr models.Program
+		err := rows.Scan(&pr.ID, &pr.Name, &pr.Description, &pr.TrainerID, &pr.ClientID, &pr.IsTemplate, &pr.SourceTemplateID, &pr.CreatedAt)
+		return pr, err
+	})
+}
+
+func (r *programRepository) GetProgramByID(id int) (*models.Program, error) {
+	query := `SELECT id, name, description, trainer_id, client_id, is_template, source_template_id, created_at FROM programs WHERE id = $1`
+	var p models.Program
+	err := r.db.QueryRow(query, id).Scan(&p.ID, &p.Name, &p.Description, &p.TrainerID, &p.ClientID, &p.IsTemplate, &p.SourceTemplateID, &p.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return &p, nil
+}
 
-	var programs []models.Program
+// AssignProgram copy Template ให้ลูกค้าทุกคนใน clientIDs (ลูกค้าต้องเป็นของ trainerID = เจ้าของ Template) ถ้าคนใดไม่ผ่าน จะไม่มีการ copy เลย
+func (r *programRepository) AssignProgram(templateID, trainerID int, clientIDs []int) ([]models.Program, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	owned := map[int]bool{}
+	rows, err := tx.Query(`SELECT id FROM clients WHERE id = ANY($1) AND trainer_id = $2`, pq.Array(clientIDs), trainerID)
+	if err != nil {
+		return nil, err
+	}
 	for rows.Next() {
-		var p models.Program
-		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.TrainerID, &p.ClientID, &p.IsTemplate, &p.CreatedAt); err != nil {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		owned[id] = true
+	}
+	rows.Close()
+	for _, id := range clientIDs {
+		if !owned[id] {
+			return nil, fmt.Errorf("%w: %d", ErrProgramClientNotFound, id)
+		}
+	}
+
+	programs := make([]models.Program, 0, len(clientIDs))
+	for _, clientID := range clientIDs {
+		cid := clientID
+		p := models.Program{ClientID: &cid}
+		err := tx.QueryRow(`
+			INSERT INTO programs (name, description, trainer_id, client_id, is_template, source_template_id)
+			SELECT name, description, trainer_id, $2, FALSE, id FROM programs WHERE id = $1
+			RETURNING id, name, description, trainer_id, source_template_id, created_at`, templateID, clientID).
+			Scan(&p.ID, &p.Name, &p.Description, &p.TrainerID, &p.SourceTemplateID, &p.CreatedAt)
+		if err == sql.ErrNoRows {
+			return nil, ErrProgramNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := copyProgramContent(tx, templateID, p.ID); err != nil {
 			return nil, err
 		}
 		programs = append(programs, p)
 	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 	return programs, nil
 }
 
-func (r *programRepository) GetProgramByID(id int) (*models.Program, error) {
-	query := `SELECT id, name, description, trainer_id, client_id, is_template, created_at FROM programs WHERE id = $1`
-	var p models.Program
-	err := r.db.QueryRow(query, id).Scan(&p.ID, &p.Name, &p.Description, &p.TrainerID, &p.ClientID, &p.IsTemplate, &p.CreatedAt)
+// copyProgramExercises copy ท่าฝึกที่ไม่อยู่ในวันฝึกใดของโปรแกรม src ไปยัง dst (คงลำดับเดิม)
+// ท่าฝึกในวันฝึกถูก copy พร้อมวันฝึก (copyDay)
+func copyProgramExercises(tx *sql.Tx, srcID, dstID int) error {
+	_, err := tx.Exec(`
+		INSERT INTO program_exercises (program_id, exercise_id, sets, reps, duration_seconds, rest_seconds, notes, "order",
+		                               reps_min, reps_max, target_rpe, target_rir, percent_1rm, tempo, load_kg)
+		SELECT $2, exercise_id, sets, reps, duration_seconds, rest_seconds, notes, "order",
+		       reps_min, reps_max, target_rpe, target_rir, percent_1rm, tempo, load_kg
+		FROM program_exercises WHERE program_id = $1 AND day_id IS NULL
+		ORDER BY "order", id`, srcID, dstID)
+	return err
+}
+
+// GetProgramAssignments ลูกค้าที่ได้รับโปรแกรมจาก Template นี้ (ล่าสุดก่อน)
+func (r *programRepository) GetProgramAssignments(templateID int) ([]models.ProgramAssignment, error) {
+	query := `SELECT p.id, c.id, c.name, p.created_at
+              FROM programs p
+              JOIN clients c ON c.id = p.client_id
+              WHERE p.source_template_id = $1
+              ORDER BY p.created_at DESC, p.id DESC`
+	rows, err := r.db.Query(query, templateID)
 	if err != nil {
 		return nil, err
 	}
-	return &p, nil
+	defer rows.Close()
+
+	assignments := []models.ProgramAssignment{}
+	for rows.Next() {
+		var a models.ProgramAssignment
+		if err := rows.Scan(&a.ProgramID, &a.ClientID, &a.ClientName, &a.AssignedAt); err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, a)
+	}
+	return assignments, rows.Err()
 }
 
 // --- Program Exercises ---
 
 func (r *programRepository) AddExercise(pe *models.ProgramExercise) error {
 	query := `
-        INSERT INTO program_exercises (program_id, exercise_id, sets, reps, duration_seconds, rest_seconds, notes, "order")
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        INSERT INTO program_exercises (program_id, day_id, block_id, exercise_id, sets, reps, duration_seconds, rest_seconds, notes, "order",
+                                       reps_min, reps_max, target_rpe, target_rir, percent_1rm, tempo, load_kg)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
         RETURNING id`
-	return r.db.QueryRow(query, pe.ProgramID, pe.ExerciseID, pe.Sets, pe.Reps, pe.DurationSeconds, pe.RestSeconds, pe.Notes, pe.Order).Scan(&pe.ID)
+	err := r.db.QueryRow(query, pe.ProgramID, pe.DayID, pe.BlockID, pe.ExerciseID, pe.Sets, pe.Reps, pe.DurationSeconds, pe.RestSeconds, pe.Notes, pe.Order,
+		pe.RepsMin, pe.RepsMax, pe.TargetRPE, pe.TargetRIR, pe.Percent1RM, pe.Tempo, pe.LoadKg).Scan(&pe.ID)
+	return exerciseRefError(err)
+}
+
+// exerciseRefError 23503 = foreign_key_violation (exercise_id ไม่มีอยู่ในคลังท่าฝึก)
+func exerciseRefError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return ErrExerciseNotFound
+	}
+	return err
 }
 
 func (r *programRepository) GetExercisesByProgramID(programID int) ([]models.ProgramExercise, error) {
-	query := `SELECT id, program_id, exercise_id, sets, reps, duration_seconds, rest_seconds, notes, "order" 
-              FROM program_exercises WHERE program_id = $1 ORDER BY "order" ASC`
+	query := `SELECT pe.id, pe.program_id, pe.day_id, pe.block_id, pe.exercise_id, pe.sets, pe.reps, pe.duration_seconds, pe.rest_seconds, pe.notes, pe."order",
+                     pe.reps_min, pe.reps_max, pe.target_rpe, pe.target_rir, pe.percent_1rm, pe.tempo, pe.load_kg,
+                     e.id, e.trainer_id, e.name, e.category, e.primary_muscles, e.secondary_muscles, e.equipment, e.instructions, e.created_at, e.updated_at
+              FROM program_exercises pe
+              JOIN exercises e ON e.id = pe.exercise_id
+              WHERE pe.program_id = $1 ORDER BY pe."order" ASC, pe.id ASC`
 	rows, err := r.db.Query(query, programID)
 	if err != nil {
 		return nil, err
@@ -89,10 +256,21 @@ func (r *programRepository) GetExercisesByProgramID(programID int) ([]models.Pro
 	var exercises []models.ProgramExercise
 	for rows.Next() {
 		var pe models.ProgramExercise
-		rows.Scan(&pe.ID, &pe.ProgramID, &pe.ExerciseID, &pe.Sets, &pe.Reps, &pe.DurationSeconds, &pe.RestSeconds, &pe.Notes, &pe.Order)
+		var e models.Exercise
+		if err := rows.Scan(
+			&pe.ID, &pe.ProgramID, &pe.DayID, &pe.BlockID, &pe.ExerciseID, &pe.Sets, &pe.Reps, &pe.DurationSeconds, &pe.RestSeconds, &pe.Notes, &pe.Order,
+			&pe.RepsMin, &pe.RepsMax, &pe.TargetRPE, &pe.TargetRIR, &pe.Percent1RM, &pe.Tempo, &pe.LoadKg,
+			&e.ID, &e.TrainerID, &e.Name, &e.Category,
+			(*pq.StringArray)(&e.PrimaryMuscles), (*pq.StringArray)(&e.SecondaryMuscles),
+			&e.Equipment, &e.Instructions, &e.CreatedAt, &e.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		e.IsGlobal = e.TrainerID == nil
+		pe.Exercise = &e
 		exercises = append(exercises, pe)
 	}
-	return exercises, nil
+	return exercises, rows.Err()
 }
 
 func (r *programRepository) UpdateProgram(p *models.Program) error {
@@ -139,12 +317,30 @@ func (r *programRepository) DeleteProgram(id int, trainerID int) error {
 	return nil
 }
 
-// --- Program Exercises Delete ---
+// --- Program Exercises Update / Delete / Reorder ---
+
+// UpdateProgramExercise แก้ไขท่าฝึกในโปรแกรม (ย้ายวันฝึกได้ด้วย day_id)
+func (r *programRepository) UpdateProgramExercise(pe *models.ProgramExercise) error {
+	query := `
+		UPDATE program_exercises
+		SET day_id = $3, block_id = $4, exercise_id = $5, sets = $6, reps = $7, duration_seconds = $8, rest_seconds = $9, notes = $10, "order" = $11,
+		    reps_min = $12, reps_max = $13, target_rpe = $14, target_rir = $15, percent_1rm = $16, tempo = $17, load_kg = $18
+		WHERE id = $1 AND program_id = $2`
+	res, err := r.db.Exec(query, pe.ID, pe.ProgramID, pe.DayID, pe.BlockID, pe.ExerciseID, pe.Sets, pe.Reps, pe.DurationSeconds, pe.RestSeconds, pe.Notes, pe.Order,
+		pe.RepsMin, pe.RepsMax, pe.TargetRPE, pe.TargetRIR, pe.Percent1RM, pe.Tempo, pe.LoadKg)
+	if err != nil {
+		return exerciseRefError(err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return ErrProgramExerciseNotFound
+	}
+	return nil
+}
 
-func (r *programRepository) DeleteExerciseFromProgram(id int) error {
-	query := `DELETE FROM program_exercises WHERE id=$1`
+func (r *programRepository) DeleteExerciseFromProgram(programID, id int) error {
+	query := `DELETE FROM program_exercises WHERE id=$1 AND program_id=$2`
 
-	res, err := r.db.Exec(query, id)
+	res, err := r.db.Exec(query, id, programID)
 	if err != nil {
 		return err
 	}
@@ -154,8 +350,51 @@ func (r *programRepository) DeleteExerciseFromProgram(id int) error {
 		return err
 	}
 	if rows == 0 {
-		return sql.ErrNoRows
+		return ErrProgramExerciseNotFound
 	}
 
 	return nil
 }
+
+func (r *programRepository) ReorderProgramExercises(programID int, dayID *int, ids []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockProgram(tx, programID); err != nil {
+		return err
+	}
+	if dayID != nil {
+		if _, err := dayWeek(tx, programID, *dayID); err != nil {
+			return err
+		}
+	}
+	current, err := queryIDs(tx, `
+		SELECT id FROM program_exercises
+		WHERE program_id = $1 AND day_id IS NOT DISTINCT FROM $2`, programID, dayID)
+	if err != nil {
+		return err
+	}
+
+	// ต้องเป็นชุดเดียวกันพอดี (กันการส่งรายการเก่าที่ขาดท่าที่เพิ่งเพิ่ม)
+	remaining := map[int]bool{}
+	for _, id := range current {
+		remaining[id] = true
+	}
+	if len(ids) != len(current) {
+		return ErrInvalidExerciseOrder
+	}
+	for _, id := range ids {
+		if !remaining[id] {
+			return ErrInvalidExerciseOrder
+		}
+		delete(remaining, id)
+	}
+
+	if err := renumber(tx, "program_exercises", ids); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
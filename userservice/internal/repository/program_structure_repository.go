@@ -0,0 +1,783 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"users/internal/models"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrProgramPhaseNotFound = errors.New("program phase not found")
+	ErrProgramWeekNotFound  = errors.New("program week not found")
+	ErrProgramDayNotFound   = errors.New("program day not found")
+	ErrProgramBlockNotFound = errors.New("program block not found")
+)
+
+// --- Program Structure (Phase / Week / Day) ---
+// ทุกการแก้ลำดับ lock แถว programs ไว้ก่อน เพื่อไม่ให้การย้าย/copy พร้อมกันทำให้ "order" ซ้ำ
+
+func (r *programRepository) GetPhases(programID int) ([]models.ProgramPhase, error) {
+	query := `SELECT id, program_id, name, description, "order" FROM program_phases WHERE program_id = $1 ORDER BY "order", id`
+	rows, err := r.db.Query(query, programID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	phases := []models.ProgramPhase{}
+	for rows.Next() {
+		var ph models.ProgramPhase
+		if err := rows.Scan(&ph.ID, &ph.ProgramID, &ph.Name, &ph.Description, &ph.Order); err != nil {
+			return nil, err
+		}
+		ph.Weeks = []models.ProgramWeek{}
+		phases = append(phases, ph)
+	}
+	return phases, rows.Err()
+}
+
+// GetWeeks สัปดาห์ทั้งหมดของโปรแกรมตามลำดับ พร้อมวันฝึกและ Block (ยังไม่ใส่ท่าฝึก)
+func (r *programRepository) GetWeeks(programID int) ([]models.ProgramWeek, error) {
+	query := `SELECT ` + programWeekColumns + ` FROM program_weeks WHERE program_id = $1 ORDER BY "order", id`
+	rows, err := r.db.Query(query, programID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	weeks := []models.ProgramWeek{}
+	index := map[int]int{}
+	for rows.Next() {
+		var w models.ProgramWeek
+		if err := scanProgramWeek(rows, &w); err != nil {
+			return nil, err
+		}
+		w.Days = []models.ProgramDay{}
+		index[w.ID] = len(weeks)
+		weeks = append(weeks, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	dayRows, err := r.db.Query(`
+		SELECT d.id, d.week_id, d.name, d."order", d.notes
+		FROM program_days d JOIN program_weeks w ON w.id = d.week_id
+		WHERE w.program_id = $1
+		ORDER BY d."order", d.id`, programID)
+	if err != nil {
+		return nil, err
+	}
+	defer dayRows.Close()
+
+	type dayPos struct{ week, day int }
+	days := map[int]dayPos{}
+	for dayRows.Next() {
+		var d models.ProgramDay
+		if err := dayRows.Scan(&d.ID, &d.WeekID, &d.Name, &d.Order, &d.Notes); err != nil {
+			return nil, err
+		}
+		d.Exercises = []models.ProgramExercise{}
+		d.Blocks = []models.ProgramBlock{}
+		if i, ok := index[d.WeekID]; ok {
+			days[d.ID] = dayPos{week: i, day: len(weeks[i].Days)}
+			weeks[i].Days = append(weeks[i].Days, d)
+		}
+	}
+	if err := dayRows.Err(); err != nil {
+		return nil, err
+	}
+
+	blockRows, err := r.db.Query(`
+		SELECT `+programBlockColumns("b")+`
+		FROM program_blocks b
+		JOIN program_days d ON d.id = b.day_id
+		JOIN program_weeks w ON w.id = d.week_id
+		WHERE w.program_id = $1
+		ORDER BY b."order", b.id`, programID)
+	if err != nil {
+		return nil, err
+	}
+	defer blockRows.Close()
+
+	for blockRows.Next() {
+		var b models.ProgramBlock
+		if err := scanProgramBlock(blockRows, &b); err != nil {
+			return nil, err
+		}
+		b.Exercises = []models.ProgramExercise{}
+		if pos, ok := days[b.DayID]; ok {
+			day := &weeks[pos.week].Days[pos.day]
+			day.Blocks = append(day.Blocks, b)
+		}
+	}
+	return weeks, blockRows.Err()
+}
+
+func (r *programRepository) GetWeek(programID, weekID int) (*models.ProgramWeek, error) {
+	query := `SELECT ` + programWeekColumns + ` FROM program_weeks WHERE id = $1 AND program_id = $2`
+	var w models.ProgramWeek
+	err := scanProgramWeek(r.db.QueryRow(query, weekID, programID), &w)
+	if err == sql.ErrNoRows {
+		return nil, ErrProgramWeekNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (r *programRepository) GetDay(programID, dayID int) (*models.ProgramDay, error) {
+	query := `SELECT d.id, d.week_id, d.name, d."order", d.notes
+              FROM program_days d JOIN program_weeks w ON w.id = d.week_id
+              WHERE d.id = $1 AND w.program_id = $2`
+	var d models.ProgramDay
+	err := r.db.QueryRow(query, dayID, programID).Scan(&d.ID, &d.WeekID, &d.Name, &d.Order, &d.Notes)
+	if err == sql.ErrNoRows {
+		return nil, ErrProgramDayNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *programRepository) GetBlock(programID, blockID int) (*models.ProgramBlock, error) {
+	query := `SELECT ` + programBlockColumns("b") + `
+              FROM program_blocks b
+              JOIN program_days d ON d.id = b.day_id
+              JOIN program_weeks w ON w.id = d.week_id
+              WHERE b.id = $1 AND w.program_id = $2`
+	var b models.ProgramBlock
+	err := scanProgramBlock(r.db.QueryRow(query, blockID, programID), &b)
+	if err == sql.ErrNoRows {
+		return nil, ErrProgramBlockNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// GetBlockWithTrainer คืน Block พร้อมเทรนเนอร์เจ้าของโปรแกรม (ใช้ตรวจ block_id ตอนบันทึกผลการฝึก)
+func (r *programRepository) GetBlockWithTrainer(blockID int) (*models.ProgramBlock, int, error) {
+	query := `SELECT ` + programBlockColumns("b") + `, p.trainer_id
+              FROM program_blocks b
+              JOIN program_days d ON d.id = b.day_id
+              JOIN program_weeks w ON w.id = d.week_id
+              JOIN programs p ON p.id = w.program_id
+              WHERE b.id = $1`
+	var b models.ProgramBlock
+	var trainerID int
+	err := r.db.QueryRow(query, blockID).Scan(&b.ID, &b.DayID, &b.BlockType, &b.Name, &b.Rounds, &b.RestSeconds,
+		&b.DurationSeconds, &b.Order, &b.Notes, &trainerID)
+	if err == sql.ErrNoRows {
+		return nil, 0, ErrProgramBlockNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return &b, trainerID, nil
+}
+
+// CreatePhase เพิ่ม Phase ต่อท้าย
+func (r *programRepository) CreatePhase(ph *models.ProgramPhase) error {
+	query := `
+		INSERT INTO program_phases (program_id, name, description, "order")
+		SELECT $1, $2, $3, COALESCE(MAX("order"), 0) + 1 FROM program_phases WHERE program_id = $1
+		RETURNING id, "order"`
+	return r.db.QueryRow(query, ph.ProgramID, ph.Name, ph.Description).Scan(&ph.ID, &ph.Order)
+}
+
+// CreateWeek เพิ่มสัปดาห์ต่อท้ายโปรแกรม
+func (r *programRepository) CreateWeek(w *models.ProgramWeek) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockProgram(tx, w.ProgramID); err != nil {
+		return err
+	}
+	if err := checkPhase(tx, w.ProgramID, w.PhaseID); err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO program_weeks (program_id, phase_id, name, "order", sets_override, reps_override, intensity_pct, notes)
+		SELECT $1, $2, $3, COUNT(*) + 1, $4, $5, $6, $7 FROM program_weeks WHERE program_id = $1
+		RETURNING id, "order"`
+	err = tx.QueryRow(query, w.ProgramID, w.PhaseID, w.Name, w.SetsOverride, w.RepsOverride, w.IntensityPct, w.Notes).
+		Scan(&w.ID, &w.Order)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateWeek แก้ชื่อ/Phase/Override ของสัปดาห์ (ไม่เปลี่ยนลำดับ; ใช้ MoveWeek)
+func (r *programRepository) UpdateWeek(w *models.ProgramWeek) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkPhase(tx, w.ProgramID, w.PhaseID); err != nil {
+		return err
+	}
+	query := `
+		UPDATE program_weeks
+		SET phase_id = $3, name = $4, sets_override = $5, reps_override = $6, intensity_pct = $7, notes = $8
+		WHERE id = $1 AND program_id = $2
+		RETURNING "order"`
+	err = tx.QueryRow(query, w.ID, w.ProgramID, w.PhaseID, w.Name, w.SetsOverride, w.RepsOverride, w.IntensityPct, w.Notes).
+		Scan(&w.Order)
+	if err == sql.ErrNoRows {
+		return ErrProgramWeekNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteWeek ลบสัปดาห์ (วันฝึกและท่าฝึกในสัปดาห์ถูกลบตาม) แล้วเรียงลำดับสัปดาห์ที่เหลือใหม่
+func (r *programRepository) DeleteWeek(programID, weekID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockProgram(tx, programID); err != nil {
+		return err
+	}
+	res, err := tx.Exec(`DELETE FROM program_weeks WHERE id = $1 AND program_id = $2`, weekID, programID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return ErrProgramWeekNotFound
+	}
+	ids, err := queryIDs(tx, `SELECT id FROM program_weeks WHERE program_id = $1 ORDER BY "order", id`, programID)
+	if err != nil {
+		return err
+	}
+	if err := renumber(tx, "program_weeks", ids); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MoveWeek ย้ายสัปดาห์ไปอยู่ลำดับที่ position (เริ่มที่ 1; เกินจำนวน = ท้ายสุด)
+func (r *programRepository) MoveWeek(programID, weekID, position int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockProgram(tx, programID); err != nil {
+		return err
+	}
+	ids, err := queryIDs(tx, `SELECT id FROM program_weeks WHERE program_id = $1 ORDER BY "order", id`, programID)
+	if err != nil {
+		return err
+	}
+	ids, ok := moveID(ids, weekID, position)
+	if !ok {
+		return ErrProgramWeekNotFound
+	}
+	if err := renumber(tx, "program_weeks", ids); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DuplicateWeek copy สัปดาห์พร้อมวันฝึกและท่าฝึกทั้งหมด ไปไว้ต่อจากสัปดาห์เดิม คืน id ของสัปดาห์ใหม่
+func (r *programRepository) DuplicateWeek(programID, weekID int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := lockProgram(tx, programID); err != nil {
+		return 0, err
+	}
+	ids, err := queryIDs(tx, `SELECT id FROM program_weeks WHERE program_id = $1 ORDER BY "order", id`, programID)
+	if err != nil {
+		return 0, err
+	}
+	pos := indexOf(ids, weekID)
+	if pos < 0 {
+		return 0, ErrProgramWeekNotFound
+	}
+
+	var phaseID *int
+	if err := tx.QueryRow(`SELECT phase_id FROM program_weeks WHERE id = $1`, weekID).Scan(&phaseID); err != nil {
+		return 0, err
+	}
+	newID, err := copyWeek(tx, weekID, programID, phaseID, pos+2)
+	if err != nil {
+		return 0, err
+	}
+
+	ids = append(ids[:pos+1], append([]int{newID}, ids[pos+1:]...)...)
+	if err := renumber(tx, "program_weeks", ids); err != nil {
+		return 0, err
+	}
+	return newID, tx.Commit()
+}
+
+// CreateDay เพิ่มวันฝึกต่อท้ายสัปดาห์ (สัปดาห์ต้องอยู่ในโปรแกรมนี้)
+func (r *programRepository) CreateDay(programID int, d *models.ProgramDay) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockProgram(tx, programID); err != nil {
+		return err
+	}
+	if err := checkWeek(tx, programID, d.WeekID); err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO program_days (week_id, name, "order", notes)
+		SELECT $1, $2, COUNT(*) + 1, $3 FROM program_days WHERE week_id = $1
+		RETURNING id, "order"`
+	if err := tx.QueryRow(query, d.WeekID, d.Name, d.Notes).Scan(&d.ID, &d.Order); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteDay ลบวันฝึก (ท่าฝึกในวันนั้นถูกลบตาม) แล้วเรียงลำดับวันที่เหลือในสัปดาห์ใหม่
+func (r *programRepository) DeleteDay(programID, dayID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockProgram(tx, programID); err != nil {
+		return err
+	}
+	var weekID int
+	err = tx.QueryRow(`
+		DELETE FROM program_days d USING program_weeks w
+		WHERE d.id = $1 AND w.id = d.week_id AND w.program_id = $2
+		RETURNING d.week_id`, dayID, programID).Scan(&weekID)
+	if err == sql.ErrNoRows {
+		return ErrProgramDayNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := renumberDays(tx, weekID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MoveDay ย้ายวันฝึกไปยังสัปดาห์ weekID (สัปดาห์เดิมหรือสัปดาห์อื่นในโปรแกรมเดียวกัน) ที่ลำดับ position
+func (r *programRepository) MoveDay(programID, dayID, weekID, position int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockProgram(tx, programID); err != nil {
+		return err
+	}
+	fromWeekID, err := dayWeek(tx, programID, dayID)
+	if err != nil {
+		return err
+	}
+	if err := checkWeek(tx, programID, weekID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE program_days SET week_id = $2 WHERE id = $1`, dayID, weekID); err != nil {
+		return err
+	}
+	ids, err := queryIDs(tx, `SELECT id FROM program_days WHERE week_id = $1 AND id <> $2 ORDER BY "order", id`, weekID, dayID)
+	if err != nil {
+		return err
+	}
+	ids, _ = moveID(append(ids, dayID), dayID, position)
+	if err := renumber(tx, "program_days", ids); err != nil {
+		return err
+	}
+	if fromWeekID != weekID {
+		if err := renumberDays(tx, fromWeekID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DuplicateDay copy วันฝึกพร้อมท่าฝึกไปยังสัปดาห์ weekID
+// (สัปดาห์เดิม = ต่อจากวันเดิม, สัปดาห์อื่น = ต่อท้าย) คืน id ของวันใหม่
+func (r *programRepository) DuplicateDay(programID, dayID, weekID int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := lockProgram(tx, programID); err != nil {
+		return 0, err
+	}
+	fromWeekID, err := dayWeek(tx, programID, dayID)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkWeek(tx, programID, weekID); err != nil {
+		return 0, err
+	}
+
+	ids, err := queryIDs(tx, `SELECT id FROM program_days WHERE week_id = $1 ORDER BY "order", id`, weekID)
+	if err != nil {
+		return 0, err
+	}
+	pos := len(ids)
+	if fromWeekID == weekID {
+		pos = indexOf(ids, dayID) + 1
+	}
+	newID, err := copyDay(tx, dayID, weekID, pos+1)
+	if err != nil {
+		return 0, err
+	}
+
+	ids = append(ids[:pos], append([]int{newID}, ids[pos:]...)...)
+	if err := renumber(tx, "program_days", ids); err != nil {
+		return 0, err
+	}
+	return newID, tx.Commit()
+}
+
+// CreateBlock เพิ่ม Block ต่อท้ายวันฝึก (วันฝึกต้องอยู่ในโปรแกรมนี้)
+func (r *programRepository) CreateBlock(programID int, b *models.ProgramBlock) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockProgram(tx, programID); err != nil {
+		return err
+	}
+	if _, err := dayWeek(tx, programID, b.DayID); err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO program_blocks (day_id, block_type, name, rounds, rest_seconds, duration_seconds, "order", notes)
+		SELECT $1, $2, $3, $4, $5, $6, COUNT(*) + 1, $7 FROM program_blocks WHERE day_id = $1
+		RETURNING id, "order"`
+	err = tx.QueryRow(query, b.DayID, b.BlockType, b.Name, b.Rounds, b.RestSeconds, b.DurationSeconds, b.Notes).
+		Scan(&b.ID, &b.Order)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateBlock แก้ชนิด/จำนวนรอบ/เวลาพักของ Block (ย้ายวันฝึกไม่ได้)
+func (r *programRepository) UpdateBlock(programID int, b *models.ProgramBlock) error {
+	query := `
+		UPDATE program_blocks b
+		SET block_type = $3, name = $4, rounds = $5, rest_seconds = $6, duration_seconds = $7, notes = $8
+		FROM program_days d JOIN program_weeks w ON w.id = d.week_id
+		WHERE b.id = $1 AND d.id = b.day_id AND w.program_id = $2
+		RETURNING b.day_id, b."order"`
+	err := r.db.QueryRow(query, b.ID, programID, b.BlockType, b.Name, b.Rounds, b.RestSeconds, b.DurationSeconds, b.Notes).
+		Scan(&b.DayID, &b.Order)
+	if err == sql.ErrNoRows {
+		return ErrProgramBlockNotFound
+	}
+	return err
+}
+
+// DeleteBlock ลบ Block (ท่าฝึกใน Block กลายเป็นท่าเดี่ยวในวันฝึกเดิม)
+func (r *programRepository) DeleteBlock(programID, blockID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockProgram(tx, programID); err != nil {
+		return err
+	}
+	var dayID int
+	err = tx.QueryRow(`
+		DELETE FROM program_blocks b USING program_days d, program_weeks w
+		WHERE b.id = $1 AND d.id = b.day_id AND w.id = d.week_id AND w.program_id = $2
+		RETURNING b.day_id`, blockID, programID).Scan(&dayID)
+	if err == sql.ErrNoRows {
+		return ErrProgramBlockNotFound
+	}
+	if err != nil {
+		return err
+	}
+	ids, err := queryIDs(tx, `SELECT id FROM program_blocks WHERE day_id = $1 ORDER BY "order", id`, dayID)
+	if err != nil {
+		return err
+	}
+	if err := renumber(tx, "program_blocks", ids); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// --- helpers ---
+
+func programBlockColumns(alias string) string {
+	a := alias + "."
+	return a + "id, " + a + "day_id, " + a + "block_type, " + a + "name, " + a + "rounds, " + a + "rest_seconds, " +
+		a + "duration_seconds, " + a + `"order", ` + a + "notes"
+}
+
+func scanProgramBlock(row rowScanner, b *models.ProgramBlock) error {
+	return row.Scan(&b.ID, &b.DayID, &b.BlockType, &b.Name, &b.Rounds, &b.RestSeconds, &b.DurationSeconds, &b.Order, &b.Notes)
+}
+
+const programWeekColumns = `id, program_id, phase_id, name, "order", sets_override, reps_override, intensity_pct, notes`
+
+func scanProgramWeek(row rowScanner, w *models.ProgramWeek) error {
+	return row.Scan(&w.ID, &w.ProgramID, &w.PhaseID, &w.Name, &w.Order, &w.SetsOverride, &w.RepsOverride, &w.IntensityPct, &w.Notes)
+}
+
+func lockProgram(tx *sql.Tx, programID int) error {
+	var id int
+	err := tx.QueryRow(`SELECT id FROM programs WHERE id = $1 FOR UPDATE`, programID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return ErrProgramNotFound
+	}
+	return err
+}
+
+// checkPhase Phase ต้องอยู่ในโปรแกรมเดียวกัน (nil = ไม่อยู่ใน Phase ใด)
+func checkPhase(tx *sql.Tx, programID int, phaseID *int) error {
+	if phaseID == nil {
+		return nil
+	}
+	var id int
+	err := tx.QueryRow(`SELECT id FROM program_phases WHERE id = $1 AND program_id = $2`, *phaseID, programID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return ErrProgramPhaseNotFound
+	}
+	return err
+}
+
+func checkWeek(tx *sql.Tx, programID, weekID int) error {
+	var id int
+	err := tx.QueryRow(`SELECT id FROM program_weeks WHERE id = $1 AND program_id = $2`, weekID, programID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return ErrProgramWeekNotFound
+	}
+	return err
+}
+
+// dayWeek คืนสัปดาห์ของวันฝึก (วันฝึกต้องอยู่ในโปรแกรมนี้)
+func dayWeek(tx *sql.Tx, programID, dayID int) (int, error) {
+	var weekID int
+	err := tx.QueryRow(`
+		SELECT d.week_id FROM program_days d JOIN program_weeks w ON w.id = d.week_id
+		WHERE d.id = $1 AND w.program_id = $2`, dayID, programID).Scan(&weekID)
+	if err == sql.ErrNoRows {
+		return 0, ErrProgramDayNotFound
+	}
+	return weekID, err
+}
+
+func queryIDs(tx *sql.Tx, query string, args ...interface{}) ([]int, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// renumber ตั้ง "order" = ลำดับใน ids (เริ่มที่ 1); table มาจากค่าคงที่ในไฟล์นี้เท่านั้น
+func renumber(tx *sql.Tx, table string, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(`
+		UPDATE `+table+` t SET "order" = x.ord
+		FROM unnest($1::int[]) WITH ORDINALITY AS x(id, ord)
+		WHERE t.id = x.id`, pq.Array(ids))
+	return err
+}
+
+func renumberDays(tx *sql.Tx, weekID int) error {
+	ids, err := queryIDs(tx, `SELECT id FROM program_days WHERE week_id = $1 ORDER BY "order", id`, weekID)
+	if err != nil {
+		return err
+	}
+	return renumber(tx, "program_days", ids)
+}
+
+// moveID ย้าย id ไปไว้ที่ลำดับ position (เริ่มที่ 1) คืน false ถ้าไม่มี id ใน ids
+func moveID(ids []int, id, position int) ([]int, bool) {
+	i := indexOf(ids, id)
+	if i < 0 {
+		return ids, false
+	}
+	rest := append(append([]int{}, ids[:i]...), ids[i+1:]...)
+	pos := position - 1
+	if pos < 0 {
+		pos = 0
+	}
+	if pos > len(rest) {
+		pos = len(rest)
+	}
+	return append(rest[:pos], append([]int{id}, rest[pos:]...)...), true
+}
+
+func indexOf(ids []int, id int) int {
+	for i, v := range ids {
+		if v == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// copyWeek copy สัปดาห์ + วันฝึก + ท่าฝึก ไปยังโปรแกรม dstProgramID (ใช้ทั้งตอน Duplicate และตอน assign Template)
+func copyWeek(tx *sql.Tx, srcWeekID, dstProgramID int, phaseID *int, order int) (int, error) {
+	var newID int
+	err := tx.QueryRow(`
+		INSERT INTO program_weeks (program_id, phase_id, name, "order", sets_override, reps_override, intensity_pct, notes)
+		SELECT $2, $3, name, $4, sets_override, reps_override, intensity_pct, notes
+		FROM program_weeks WHERE id = $1
+		RETURNING id`, srcWeekID, dstProgramID, phaseID, order).Scan(&newID)
+	if err != nil {
+		return 0, err
+	}
+
+	days, err := queryIDs(tx, `SELECT id FROM program_days WHERE week_id = $1 ORDER BY "order", id`, srcWeekID)
+	if err != nil {
+		return 0, err
+	}
+	for i, dayID := range days {
+		if _, err := copyDay(tx, dayID, newID, i+1); err != nil {
+			return 0, err
+		}
+	}
+	return newID, nil
+}
+
+// copyDay copy วันฝึก + Block + ท่าฝึก ไปยังสัปดาห์ dstWeekID
+func copyDay(tx *sql.Tx, srcDayID, dstWeekID, order int) (int, error) {
+	var newID int
+	err := tx.QueryRow(`
+		INSERT INTO program_days (week_id, name, "order", notes)
+		SELECT $2, name, $3, notes FROM program_days WHERE id = $1
+		RETURNING id`, srcDayID, dstWeekID, order).Scan(&newID)
+	if err != nil {
+		return 0, err
+	}
+
+	// copy Block แล้วจำ id เก่า -> ใหม่ ไว้ผูกท่าฝึก
+	srcBlocks, err := queryIDs(tx, `SELECT id FROM program_blocks WHERE day_id = $1 ORDER BY "order", id`, srcDayID)
+	if err != nil {
+		return 0, err
+	}
+	dstBlocks := make([]int, len(srcBlocks))
+	for i, blockID := range srcBlocks {
+		err := tx.QueryRow(`
+			INSERT INTO program_blocks (day_id, block_type, name, rounds, rest_seconds, duration_seconds, "order", notes)
+			SELECT $2, block_type, name, rounds, rest_seconds, duration_seconds, "order", notes
+			FROM program_blocks WHERE id = $1
+			RETURNING id`, blockID, newID).Scan(&dstBlocks[i])
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO program_exercises (program_id, day_id, block_id, exercise_id, sets, reps, duration_seconds, rest_seconds, notes, "order",
+		                               reps_min, reps_max, target_rpe, target_rir, percent_1rm, tempo, load_kg)
+		SELECT w.program_id, $2, m.new_id, pe.exercise_id, pe.sets, pe.reps, pe.duration_seconds, pe.rest_seconds, pe.notes, pe."order",
+		       pe.reps_min, pe.reps_max, pe.target_rpe, pe.target_rir, pe.percent_1rm, pe.tempo, pe.load_kg
+		FROM program_exercises pe
+		LEFT JOIN unnest($3::int[], $4::int[]) AS m(old_id, new_id) ON m.old_id = pe.block_id
+		CROSS JOIN program_days d JOIN program_weeks w ON w.id = d.week_id
+		WHERE pe.day_id = $1 AND d.id = $2
+		ORDER BY pe."order", pe.id`, srcDayID, newID, pq.Array(srcBlocks), pq.Array(dstBlocks))
+	return newID, err
+}
+
+// copyProgramContent copy ทั้งโครงสร้าง (Phase/Week/Day) และท่าฝึกที่ไม่อยู่ในวันฝึก จากโปรแกรม src ไป dst
+func copyProgramContent(tx *sql.Tx, srcID, dstID int) error {
+	phaseIDs, err := queryIDs(tx, `SELECT id FROM program_phases WHERE program_id = $1 ORDER BY "order", id`, srcID)
+	if err != nil {
+		return err
+	}
+	newPhase := map[int]int{}
+	for _, id := range phaseIDs {
+		var newID int
+		err := tx.QueryRow(`
+			INSERT INTO program_phases (program_id, name, description, "order")
+			SELECT $2, name, description, "order" FROM program_phases WHERE id = $1
+			RETURNING id`, id, dstID).Scan(&newID)
+		if err != nil {
+			return err
+		}
+		newPhase[id] = newID
+	}
+
+	// อ่านสัปดาห์ให้ครบก่อน (ใน Transaction เดียวกันจะเปิด Query ซ้อนขณะยังอ่าน rows อยู่ไม่ได้)
+	type weekRef struct {
+		id, order int
+		phaseID   *int
+	}
+	rows, err := tx.Query(`SELECT id, "order", phase_id FROM program_weeks WHERE program_id = $1 ORDER BY "order", id`, srcID)
+	if err != nil {
+		return err
+	}
+	var weeks []weekRef
+	for rows.Next() {
+		var w weekRef
+		if err := rows.Scan(&w.id, &w.order, &w.phaseID); err != nil {
+			rows.Close()
+			return err
+		}
+		weeks = append(weeks, w)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, w := range weeks {
+		var phaseID *int
+		if w.phaseID != nil {
+			id := newPhase[*w.phaseID]
+			phaseID = &id
+		}
+		if _, err := copyWeek(tx, w.id, dstID, phaseID, w.order); err != nil {
+			return err
+		}
+	}
+	return copyProgramExercises(tx, srcID, dstID)
+}
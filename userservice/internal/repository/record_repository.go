@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+	"users/internal/models"
+
+	"github.com/lib/pq"
+)
+
+type PersonalRecordRepository interface {
+	// จำนวนครั้งสูงสุดในแต่ละน้ำหนักของลูกค้า (เฉพาะท่าที่ระบุ) ใช้ตรวจหา PR ใหม่
+	// before != nil นับเฉพาะ Session ที่เริ่มก่อนเวลานั้น (ตรวจ PR ของ Session ย้อนหลัง)
+	GetRepMaxes(clientID int, exerciseIDs []int, before *time.Time) ([]models.RepMax, error)
+	// ประวัติ PR ของลูกค้า (exerciseID = nil คือทุกท่า, recordType = "" คือทุกประเภท) ล่าสุดก่อน
+	GetRecords(clientID int, exerciseID *int, recordType string) ([]models.PersonalRecord, error)
+	// แทนที่ PR ทั้งหมดของลูกค้าในท่าหนึ่ง (หลังคำนวณใหม่จากประวัติ) ใน Transaction เดียว
+	ReplaceRecords(clientID, exerciseID int, records []models.PersonalRecord) error
+	// ท่าที่ลูกค้ามี PR หรือมีบันทึกการฝึก (รวม Session ที่ถูกยกเลิก) ใช้หาท่าที่ต้องคำนวณ PR ใหม่
+	GetClientExerciseIDs(clientID int) ([]int, error)
+}
+
+type personalRecordRepository struct {
+	db *sql.DB
+}
+
+func NewPersonalRecordRepository(db *sql.DB) PersonalRecordRepository {
+	return &personalRecordRepository{db: db}
+}
+
+func (r *personalRecordRepository) GetRepMaxes(clientID int, exerciseIDs []int, before *time.Time) ([]models.RepMax, error) {
+	ids := make([]int64, len(exerciseIDs))
+	for i, id := range exerciseIDs {
+		ids[i] = int64(id)
+	}
+
+	query := `
+		SELECT l.exercise_id, s.weight_kg, MAX(s.reps)
+		FROM session_log_sets s
+		JOIN session_logs l ON l.id = s.session_log_id
+		JOIN schedules sc ON sc.id = l.schedule_id
+		WHERE sc.client_id = $1 AND l.exercise_id = ANY($2) AND sc.status <> 'cancelled'
+		  AND s.reps > 0 AND s.weight_kg > 0
+		  AND ($3::timestamptz IS NULL OR sc.start_time < $3)
+		GROUP BY l.exercise_id, s.weight_kg`
+	rows, err := r.db.Query(query, clientID, pq.Array(ids), before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var maxes []models.RepMax
+	for rows.Next() {
+		var m models.RepMax
+		if err := rows.Scan(&m.ExerciseID, &m.WeightKg, &m.Reps); err != nil {
+			return nil, err
+		}
+		maxes = append(maxes, m)
+	}
+	return maxes, rows.Err()
+}
+
+func (r *personalRecordRepository) GetRecords(clientID int, exerciseID *int, recordType string) ([]models.PersonalRecord, error) {
+	query := `
+		SELECT p.id, p.client_id, p.exercise_id, e.name, p.record_type, p.value, p.previous_value,
+		       p.weight_kg, p.reps, p.session_log_set_id, p.schedule_id, p.achieved_at, p.created_at
+		FROM personal_records p
+		JOIN exercises e ON e.id = p.exercise_id
+		WHERE p.client_id = $1
+		  AND ($2::int IS NULL OR p.exercise_id = $2)
+		  AND ($3 = '' OR p.record_type = $3)
+		ORDER BY p.achieved_at DESC, p.id DESC`
+	rows, err := r.db.Query(query, clientID, exerciseID, recordType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []models.PersonalRecord{}
+	for rows.Next() {
+		var p models.PersonalRecord
+		if err := rows.Scan(&p.ID, &p.ClientID, &p.ExerciseID, &p.ExerciseName, &p.RecordType, &p.Value, &p.PreviousValue,
+			&p.WeightKg, &p.Reps, &p.SessionLogSetID, &p.ScheduleID, &p.AchievedAt, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		records = append(records, p)
+	}
+	return records, rows.Err()
+}
+
+func (r *personalRecordRepository) ReplaceRecords(clientID, exerciseID int, records []models.PersonalRecord) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM personal_records WHERE client_id = $1 AND exercise_id = $2`, clientID, exerciseID); err != nil {
+		return err
+	}
+	for i := range records {
+		pr := &records[i]
+		err := tx.QueryRow(`
+			INSERT INTO personal_records (client_id, exercise_id, record_type, value, previous_value, weight_kg, reps, session_log_set_id, schedule_id, achieved_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING id, created_at`,
+			pr.ClientID, pr.ExerciseID, pr.RecordType, pr.Value, pr.PreviousValue, pr.WeightKg, pr.Reps, pr.SessionLogSetID, pr.ScheduleID, pr.AchievedAt,
+		).Scan(&pr.ID, &pr.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *personalRecordRepository) GetClientExerciseIDs(clientID int) ([]int, error) {
+	query := `
+		SELECT exercise_id FROM personal_records WHERE client_id = $1
+		UNION
+		SELECT l.exercise_id
+		FROM session_logs l
+		JOIN schedules sc ON sc.id = l.schedule_id
+		WHERE sc.client_id = $1 AND l.exercise_id IS NOT NULL
+		ORDER BY 1`
+	rows, err := r.db.Query(query, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
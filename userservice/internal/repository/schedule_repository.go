@@ -0,0 +1,325 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+	"users/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// ScheduleRepository จัดการนัดแบบเกิดซ้ำ (schedule_series) และแถวใน schedules ที่ขยายมาจาก series
+// occurrences คือเวลาเริ่มตาม Rule ที่ Service ขยายไว้แล้ว (เวลาจบ = เริ่ม + ความยาวของ series)
+type ScheduleRepository interface {
+	CreateSchedule(s *models.Schedule) error
+	GetScheduleByID(id int) (*models.Schedule, error)
+	// แก้ไขนัดเดียว ถ้าอยู่ใน series จะถูกทำเครื่องหมายเป็น exception
+	UpdateOccurrence(s *models.Schedule) error
+	// ยกเลิกนัดเดียวใน series (ไม่ลบแถว เพื่อกันไม่ให้ถูกสร้างซ้ำตอนขยาย Rule ใหม่)
+	CancelOccurrence(id int) error
+	DeleteSchedule(id int) error
+
+	GetSeriesByID(id int) (*models.ScheduleSeries, error)
+	CreateSeries(s *models.ScheduleSeries, occurrences []time.Time) error
+	// แก้ series ทั้งชุด: ลบนัดที่สร้างใหม่ได้ตั้งแต่ from แล้วขยายใหม่
+	UpdateSeries(s *models.ScheduleSeries, from time.Time, anchorID int, occurrences []time.Time) error
+	// แยก series: old ถูกตัดจบก่อน from และสร้าง next (ครั้งนี้และครั้งถัดไป)
+	SplitSeries(old *models.ScheduleSeries, from time.Time, anchorID int, next *models.ScheduleSeries, occurrences []time.Time) error
+	// ตัด series ให้จบก่อน from (ลบครั้งถัดไป)
+	TruncateSeries(s *models.ScheduleSeries, from time.Time, anchorID int) error
+	DeleteSeries(id int) error
+
+	// นัดสถานะ scheduled ของเทรนเนอร์หรือลูกค้าที่ทับช่วงเวลาใดช่วงหนึ่ง (starts[i]..ends[i]) โดยเผื่อ buffer
+	// excludeSeriesID/excludeIDs = นัดที่กำลังถูกแก้ (0/nil = ไม่ยกเว้น)
+	FindConflicts(trainerID, clientID int, starts, ends []time.Time, buffer time.Duration, excludeSeriesID int, excludeIDs []int) ([]models.Schedule, error)
+}
+
+type scheduleRepository struct {
+	db *sql.DB
+}
+
+func NewScheduleRepository(db *sql.DB) ScheduleRepository {
+	return &scheduleRepository{db: db}
+}
+
+const scheduleColumns = `id, title, trainer_id, client_id, start_time, end_time, status, created_at, updated_at, series_id, original_start, is_exception`
+
+func scanSchedule(row rowScanner, s *models.Schedule) error {
+	return row.Scan(&s.ID, &s.Title, &s.TrainerID, &s.ClientID, &s.StartTime, &s.EndTime, &s.Status,
+		&s.CreatedAt, &s.UpdatedAt, &s.SeriesID, &s.OriginalStart, &s.IsException)
+}
+
+func (r *scheduleRepository) CreateSchedule(s *models.Schedule) error {
+	query := `
+		INSERT INTO schedules (title, trainer_id, client_id, start_time, end_time, status)
+		VALUES ($1, $2, $3, $4, $5, 'scheduled')
+		RETURNING ` + scheduleColumns
+	return scanSchedule(r.db.QueryRow(query, s.Title, s.TrainerID, s.ClientID, s.StartTime, s.EndTime), s)
+}
+
+func (r *scheduleRepository) GetScheduleByID(id int) (*models.Schedule, error) {
+	var s models.Schedule
+	if err := scanSchedule(r.db.QueryRow(`SELECT `+scheduleColumns+` FROM schedules WHERE id = $1`, id), &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *scheduleRepository) UpdateOccurrence(s *models.Schedule) error {
+	query := `
+		UPDATE schedules
+		SET title=$1, client_id=$2, start_time=$3, end_time=$4, status=$5,
+		    is_exception = (series_id IS NOT NULL), updated_at=NOW()
+		WHERE id=$6
+		RETURNING ` + scheduleColumns
+	return scanSchedule(r.db.QueryRow(query, s.Title, s.ClientID, s.StartTime, s.EndTime, s.Status, s.ID), s)
+}
+
+func (r *scheduleRepository) CancelOccurrence(id int) error {
+	res, err := r.db.Exec(`UPDATE schedules SET status='cancelled', is_exception=TRUE, updated_at=NOW() WHERE id=$1`, id)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *scheduleRepository) DeleteSchedule(id int) error {
+	res, err := r.db.Exec(`DELETE FROM schedules WHERE id=$1`, id)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *scheduleRepository) GetSeriesByID(id int) (*models.ScheduleSeries, error) {
+	query := `
+		SELECT id, title, trainer_id, client_id, rrule, start_time, end_time, timezone, created_at, updated_at
+		FROM schedule_series WHERE id = $1`
+	var s models.ScheduleSeries
+	err := r.db.QueryRow(query, id).Scan(&s.ID, &s.Title, &s.TrainerID, &s.ClientID, &s.RRule,
+		&s.StartTime, &s.EndTime, &s.Timezone, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`SELECT `+scheduleColumns+` FROM schedules WHERE series_id = $1 ORDER BY start_time ASC`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	s.Occurrences = []models.Schedule{}
+	for rows.Next() {
+		var o models.Schedule
+		if err := scanSchedule(rows, &o); err != nil {
+			return nil, err
+		}
+		s.Occurrences = append(s.Occurrences, o)
+	}
+	return &s, rows.Err()
+}
+
+func (r *scheduleRepository) CreateSeries(s *models.ScheduleSeries, occurrences []time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertSeries(tx, s); err != nil {
+		return err
+	}
+	if err := insertOccurrences(tx, s, occurrences); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *scheduleRepository) UpdateSeries(s *models.ScheduleSeries, from time.Time, anchorID int, occurrences []time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := updateSeriesRow(tx, s); err != nil {
+		return err
+	}
+	if err := deleteRegenerable(tx, s.ID, from, anchorID); err != nil {
+		return err
+	}
+	if err := insertOccurrences(tx, s, occurrences); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *scheduleRepository) SplitSeries(old *models.ScheduleSeries, from time.Time, anchorID int, next *models.ScheduleSeries, occurrences []time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := updateSeriesRow(tx, old); err != nil {
+		return err
+	}
+	if err := deleteRegenerable(tx, old.ID, from, anchorID); err != nil {
+		return err
+	}
+	if err := insertSeries(tx, next); err != nil {
+		return err
+	}
+	if err := insertOccurrences(tx, next, occurrences); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *scheduleRepository) TruncateSeries(s *models.ScheduleSeries, from time.Time, anchorID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := updateSeriesRow(tx, s); err != nil {
+		return err
+	}
+	if err := deleteRegenerable(tx, s.ID, from, anchorID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteSeries ลบ series และนัดที่ยังไม่เกิดขึ้น (นัดที่มี Log หรือไม่ได้อยู่สถานะ scheduled จะกลายเป็นนัดเดี่ยว)
+func (r *scheduleRepository) DeleteSeries(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteRegenerable(tx, id, time.Time{}, 0); err != nil {
+		return err
+	}
+	res, err := tx.Exec(`DELETE FROM schedule_series WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return tx.Commit()
+}
+
+func insertSeries(tx *sql.Tx, s *models.ScheduleSeries) error {
+	query := `
+		INSERT INTO schedule_series (title, trainer_id, client_id, rrule, start_time, end_time, timezone)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at`
+	return tx.QueryRow(query, s.Title, s.TrainerID, s.ClientID, s.RRule, s.StartTime, s.EndTime, s.Timezone).
+		Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt)
+}
+
+func updateSeriesRow(tx *sql.Tx, s *models.ScheduleSeries) error {
+	query := `
+		UPDATE schedule_series
+		SET title=$1, client_id=$2, rrule=$3, start_time=$4, end_time=$5, timezone=$6, updated_at=NOW()
+		WHERE id=$7
+		RETURNING created_at, updated_at`
+	return tx.QueryRow(query, s.Title, s.ClientID, s.RRule, s.StartTime, s.EndTime, s.Timezone, s.ID).
+		Scan(&s.CreatedAt, &s.UpdatedAt)
+}
+
+// deleteRegenerable ลบนัดใน series ตั้งแต่ from ที่สร้างใหม่จาก Rule ได้โดยไม่เสียข้อมูล:
+// ยังเป็น scheduled, ไม่ใช่ exception และไม่มี Session Log
+// anchorID (นัดที่ผู้ใช้เลือกแก้) ถูกลบด้วยแม้เป็น exception ถ้ายังไม่มี Log
+func deleteRegenerable(tx *sql.Tx, seriesID int, from time.Time, anchorID int) error {
+	query := `
+		DELETE FROM schedules s
+		WHERE s.series_id = $1 AND s.original_start >= $2
+		  AND (s.id = $3 OR (s.is_exception = FALSE AND s.status = 'scheduled'))
+		  AND NOT EXISTS (SELECT 1 FROM session_logs l WHERE l.schedule_id = s.id)`
+	_, err := tx.Exec(query, seriesID, from, anchorID)
+	return err
+}
+
+// insertOccurrences สร้างนัดตามเวลาที่ขยายแล้ว (ข้ามเวลาที่มีนัดของ series นี้อยู่แล้ว เช่น exception)
+func insertOccurrences(tx *sql.Tx, s *models.ScheduleSeries, occurrences []time.Time) error {
+	stmt, err := tx.Prepare(`
+		INSERT INTO schedules (title, trainer_id, client_id, start_time, end_time, status, series_id, original_start)
+		VALUES ($1, $2, $3, $4, $5, 'scheduled', $6, $4)
+		ON CONFLICT (series_id, original_start) WHERE series_id IS NOT NULL DO NOTHING`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	duration := s.EndTime.Sub(s.StartTime)
+	for _, start := range occurrences {
+		if _, err := stmt.Exec(s.Title, s.TrainerID, s.ClientID, start, start.Add(duration), s.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *scheduleRepository) FindConflicts(trainerID, clientID int, starts, ends []time.Time, buffer time.Duration, excludeSeriesID int, excludeIDs []int) ([]models.Schedule, error) {
+	exclude := make([]int64, len(excludeIDs))
+	for i, id := range excludeIDs {
+		exclude[i] = int64(id)
+	}
+	// ส่งเวลาเป็นข้อความ RFC 3339 แล้วให้ Postgres แปลงเป็น timestamptz[]
+	startTexts := make([]string, len(starts))
+	endTexts := make([]string, len(ends))
+	for i := range starts {
+		startTexts[i] = starts[i].Format(time.RFC3339Nano)
+		endTexts[i] = ends[i].Format(time.RFC3339Nano)
+	}
+
+	// ช่วงเวลาทับกันเมื่อ start_a < end_b + buffer และ start_b < end_a + buffer
+	query := `
+		SELECT DISTINCT ` + prefixColumns("s", scheduleColumns) + `
+		FROM schedules s
+		JOIN unnest($3::timestamptz[], $4::timestamptz[]) AS r(start_time, end_time)
+		  ON s.start_time < r.end_time + ($5 * INTERVAL '1 second')
+		 AND r.start_time < s.end_time + ($5 * INTERVAL '1 second')
+		WHERE s.status = 'scheduled'
+		  AND (s.trainer_id = $1 OR s.client_id = $2)
+		  AND ($6 = 0 OR s.series_id IS DISTINCT FROM $6)
+		  AND s.id <> ALL($7)
+		ORDER BY s.start_time, s.id`
+	rows, err := r.db.Query(query, trainerID, clientID, pq.Array(startTexts), pq.Array(endTexts),
+		int64(buffer/time.Second), excludeSeriesID, pq.Array(exclude))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	conflicts := []models.Schedule{}
+	for rows.Next() {
+		var c models.Schedule
+		if err := scanSchedule(rows, &c); err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, c)
+	}
+	return conflicts, rows.Err()
+}
+
+// prefixColumns เติม alias ให้ทุกคอลัมน์ เช่น "id, title" -> "s.id, s.title"
+func prefixColumns(alias, columns string) string {
+	parts := strings.Split(columns, ",")
+	for i, p := range parts {
+		parts[i] = alias + "." + strings.TrimSpace(p)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode"
+	"users/internal/models"
+)
+
+type SearchRepository interface {
+	// ค้นหาชนิดเดียว (models.SearchType*) เฉพาะข้อมูลของผู้ค้น เรียงตามความเกี่ยวข้อง
+	// คืนผลไม่เกิน limit รายการ และจำนวนที่ตรงทั้งหมด
+	Search(searchType string, userID int, role string, query string, limit int) ([]models.SearchResult, int, error)
+}
+
+type searchRepository struct {
+	db *sql.DB
+}
+
+func NewSearchRepository(db *sql.DB) SearchRepository {
+	return &searchRepository{db: db}
+}
+
+// searchSource วิธีค้นหาของแต่ละชนิด
+// document ต้องตรงกับนิพจน์ของ index ใน migrations/0017_search.up.sql (ไม่เช่นนั้นจะ scan ทั้งตาราง)
+// scope เงื่อนไขเฉพาะข้อมูลของผู้ค้น ($3 = user id)
+type searchSource struct {
+	from     string
+	id       string
+	title    string
+	subtitle string
+	body     string
+	clientID string
+	document string
+	scope    func(role string) string
+
+	// ค้นเบอร์โทรแบบตัวเลขล้วน ($5)
+	phone string
+}
+
+var searchSources = map[string]searchSource{
+	models.SearchTypeClients: {
+		from:     "clients",
+		id:       "id",
+		title:    "name",
+		subtitle: "coalesce(goal, '')",
+		body:     "concat_ws(' · ', phone_number, email)",
+		clientID: "id",
+		document: "name || ' ' || coalesce(email, '') || ' ' || coalesce(phone_number, '')",
+		scope: func(role string) string {
+			if role == "client" {
+				return "user_id = $3"
+			}
+			return "trainer_id = $3"
+		},
+		phone: "regexp_replace(coalesce(phone_number, ''), '[^0-9]', '', 'g')",
+	},
+	models.SearchTypeNotes: {
+		from:     "client_notes n JOIN clients c ON c.id = n.client_id",
+		id:       "n.id",
+		title:    "c.name",
+		subtitle: "n.type",
+		body:     "n.content",
+		clientID: "n.client_id",
+		document: "n.content",
+		scope: func(role string) string {
+			// บันทึกเป็นของเทรนเนอร์เท่านั้น
+			if role == "client" {
+				return "FALSE"
+			}
+			return "c.trainer_id = $3"
+		},
+	},
+	models.SearchTypePrograms: {
+		from:     "programs",
+		id:       "id",
+		title:    "name",
+		subtitle: "CASE WHEN is_template THEN 'template' ELSE '' END",
+		body:     "description",
+		clientID: "client_id",
+		document: "name || ' ' || description",
+		scope: func(role string) string {
+			if role == "client" {
+				return "client_id IN (SELECT id FROM clients WHERE user_id = $3)"
+			}
+			return "trainer_id = $3"
+		},
+	},
+	models.SearchTypeExercises: {
+		from:     "exercises",
+		id:       "id",
+		title:    "name",
+		subtitle: "category",
+		body:     "instructions",
+		clientID: "NULL::int",
+		document: "name || ' ' || category || ' ' || coalesce(equipment, '') || ' ' || instructions",
+		// ท่ากลาง + ท่าส่วนตัว (เหมือน GET /exercises)
+		scope: func(string) string { return "(trainer_id IS NULL OR trainer_id = $3)" },
+	},
+}
+
+// Rank = ts_rank ของ Full-text + word_similarity ของ Trigram
+// ตรงเมื่อ: Full-text ตรงทั้งคำ, Trigram ใกล้เคียง (พิมพ์ผิด/ไม่ครบ) หรือมีคำค้นเป็นส่วนหนึ่งของข้อความ (ภาษาไทยที่ไม่เว้นวรรค)
+func (r *searchRepository) Search(searchType string, userID int, role string, query string, limit int) ([]models.SearchResult, int, error) {
+	s, ok := searchSources[searchType]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown search type: %s", searchType)
+	}
+
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(query)
+	args := []interface{}{query, "%" + escaped + "%", userID, limit}
+	match := "to_tsvector('simple', " + s.document + ") @@ tsq OR $1 <% (" + s.document + ") OR (" + s.document + ") ILIKE $2"
+	if s.phone != "" {
+		// ตัวเลขน้อยกว่า 3 หลักไม่ค้นเบอร์โทร (LIKE NULL = ไม่ตรง)
+		var digits interface{}
+		if d := digitsOf(query); len(d) >= 3 {
+			digits = "%" + d + "%"
+		}
+		args = append(args, digits)
+		match += " OR " + s.phone + " LIKE $5"
+	}
+
+	rows, err := r.db.Query(`
+		SELECT `+s.id+`, `+s.title+`, `+s.subtitle+`, `+s.body+`, `+s.clientID+`,
+		       ts_rank(to_tsvector('simple', `+s.document+`), tsq) + word_similarity($1, `+s.document+`) AS rank,
+		       COUNT(*) OVER ()
+		FROM `+s.from+`, websearch_to_tsquery('simple', $1) AS tsq
+		WHERE `+s.scope(role)+` AND (`+match+`)
+		ORDER BY rank DESC, 1
+		LIMIT $4`, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	results := []models.SearchResult{}
+	total := 0
+	for rows.Next() {
+		res := models.SearchResult{Type: searchType}
+		if err := rows.Scan(&res.ID, &res.Title, &res.Subtitle, &res.Body, &res.ClientID, &res.Rank, &total); err != nil {
+			return nil, 0, err
+		}
+		results = append(results, res)
+	}
+	return results, total, rows.Err()
+}
+
+func digitsOf(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		} else if !unicode.IsSpace(r) && r != '-' && r != '+' && r != '(' && r != ')' {
+			// ไม่ใช่เบอร์โทร
+			return ""
+		}
+	}
+	return b.String()
+}
@@ -2,12 +2,15 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
+	"time"
 	"users/internal/models"
+
+	"github.com/lib/pq"
 )
 
 type SessionRepository interface {
 	// Schedule
-	CreateSchedule(s *models.Schedule) error
 	GetSchedulesByClientID(clientID int) ([]models.Schedule, error)
 	GetScheduleByID(id int) (*models.Schedule, error)
 	UpdateScheduleStatus(id int, status string) error
@@ -16,8 +19,26 @@ type SessionRepository interface {
 	CreateSessionLog(log *models.SessionLog) error
 	CreateSessionLogSet(set *models.SessionLogSet) error
 	GetLogsByScheduleID(scheduleID int) ([]models.SessionLog, error)
+
+	// บันทึก Log หลายท่าพร้อมเซตทั้งหมด (และ PR ใหม่ใน w.NewPRs) ใน Transaction เดียว
+	CreateWorkoutLog(w *models.WorkoutLog) error
+
+	// แก้ไข/ลบ Log และเซต (ต้องอยู่ใน Schedule ที่ระบุ)
+	GetLogByID(scheduleID, logID int) (*models.SessionLog, error)
+	UpdateSessionLog(l *models.SessionLog) error
+	DeleteSessionLog(scheduleID, logID int) error
+	UpdateSessionLogSet(scheduleID int, set *models.SessionLogSet) error
+	DeleteSessionLogSet(scheduleID, logID, setID int) error
+
+	// เซตทั้งหมดของลูกค้าในท่าที่ระบุ (ไม่รวม Session ที่ถูกยกเลิก) เรียงตามเวลา
+	GetClientExerciseSets(clientID, exerciseID int, from, to *time.Time) ([]models.PerformedSet, error)
 }
 
+var (
+	ErrSessionLogNotFound    = errors.New("session log not found")
+	ErrSessionLogSetNotFound = errors.New("session log set not found")
+)
+
 type sessionRepository struct {
 	db *sql.DB
 }
@@ -28,17 +49,8 @@ func NewSessionRepository(db *sql.DB) SessionRepository {
 
 // --- Implementation ---
 
-func (r *sessionRepository) CreateSchedule(s *models.Schedule) error {
-	query := `
-		INSERT INTO schedules (title, trainer_id, client_id, start_time, end_time, status)
-		VALUES ($1, $2, $3, $4, $5, 'scheduled')
-		RETURNING id, created_at`
-	return r.db.QueryRow(query, s.Title, s.TrainerID, s.ClientID, s.StartTime, s.EndTime).
-		Scan(&s.ID, &s.CreatedAt)
-}
-
 func (r *sessionRepository) GetSchedulesByClientID(clientID int) ([]models.Schedule, error) {
-	query := `SELECT id, title, trainer_id, client_id, start_time, end_time, status, created_at 
+	query := `SELECT id, title, trainer_id, client_id, start_time, end_time, status, created_at, series_id, original_start, is_exception
               FROM schedules WHERE client_id = $1 ORDER BY start_time ASC`
 	rows, err := r.db.Query(query, clientID)
 	if err != nil {
@@ -49,7 +61,7 @@ func (r *sessionRepository) GetSchedulesByClientID(clientID int) ([]models.Sched
 	var schedules []models.Schedule
 	for rows.Next() {
 		var s models.Schedule
-		if err := rows.Scan(&s.ID, &s.Title, &s.TrainerID, &s.ClientID, &s.StartTime, &s.EndTime, &s.Status, &s.CreatedAt); err != nil {
+		if err := rows.Scan(&s.ID, &s.Title, &s.TrainerID, &s.ClientID, &s.StartTime, &s.EndTime, &s.Status, &s.CreatedAt, &s.SeriesID, &s.OriginalStart, &s.IsException); err != nil {
 			return nil, err
 		}
 		schedules = append(schedules, s)
@@ -64,10 +76,10 @@ func (r *sessionRepository) UpdateScheduleStatus(id int, status string) error {
 	return err
 }
 func (r *sessionRepository) GetScheduleByID(id int) (*models.Schedule, error) {
-	query := `SELECT id, title, trainer_id, client_id, start_time, end_time, status, created_at 
+	query := `SELECT id, title, trainer_id, client_id, start_time, end_time, status, created_at, series_id, original_start, is_exception
               FROM schedules WHERE id = $1`
 	var s models.Schedule
-	err := r.db.QueryRow(query, id).Scan(&s.ID, &s.Title, &s.TrainerID, &s.ClientID, &s.StartTime, &s.EndTime, &s.Status, &s.CreatedAt)
+	err := r.db.QueryRow(query, id).Scan(&s.ID, &s.Title, &s.TrainerID, &s.ClientID, &s.StartTime, &s.EndTime, &s.Status, &s.CreatedAt, &s.SeriesID, &s.OriginalStart, &s.IsException)
 	if err != nil {
 		return nil, err
 	}
@@ -77,30 +89,318 @@ func (r *sessionRepository) GetScheduleByID(id int) (*models.Schedule, error) {
 // --- Logs ---
 
 func (r *sessionRepository) CreateSessionLog(log *models.SessionLog) error {
-	query := `INSERT INTO session_logs (schedule_id, exercise_id, notes) VALUES ($1, $2, $3) RETURNING id, created_at`
-	return r.db.QueryRow(query, log.ScheduleID, log.ExerciseID, log.Notes).Scan(&log.ID, &log.CreatedAt)
+	query := `INSERT INTO session_logs (schedule_id, exercise_id, block_id, notes) VALUES ($1, $2, $3, $4) RETURNING id, created_at`
+	return r.db.QueryRow(query, log.ScheduleID, log.ExerciseID, log.BlockID, log.Notes).Scan(&log.ID, &log.CreatedAt)
 }
 
 func (r *sessionRepository) CreateSessionLogSet(set *models.SessionLogSet) error {
-	query := `INSERT INTO session_log_sets (session_log_id, set_number, weight_kg, reps, rpe) VALUES ($1, $2, $3, $4, $5) RETURNING id`
-	return r.db.QueryRow(query, set.SessionLogID, set.SetNumber, set.WeightKg, set.Reps, set.RPE).Scan(&set.ID)
+	query := `INSERT INTO session_log_sets (session_log_id, set_number, weight_kg, reps, rpe, round) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	return r.db.QueryRow(query, set.SessionLogID, set.SetNumber, set.WeightKg, set.Reps, set.RPE, set.Round).Scan(&set.ID)
+}
+
+func (r *sessionRepository) CreateWorkoutLog(w *models.WorkoutLog) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	logStmt, err := tx.Prepare(`INSERT INTO session_logs (schedule_id, exercise_id, block_id, notes) VALUES ($1, $2, $3, $4) RETURNING id, created_at`)
+	if err != nil {
+		return err
+	}
+	defer logStmt.Close()
+
+	setStmt, err := tx.Prepare(`INSERT INTO session_log_sets (session_log_id, set_number, weight_kg, reps, rpe, round) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`)
+	if err != nil {
+		return err
+	}
+	defer setStmt.Close()
+
+	for i := range w.Exercises {
+		l := &w.Exercises[i]
+		l.ScheduleID = w.ScheduleID
+		if err := logStmt.QueryRow(l.ScheduleID, l.ExerciseID, l.BlockID, l.Notes).Scan(&l.ID, &l.CreatedAt); err != nil {
+			return err
+		}
+		for j := range l.Sets {
+			set := &l.Sets[j]
+			set.SessionLogID = l.ID
+			if err := setStmt.QueryRow(set.SessionLogID, set.SetNumber, set.WeightKg, set.Reps, set.RPE, set.Round).Scan(&set.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	// PR ที่ Service ตรวจพบ อ้างอิงเซตผ่านตำแหน่ง (LogIndex, SetIndex) เพราะเพิ่งได้ id จาก INSERT ด้านบน
+	for i := range w.NewPRs {
+		pr := &w.NewPRs[i]
+		pr.SessionLogSetID = w.Exercises[pr.LogIndex].Sets[pr.SetIndex].ID
+		pr.ScheduleID = w.ScheduleID
+		err := tx.QueryRow(`
+			INSERT INTO personal_records (client_id, exercise_id, record_type, value, previous_value, weight_kg, reps, session_log_set_id, schedule_id, achieved_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING id, created_at`,
+			pr.ClientID, pr.ExerciseID, pr.RecordType, pr.Value, pr.PreviousValue, pr.WeightKg, pr.Reps, pr.SessionLogSetID, pr.ScheduleID, pr.AchievedAt,
+		).Scan(&pr.ID, &pr.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
 func (r *sessionRepository) GetLogsByScheduleID(scheduleID int) ([]models.SessionLog, error) {
-	// (Logic ดึง Log และอาจต้อง Join เอา Sets มาด้วย ถ้าทำแบบละเอียด)
-	// เบื้องต้นดึงแค่ Log header ก่อน
-	query := `SELECT id, schedule_id, exercise_id, notes, created_at FROM session_logs WHERE schedule_id = $1`
-	rows, err := r.db.Query(query, scheduleID)
+	return r.getLogs(`l.schedule_id = $1`, scheduleID)
+}
+
+func (r *sessionRepository) GetLogByID(scheduleID, logID int) (*models.SessionLog, error) {
+	logs, err := r.getLogs(`l.schedule_id = $1 AND l.id = $2`, scheduleID, logID)
+	if err != nil {
+		return nil, err
+	}
+	if len(logs) == 0 {
+		return nil, ErrSessionLogNotFound
+	}
+	return &logs[0], nil
+}
+
+// getLogs ดึง Log header พร้อมรายละเอียดท่าฝึก และเซตทั้งหมดของแต่ละ Log (เรียงตาม set_number)
+func (r *sessionRepository) getLogs(where string, args ...interface{}) ([]models.SessionLog, error) {
+	// LEFT JOIN เพราะ exercise_id อาจเป็น null
+	query := `SELECT l.id, l.schedule_id, l.exercise_id, l.block_id, l.notes, l.created_at,
+                     e.id, e.trainer_id, e.name, e.category, e.primary_muscles, e.secondary_muscles, e.equipment, e.instructions, e.created_at, e.updated_at
+              FROM session_logs l
+              LEFT JOIN exercises e ON e.id = l.exercise_id
+              WHERE ` + where + `
+              ORDER BY l.id ASC`
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var logs []models.SessionLog
+	logs := []models.SessionLog{}
+	index := map[int]int{} // log id -> ตำแหน่งใน logs
+	var logIDs []int64
 	for rows.Next() {
 		var l models.SessionLog
-		rows.Scan(&l.ID, &l.ScheduleID, &l.ExerciseID, &l.Notes, &l.CreatedAt)
+		var (
+			exID           sql.NullInt64
+			exName, exCat  sql.NullString
+			exInstructions sql.NullString
+			exCreatedAt    sql.NullTime
+			exUpdatedAt    sql.NullTime
+			e              models.Exercise
+		)
+		if err := rows.Scan(
+			&l.ID, &l.ScheduleID, &l.ExerciseID, &l.BlockID, &l.Notes, &l.CreatedAt,
+			&exID, &e.TrainerID, &exName, &exCat,
+			(*pq.StringArray)(&e.PrimaryMuscles), (*pq.StringArray)(&e.SecondaryMuscles),
+			&e.Equipment, &exInstructions, &exCreatedAt, &exUpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if exID.Valid {
+			e.ID = int(exID.Int64)
+			e.Name = exName.String
+			e.Category = exCat.String
+			e.Instructions = exInstructions.String
+			e.CreatedAt = exCreatedAt.Time
+			e.UpdatedAt = exUpdatedAt.Time
+			e.IsGlobal = e.TrainerID == nil
+			l.Exercise = &e
+		}
+		l.Sets = []models.SessionLogSet{}
+		index[l.ID] = len(logs)
+		logIDs = append(logIDs, int64(l.ID))
 		logs = append(logs, l)
 	}
-	return logs, nil
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(logs) == 0 {
+		return logs, nil
+	}
+
+	setRows, err := r.db.Query(`
+		SELECT id, session_log_id, set_number, weight_kg, reps, rpe, round
+		FROM session_log_sets
+		WHERE session_log_id = ANY($1)
+		ORDER BY session_log_id, round NULLS FIRST, set_number, id`, pq.Array(logIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer setRows.Close()
+
+	for setRows.Next() {
+		var set models.SessionLogSet
+		if err := setRows.Scan(&set.ID, &set.SessionLogID, &set.SetNumber, &set.WeightKg, &set.Reps, &set.RPE, &set.Round); err != nil {
+			return nil, err
+		}
+		l := &logs[index[set.SessionLogID]]
+		l.Sets = append(l.Sets, set)
+	}
+	return logs, setRows.Err()
+}
+
+// UpdateSessionLog แก้ไขท่า/โน้ตของ Log ถ้า l.Sets ไม่เป็น nil จะแทนที่เซตทั้งหมดด้วยชุดใหม่ (ใน Transaction เดียว ดู replaceSets)
+func (r *sessionRepository) UpdateSessionLog(l *models.SessionLog) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE session_logs SET exercise_id=$1, block_id=$2, notes=$3 WHERE id=$4 AND schedule_id=$5`,
+		l.ExerciseID, l.BlockID, l.Notes, l.ID, l.ScheduleID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return ErrSessionLogNotFound
+	}
+
+	if l.Sets != nil {
+		if err := replaceSets(tx, l); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// setKey ตำแหน่งของเซตใน Log (round = 0 คือไม่ได้ฝึกเป็น Block)
+type setKey struct {
+	round, setNumber int
+}
+
+func keyOfSet(set *models.SessionLogSet) setKey {
+	k := setKey{setNumber: set.SetNumber}
+	if set.Round != nil {
+		k.round = *set.Round
+	}
+	return k
+}
+
+// replaceSets แทนที่เซตของ Log ด้วย l.Sets โดยแก้เซตเดิมที่ (round, set_number) ตรงกันในที่เดิม
+// (personal_records อ้าง id ของเซตแบบ ON DELETE CASCADE: ลบแล้วสร้างใหม่จะทำให้ประวัติ PR หาย)
+// เซตเดิมที่ไม่มีในชุดใหม่ถูกลบ เซตใหม่ที่ไม่ตรงกับเซตเดิมถูกเพิ่ม
+func replaceSets(tx *sql.Tx, l *models.SessionLog) error {
+	rows, err := tx.Query(`SELECT id, set_number, round FROM session_log_sets WHERE session_log_id = $1 ORDER BY id`, l.ID)
+	if err != nil {
+		return err
+	}
+	existing := map[setKey][]int{}
+	for rows.Next() {
+		var set models.SessionLogSet
+		if err := rows.Scan(&set.ID, &set.SetNumber, &set.Round); err != nil {
+			rows.Close()
+			return err
+		}
+		k := keyOfSet(&set)
+		existing[k] = append(existing[k], set.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	kept := []int64{}
+	for j := range l.Sets {
+		set := &l.Sets[j]
+		set.SessionLogID = l.ID
+		k := keyOfSet(set)
+		if ids := existing[k]; len(ids) > 0 {
+			set.ID, existing[k] = ids[0], ids[1:]
+			if _, err := tx.Exec(`UPDATE session_log_sets SET weight_kg=$1, reps=$2, rpe=$3 WHERE id=$4`,
+				set.WeightKg, set.Reps, set.RPE, set.ID); err != nil {
+				return err
+			}
+		} else {
+			err := tx.QueryRow(
+				`INSERT INTO session_log_sets (session_log_id, set_number, weight_kg, reps, rpe, round) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+				set.SessionLogID, set.SetNumber, set.WeightKg, set.Reps, set.RPE, set.Round,
+			).Scan(&set.ID)
+			if err != nil {
+				return err
+			}
+		}
+		kept = append(kept, int64(set.ID))
+	}
+
+	_, err = tx.Exec(`DELETE FROM session_log_sets WHERE session_log_id = $1 AND id <> ALL($2)`, l.ID, pq.Array(kept))
+	return err
+}
+
+func (r *sessionRepository) DeleteSessionLog(scheduleID, logID int) error {
+	res, err := r.db.Exec(`DELETE FROM session_logs WHERE id=$1 AND schedule_id=$2`, logID, scheduleID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return ErrSessionLogNotFound
+	}
+	return nil
+}
+
+// UpdateSessionLogSet แก้ไขเซตเดียว (ต้องอยู่ใน Log และ Schedule ที่ระบุ)
+func (r *sessionRepository) UpdateSessionLogSet(scheduleID int, set *models.SessionLogSet) error {
+	query := `
+		UPDATE session_log_sets s
+		SET set_number=$1, weight_kg=$2, reps=$3, rpe=$4, round=$5
+		FROM session_logs l
+		WHERE s.id=$6 AND s.session_log_id=$7 AND l.id = s.session_log_id AND l.schedule_id=$8`
+	res, err := r.db.Exec(query, set.SetNumber, set.WeightKg, set.Reps, set.RPE, set.Round, set.ID, set.SessionLogID, scheduleID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return ErrSessionLogSetNotFound
+	}
+	return nil
+}
+
+func (r *sessionRepository) DeleteSessionLogSet(scheduleID, logID, setID int) error {
+	query := `
+		DELETE FROM session_log_sets s
+		USING session_logs l
+		WHERE s.id=$1 AND s.session_log_id=$2 AND l.id = s.session_log_id AND l.schedule_id=$3`
+	res, err := r.db.Exec(query, setID, logID, scheduleID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return ErrSessionLogSetNotFound
+	}
+	return nil
+}
+
+func (r *sessionRepository) GetClientExerciseSets(clientID, exerciseID int, from, to *time.Time) ([]models.PerformedSet, error) {
+	// from/to เป็น null ได้ (ไม่จำกัดช่วงเวลา)
+	query := `
+		SELECT s.id, sc.id, sc.start_time, s.weight_kg, s.reps, s.rpe
+		FROM session_log_sets s
+		JOIN session_logs l ON l.id = s.session_log_id
+		JOIN schedules sc ON sc.id = l.schedule_id
+		WHERE sc.client_id = $1 AND l.exercise_id = $2 AND sc.status <> 'cancelled'
+		  AND ($3::timestamptz IS NULL OR sc.start_time >= $3)
+		  AND ($4::timestamptz IS NULL OR sc.start_time < $4)
+		ORDER BY sc.start_time ASC, l.id ASC, s.set_number ASC`
+	rows, err := r.db.Query(query, clientID, exerciseID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sets := []models.PerformedSet{}
+	for rows.Next() {
+		var p models.PerformedSet
+		if err := rows.Scan(&p.SetID, &p.ScheduleID, &p.PerformedAt, &p.WeightKg, &p.Reps, &p.RPE); err != nil {
+			return nil, err
+		}
+		sets = append(sets, p)
+	}
+	return sets, rows.Err()
 }
@@ -3,23 +3,26 @@ package repository
 
 import (
 	"database/sql"
+	"users/internal/listing"
 	"users/internal/models"
 )
 
 type TrainingRepository interface {
-	GetClientsByTrainerID(trainerID int) ([]models.Client, error)
+	// รายการแบบแบ่งหน้า (ตัวกรอง/การเรียงตาม ClientList, ScheduleList, AssignmentList)
+	ListClients(trainerID int, p *listing.Params) (*listing.Page[models.Client], error)
+	ListSchedules(userID int, role string, p *listing.Params) (*listing.Page[models.Schedule], error)
+	ListAssignments(userID int, role string, p *listing.Params) (*listing.Page[models.Assignment], error)
+
 	CreateClient(client *models.Client) error
 	GetProgramsByUserID(userID int, role string) ([]models.Program, error)
+	// นัดทั้งหมด (ICS feed)
 	GetSchedulesByUserID(userID int, role string) ([]models.Schedule, error)
-	GetAssignmentsByUserID(userID int, role string) ([]models.Assignment, error)
 
 	CreateAssignment(assignment *models.Assignment) error
 	CreateProgram(program *models.Program) error
-	CreateSchedule(schedule *models.Schedule) error
-
-	UpdateSchedule(schedule *models.Schedule) error
-	DeleteSchedule(id int, trainerID int) error
 
+	// client_id ปัจจุบันของงาน (ใช้ตัดสินว่า PUT ย้ายงานไปลูกค้าคนอื่นหรือไม่)
+	GetAssignmentClientID(id int) (int, error)
 	UpdateAssignment(a *models.Assignment) error
 	DeleteAssignment(id int, trainerID int) error
 }
@@ -32,45 +35,51 @@ func NewTrainingRepository(db *sql.DB) TrainingRepository {
 	return &trainingRepository{db: db}
 }
 
+// ClientList ตัวกรอง/การเรียงของ GET /clients
+var ClientList = &listing.Spec[models.Client]{
+	Sorts: map[string]listing.Sort[models.Client]{
+		"name":       {Column: "name", Key: func(c models.Client) any { return c.Name }},
+		"created_at": {Column: "created_at", Key: func(c models.Client) any { return c.CreatedAt }},
+	},
+	DefaultSort: "-created_at",
+	IDColumn:    "id",
+	ID:          func(c models.Client) int { return c.ID },
+	Filters: map[string]listing.Filter{
+		"q":              {Column: "name", Type: listing.Search},
+		"goal":           {Column: "goal", Type: listing.Search},
+		"activity_level": {Column: "activity_level", Type: listing.TextList},
+		"has_account":    {Column: "(user_id IS NOT NULL)", Type: listing.Bool},
+	},
+}
+
 // 1. ดึงรายชื่อลูกเทรน (Trainees) ของเทรนเนอร์คนนั้น
-func (r *trainingRepository) GetClientsByTrainerID(trainerID int) ([]models.Client, error) {
-	query := `
-        SELECT id, trainer_id, name, email, phone_number, avatar_url, 
+func (r *trainingRepository) ListClients(trainerID int, p *listing.Params) (*listing.Page[models.Client], error) {
+	selectFrom := `
+        SELECT id, trainer_id, user_id, name, email, phone_number, avatar_url, 
                birth_date, gender, height_cm, weight_kg, goal, 
                injuries, activity_level, medical_conditions, created_at
-        FROM clients 
-        WHERE trainer_id = $1
-        ORDER BY created_at DESC
-    `
-
-	rows, err := r.db.Query(query, trainerID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+        FROM clients`
+	q := &listing.Query{}
+	q.Where("trainer_id = " + q.Arg(trainerID))
 
-	var clients []models.Client
-	for rows.Next() {
+	return listing.Run(r.db, ClientList, p, selectFrom, q, func(rows *sql.Rows) (models.Client, error) {
 		var c models.Client
-		if err := rows.Scan(
-			&c.ID, &c.TrainerID, &c.Name, &c.Email, &c.Phone, &c.AvatarURL,
+		err := rows.Scan(
+			&c.ID, &c.TrainerID, &c.UserID, &c.Name, &c.Email, &c.Phone, &c.AvatarURL,
 			&c.BirthDate, &c.Gender, &c.Height, &c.Weight, &c.Goal,
 			&c.Injuries, &c.ActivityLevel, &c.MedicalConditions, &c.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
-		clients = append(clients, c)
-	}
-	return clients, nil
+		)
+		return c, err
+	})
 }
 
-// 2. ดึง Program (ถ้าเป็น Trainer เห็นของที่ตัวเองสร้าง, Client เห็นของตัวเอง)
+// 2. ดึง Program (ถ้าเป็น Trainer เห็นของที่ตัวเองสร้าง, Client เห็นของตัวเอง ผ่าน clients.user_id)
 func (r *trainingRepository) GetProgramsByUserID(userID int, role string) ([]models.Program, error) {
 	var query string
 	if role == "trainer" {
 		query = `SELECT id, name, description, trainer_id, client_id, is_template, created_at FROM programs WHERE trainer_id = $1`
 	} else {
-		query = `SELECT id, name, description, trainer_id, client_id, is_template, created_at FROM programs WHERE client_id = $1`
+		query = `SELECT id, name, description, trainer_id, client_id, is_template, created_at FROM programs WHERE client_id IN (SELECT id FROM clients WHERE user_id = $1)`
 	}
 
 	rows, err := r.db.Query(query, userID)
@@ -90,13 +99,48 @@ func (r *trainingRepository) GetProgramsByUserID(userID int, role string) ([]mod
 	return programs, nil
 }
 
+// ScheduleList ตัวกรอง/การเรียงของ GET /schedules (from/to = ช่วงของ start_time)
+var ScheduleList = &listing.Spec[models.Schedule]{
+	Sorts: map[string]listing.Sort[models.Schedule]{
+		"start_time": {Column: "start_time", Key: func(s models.Schedule) any { return s.StartTime }},
+		"created_at": {Column: "created_at", Key: func(s models.Schedule) any { return s.CreatedAt }},
+		"title":      {Column: "title", Key: func(s models.Schedule) any { return s.Title }},
+	},
+	DefaultSort: "start_time",
+	IDColumn:    "id",
+	ID:          func(s models.Schedule) int { return s.ID },
+	Filters: map[string]listing.Filter{
+		"status":    {Column: "status", Type: listing.TextList},
+		"from":      {Column: "start_time", Type: listing.Time, Op: ">="},
+		"to":        {Column: "start_time", Type: listing.Time, Op: "<"},
+		"client_id": {Column: "client_id", Type: listing.Int},
+		"series_id": {Column: "series_id", Type: listing.Int},
+	},
+}
+
+func (r *trainingRepository) ListSchedules(userID int, role string, p *listing.Params) (*listing.Page[models.Schedule], error) {
+	selectFrom := `SELECT id, title, trainer_id, client_id, start_time, end_time, status, series_id, original_start, is_exception, created_at, updated_at FROM schedules`
+	q := &listing.Query{}
+	if role == "trainer" {
+		q.Where("trainer_id = " + q.Arg(userID))
+	} else {
+		q.Where("client_id IN (SELECT id FROM clients WHERE user_id = " + q.Arg(userID) + ")")
+	}
+
+	return listing.Run(r.db, ScheduleList, p, selectFrom, q, func(rows *sql.Rows) (models.Schedule, error) {
+		var s models.Schedule
+		err := rows.Scan(&s.ID, &s.Title, &s.TrainerID, &s.ClientID, &s.StartTime, &s.EndTime, &s.Status, &s.SeriesID, &s.OriginalStart, &s.IsException, &s.CreatedAt, &s.UpdatedAt)
+		return s, err
+	})
+}
+
 // 3. ดึง Schedules
 func (r *trainingRepository) GetSchedulesByUserID(userID int, role string) ([]models.Schedule, error) {
 	var query string
 	if role == "trainer" {
-		query = `SELECT id, title, trainer_id, client_id, start_time, end_time, status FROM schedules WHERE trainer_id = $1`
+		query = `SELECT id, title, trainer_id, client_id, start_time, end_time, status, series_id, original_start, is_exception, created_at, updated_at FROM schedules WHERE trainer_id = $1`
 	} else {
-		query = `SELECT id, title, trainer_id, client_id, start_time, end_time, status FROM schedules WHERE client_id = $1`
+		query = `SELECT id, title, trainer_id, client_id, start_time, end_time, status, series_id, original_start, is_exception, created_at, updated_at FROM schedules WHERE client_id IN (SELECT id FROM clients WHERE user_id = $1)`
 	}
 
 	rows, err := r.db.Query(query, userID)
@@ -108,7 +152,7 @@ func (r *trainingRepository) GetSchedulesByUserID(userID int, role string) ([]mo
 	var schedules []models.Schedule
 	for rows.Next() {
 		var s models.Schedule
-		if err := rows.Scan(&s.ID, &s.Title, &s.TrainerID, &s.ClientID, &s.StartTime, &s.EndTime, &s.Status); err != nil {
+		if err := rows.Scan(&s.ID, &s.Title, &s.TrainerID, &s.ClientID, &s.StartTime, &s.EndTime, &s.Status, &s.SeriesID, &s.OriginalStart, &s.IsException, &s.CreatedAt, &s.UpdatedAt); err != nil {
 			return nil, err
 		}
 		schedules = append(schedules, s)
@@ -116,30 +160,41 @@ func (r *trainingRepository) GetSchedulesByUserID(userID int, role string) ([]mo
 	return schedules, nil
 }
 
+// AssignmentList ตัวกรอง/การเรียงของ GET /assignments (due_from/due_to = ช่วงของ due_date)
+var AssignmentList = &listing.Spec[models.Assignment]{
+	Sorts: map[string]listing.Sort[models.Assignment]{
+		"due_date":   {Column: "due_date", Key: func(a models.Assignment) any { return a.DueDate }},
+		"created_at": {Column: "created_at", Key: func(a models.Assignment) any { return a.CreatedAt }},
+		"title":      {Column: "title", Key: func(a models.Assignment) any { return a.Title }},
+		"status":     {Column: "status", Key: func(a models.Assignment) any { return a.Status }},
+	},
+	DefaultSort: "due_date",
+	IDColumn:    "id",
+	ID:          func(a models.Assignment) int { return a.ID },
+	Filters: map[string]listing.Filter{
+		"status": {Column: "status", Type: listing.TextList, Values: []string{
+			models.AssignmentPending, models.AssignmentInProgress, models.AssignmentSubmitted, models.AssignmentReviewed, models.AssignmentOverdue,
+		}},
+		"due_from":  {Column: "due_date", Type: listing.Time, Op: ">="},
+		"due_to":    {Column: "due_date", Type: listing.Time, Op: "<"},
+		"client_id": {Column: "client_id", Type: listing.Int},
+	},
+}
+
 // 4. ดึง Assignments
-func (r *trainingRepository) GetAssignmentsByUserID(userID int, role string) ([]models.Assignment, error) {
-	var query string
+func (r *trainingRepository) ListAssignments(userID int, role string, p *listing.Params) (*listing.Page[models.Assignment], error) {
+	q := &listing.Query{}
 	if role == "trainer" {
-		query = `SELECT id, title, description, client_id, trainer_id, due_date, status FROM assignments WHERE trainer_id = $1`
+		q.Where("trainer_id = " + q.Arg(userID))
 	} else {
-		query = `SELECT id, title, description, client_id, trainer_id, due_date, status FROM assignments WHERE client_id = $1`
+		q.Where("client_id IN (SELECT id FROM clients WHERE user_id = " + q.Arg(userID) + ")")
 	}
 
-	rows, err := r.db.Query(query, userID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var assignments []models.Assignment
-	for rows.Next() {
+	return listing.Run(r.db, AssignmentList, p, `SELECT `+assignmentColumns+` FROM assignments`, q, func(rows *sql.Rows) (models.Assignment, error) {
 		var a models.Assignment
-		if err := rows.Scan(&a.ID, &a.Title, &a.Description, &a.ClientID, &a.TrainerID, &a.DueDate, &a.Status); err != nil {
-			return nil, err
-		}
-		assignments = append(assignments, a)
-	}
-	return assignments, nil
+		err := scanAssignment(rows, &a)
+		return a, err
+	})
 }
 
 // 5. สร้างโปรแกรมการฝึกใหม่
@@ -160,23 +215,6 @@ func (r *trainingRepository) CreateProgram(program *models.Program) error {
 	).Scan(&program.ID, &program.CreatedAt, &program.UpdatedAt)
 }
 
-// 6. สร้างตารางนัดหมายใหม่
-func (r *trainingRepository) CreateSchedule(schedule *models.Schedule) error {
-	query := `
-		INSERT INTO schedules (title, trainer_id, client_id, start_time, end_time, status)
-		VALUES ($1, $2, $3, $4, $5, 'scheduled')
-		RETURNING id, created_at
-	`
-	return r.db.QueryRow(
-		query,
-		schedule.Title,
-		schedule.TrainerID,
-		schedule.ClientID,
-		schedule.StartTime,
-		schedule.EndTime,
-	).Scan(&schedule.ID, &schedule.CreatedAt)
-}
-
 // 7. สร้างงานมอบหมายใหม่ (Create Assignment)
 func (r *trainingRepository) CreateAssignment(assignment *models.Assignment) error {
 	query := `
@@ -196,57 +234,39 @@ func (r *trainingRepository) CreateAssignment(assignment *models.Assignment) err
 	).Scan(&assignment.ID, &assignment.CreatedAt)
 }
 
-// Update Schedule
-func (r *trainingRepository) UpdateSchedule(schedule *models.Schedule) error {
-	query := `
-		UPDATE schedules
-		SET title=$1, client_id=$2, start_time=$3, end_time=$4, status=$5, updated_at=NOW()
-		WHERE id=$6 AND trainer_id=$7
-		RETURNING updated_at
-	`
-	return r.db.QueryRow(
-		query,
-		schedule.Title,
-		schedule.ClientID,
-		schedule.StartTime,
-		schedule.EndTime,
-		schedule.Status,
-		schedule.ID,
-		schedule.TrainerID,
-	).Scan(&schedule.UpdatedAt)
-}
-
-// Delete Schedule
-func (r *trainingRepository) DeleteSchedule(id int, trainerID int) error {
-	query := `DELETE FROM schedules WHERE id=$1 AND trainer_id=$2`
-	res, err := r.db.Exec(query, id, trainerID)
-	if err != nil {
-		return err
+// Update Assignment (ไม่แก้สถานะ: เปลี่ยนผ่าน AssignmentService เท่านั้น)
+// ยกเว้นงาน overdue ที่ถูกเลื่อน due_date ออกไปในอนาคต จะกลับเป็น pending
+// และ due_date ใหม่ในอนาคตล้าง overdue_at (ถ้าเลยกำหนดใหม่อีก ตัวตรวจจะเปลี่ยนเป็น overdue ได้อีกครั้ง)
+func (r *trainingRepository) GetAssignmentClientID(id int) (int, error) {
+	var clientID int
+	err := r.db.QueryRow(`SELECT client_id FROM assignments WHERE id = $1`, id).Scan(&clientID)
+	if err == sql.ErrNoRows {
+		return 0, ErrAssignmentNotFound
 	}
-	if rows, _ := res.RowsAffected(); rows == 0 {
-		return sql.ErrNoRows
-	}
-	return nil
+	return clientID, err
 }
 
-// Update Assignment
 func (r *trainingRepository) UpdateAssignment(a *models.Assignment) error {
 	query := `
 		UPDATE assignments
-		SET title=$1, description=$2, client_id=$3, due_date=$4, status=$5, updated_at=NOW()
-		WHERE id=$6 AND trainer_id=$7
-		RETURNING updated_at
-	`
-	return r.db.QueryRow(
+		SET title=$1, description=$2, client_id=$3, due_date=$4, updated_at=NOW(),
+		    status = CASE WHEN status = 'overdue' AND $4 > NOW() THEN 'pending' ELSE status END,
+		    overdue_at = CASE WHEN $4 > NOW() THEN NULL ELSE overdue_at END
+		WHERE id=$5 AND trainer_id=$6
+		RETURNING ` + assignmentColumns
+	err := scanAssignment(r.db.QueryRow(
 		query,
 		a.Title,
 		a.Description,
 		a.ClientID,
 		a.DueDate,
-		a.Status,
 		a.ID,
 		a.TrainerID,
-	).Scan(&a.UpdatedAt)
+	), a)
+	if err == sql.ErrNoRows {
+		return ErrAssignmentNotFound
+	}
+	return err
 }
 
 // Delete Assignment
@@ -262,8 +282,14 @@ func (r *trainingRepository) DeleteAssignment(id int, trainerID int) error {
 	return nil
 }
 
-// 8. สร้างลูกค้าใหม่ (Create Client)
+// 8. สร้างลูกค้าใหม่ (Create Client) พร้อมบันทึกน้ำหนัก/ส่วนสูงเป็นการวัดครั้งแรกใน Transaction เดียว
 func (r *trainingRepository) CreateClient(client *models.Client) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
 		INSERT INTO clients (
             trainer_id, name, email, phone_number, 
@@ -272,9 +298,16 @@ func (r *trainingRepository) CreateClient(client *models.Client) error {
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, created_at
 	`
-	return r.db.QueryRow(
+	err = tx.QueryRow(
 		query,
 		client.TrainerID, client.Name, client.Email, client.Phone,
 		client.Gender, client.Height, client.Weight, client.Goal, client.BirthDate,
 	).Scan(&client.ID, &client.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if err := insertInitialMeasurement(tx, client); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"users/internal/config"
+	"users/internal/listing"
 	"users/internal/models"
 
 	_ "github.com/lib/pq"
@@ -14,7 +15,8 @@ import (
 
 // UserRepository interface ตามหลัก DIP
 type UserRepository interface {
-	GetAll() ([]models.User, error)
+	// ผู้ใช้ทั้งหมดแบบแบ่งหน้า (ตัวกรอง/การเรียงตาม UserList)
+	List(p *listing.Params) (*listing.Page[models.User], error)
 	GetByID(id int) (*models.User, error)
 	Create(name, email string) (*models.User, error)
 	Update(id int, name, email string) (*models.User, error)
@@ -58,28 +60,37 @@ func CheckDBConnection(db *sql.DB) error {
 	return db.Ping()
 }
 
-func (r *userRepository) GetAll() ([]models.User, error) {
-	rows, err := r.db.Query("SELECT id, name, email, created_at, updated_at FROM users ORDER BY id")
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+// UserList ตัวกรอง/การเรียงของ GET /users
+var UserList = &listing.Spec[models.User]{
+	Sorts: map[string]listing.Sort[models.User]{
+		"id":         {Column: "id", Key: func(u models.User) any { return u.ID }},
+		"name":       {Column: "name", Key: func(u models.User) any { return u.Name }},
+		"email":      {Column: "email", Key: func(u models.User) any { return u.Email }},
+		"created_at": {Column: "created_at", Key: func(u models.User) any { return u.CreatedAt }},
+	},
+	DefaultSort: "id",
+	IDColumn:    "id",
+	ID:          func(u models.User) int { return u.ID },
+	Filters: map[string]listing.Filter{
+		"q":        {Column: "(name || ' ' || email)", Type: listing.Search},
+		"role":     {Column: "role", Type: listing.TextList, Values: []string{"admin", "trainer", "client"}},
+		"verified": {Column: "verified", Type: listing.Bool},
+	},
+}
 
-	var users []models.User
-	for rows.Next() {
+func (r *userRepository) List(p *listing.Params) (*listing.Page[models.User], error) {
+	selectFrom := "SELECT id, name, email, role, verified, created_at, updated_at FROM users"
+	return listing.Run(r.db, UserList, p, selectFrom, &listing.Query{}, func(rows *sql.Rows) (models.User, error) {
 		var u models.User
-		if err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.CreatedAt, &u.UpdatedAt); err != nil {
-			return nil, err
-		}
-		users = append(users, u)
-	}
-	return users, rows.Err()
+		err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.Role, &u.Verified, &u.CreatedAt, &u.UpdatedAt)
+		return u, err
+	})
 }
 
 func (r *userRepository) GetByID(id int) (*models.User, error) {
 	var u models.User
-	err := r.db.QueryRow("SELECT id, name, email, created_at, updated_at FROM users WHERE id=$1", id).
-		Scan(&u.ID, &u.Name, &u.Email, &u.CreatedAt, &u.UpdatedAt)
+	err := r.db.QueryRow("SELECT id, name, email, role, verified, created_at, updated_at FROM users WHERE id=$1", id).
+		Scan(&u.ID, &u.Name, &u.Email, &u.Role, &u.Verified, &u.CreatedAt, &u.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, errors.New("not found")
@@ -105,9 +116,10 @@ func (r *userRepository) Create(name, email string) (*models.User, error) {
 func (r *userRepository) Update(id int, name, email string) (*models.User, error) {
 	var u models.User
 	err := r.db.QueryRow(
-		"UPDATE users SET name=$1, email=$2, updated_at=now() WHERE id=$3 RETURNING id, name, email, created_at, updated_at",
+		// เปลี่ยนอีเมลแล้วต้องยืนยันใหม่
+		"UPDATE users SET name=$1, email=$2, verified = verified AND email = $2, updated_at=now() WHERE id=$3 RETURNING id, name, email, verified, created_at, updated_at",
 		name, email, id,
-	).Scan(&u.ID, &u.Name, &u.Email, &u.CreatedAt, &u.UpdatedAt)
+	).Scan(&u.ID, &u.Name, &u.Email, &u.Verified, &u.CreatedAt, &u.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, errors.New("not found")
@@ -135,9 +147,9 @@ func (r *userRepository) CreateUser(user models.User, hashedPassword string) (*m
 
 	// (แก้ไข SQL ให้ INSERT ลงคอลัมน์ใหม่ด้วย)
 	err := r.db.QueryRow(
-		"INSERT INTO users (name, email, password_hash, role) VALUES ($1, $2, $3, $4) RETURNING id, name, email, role, created_at, updated_at",
-		user.Name, user.Email, hashedPassword, user.Role,
-	).Scan(&u.ID, &u.Name, &u.Email, &u.Role, &u.CreatedAt, &u.UpdatedAt)
+		"INSERT INTO users (name, email, password_hash, role, verified) VALUES ($1, $2, $3, $4, $5) RETURNING id, name, email, role, verified, created_at, updated_at",
+		user.Name, user.Email, hashedPassword, user.Role, user.Verified,
+	).Scan(&u.ID, &u.Name, &u.Email, &u.Role, &u.Verified, &u.CreatedAt, &u.UpdatedAt)
 
 	if err != nil {
 		return nil, err
@@ -150,8 +162,8 @@ func (r *userRepository) GetUserByEmail(email string) (*models.User, error) {
 	var u models.User
 
 	// (แก้ไข SQL ให้ SELECT คอลัมน์ใหม่มาด้วย)
-	err := r.db.QueryRow("SELECT id, name, email, password_hash, role, created_at, updated_at FROM users WHERE email=$1", email).
-		Scan(&u.ID, &u.Name, &u.Email, &u.PasswordHash, &u.Role, &u.CreatedAt, &u.UpdatedAt)
+	err := r.db.QueryRow("SELECT id, name, email, password_hash, role, verified, created_at, updated_at FROM users WHERE email=$1", email).
+		Scan(&u.ID, &u.Name, &u.Email, &u.PasswordHash, &u.Role, &u.Verified, &u.CreatedAt, &u.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, errors.New("user not found")
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"users/internal/models"
+)
+
+// ErrUserTokenInvalid token ไม่มีอยู่ ใช้ไปแล้ว หรือหมดอายุ (ไม่แยกกรณีเพื่อไม่ให้เดา token ได้)
+var ErrUserTokenInvalid = errors.New("invalid or expired token")
+
+type UserTokenRepository interface {
+	// สร้าง token ใหม่ และยกเลิก token เดิมที่ยังไม่ใช้ของ purpose เดียวกัน (ใช้ได้ทีละ 1 ลิงก์)
+	CreateUserToken(t *models.UserToken) error
+	// ใช้ token ยืนยันอีเมล แล้วตั้ง users.verified = TRUE คืน id ของผู้ใช้
+	VerifyEmail(tokenHash string) (int, error)
+	// ใช้ token รีเซ็ตรหัสผ่าน เปลี่ยนรหัสผ่าน (ยืนยันอีเมลไปด้วย) และยกเลิก Refresh Token ทั้งหมดของผู้ใช้
+	ResetPassword(tokenHash, passwordHash string) (int, error)
+}
+
+type userTokenRepository struct {
+	db *sql.DB
+}
+
+func NewUserTokenRepository(db *sql.DB) UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
+
+func (r *userTokenRepository) CreateUserToken(t *models.UserToken) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE user_tokens SET used_at = NOW() WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`,
+		t.UserID, t.Purpose); err != nil {
+		return err
+	}
+	err = tx.QueryRow(`
+		INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`,
+		t.UserID, t.Purpose, t.TokenHash, t.ExpiresAt,
+	).Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// consumeUserToken ปิด token (ใช้ได้ครั้งเดียว: UPDATE ... WHERE used_at IS NULL กัน request ซ้อนกัน)
+func consumeUserToken(tx *sql.Tx, tokenHash, purpose string) (int, error) {
+	var userID int
+	err := tx.QueryRow(`
+		UPDATE user_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id`, tokenHash, purpose).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, ErrUserTokenInvalid
+	}
+	return userID, err
+}
+
+func (r *userTokenRepository) VerifyEmail(tokenHash string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	userID, err := consumeUserToken(tx, tokenHash, models.TokenPurposeVerifyEmail)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`UPDATE users SET verified = TRUE, updated_at = NOW() WHERE id = $1`, userID); err != nil {
+		return 0, err
+	}
+	return userID, tx.Commit()
+}
+
+func (r *userTokenRepository) ResetPassword(tokenHash, passwordHash string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	userID, err := consumeUserToken(tx, tokenHash, models.TokenPurposeResetPassword)
+	if err != nil {
+		return 0, err
+	}
+	// ได้ลิงก์จากอีเมล จึงถือว่ายืนยันอีเมลแล้วด้วย
+	if _, err := tx.Exec(`UPDATE users SET password_hash = $1, verified = TRUE, updated_at = NOW() WHERE id = $2`,
+		passwordHash, userID); err != nil {
+		return 0, err
+	}
+	// ออกจากระบบทุกอุปกรณ์ (กรณีรหัสผ่านเดิมรั่ว)
+	if _, err := tx.Exec(`UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`, userID); err != nil {
+		return 0, err
+	}
+	return userID, tx.Commit()
+}
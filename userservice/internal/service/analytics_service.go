@@ -0,0 +1,187 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"users/internal/models"
+	"users/internal/repository"
+)
+
+// สูตรประมาณค่า 1RM
+const (
+	FormulaEpley   = "epley"
+	FormulaBrzycki = "brzycki"
+)
+
+// ช่วงเวลาที่ใช้รวมสถิติ
+const (
+	BucketWeek  = "week"
+	BucketMonth = "month"
+)
+
+var (
+	ErrInvalidFormula = errors.New("formula must be one of: epley, brzycki")
+	ErrInvalidBucket  = errors.New("bucket must be one of: week, month")
+)
+
+// ProgressQuery ตัวเลือกของรายงานความก้าวหน้า (From/To เป็น nil = ไม่จำกัดช่วง)
+type ProgressQuery struct {
+	ExerciseID int
+	Formula    string
+	Bucket     string
+	From       *time.Time
+	To         *time.Time
+	Timezone   string // IANA เช่น Asia/Bangkok ใช้แบ่งสัปดาห์/เดือน ("" = ค่าเริ่มต้นของระบบ)
+}
+
+type AnalyticsService interface {
+	GetExerciseProgress(clientID int, q ProgressQuery) (*models.ProgressReport, error)
+	// Location แปลง tz (IANA, "" = ค่าเริ่มต้นของระบบ) ใช้ตีความวันที่ from/to ให้ตรงกับการแบ่งช่วง
+	Location(tz string) (*time.Location, error)
+}
+
+type analyticsService struct {
+	sessionRepo     repository.SessionRepository
+	defaultTimezone string
+}
+
+// defaultTimezone ใช้แบ่งช่วงเวลาเมื่อไม่ระบุ tz (ค่าเดียวกับ CALENDAR_TIMEZONE ของฟีดปฏิทิน)
+func NewAnalyticsService(sessionRepo repository.SessionRepository, defaultTimezone string) AnalyticsService {
+	return &analyticsService{sessionRepo: sessionRepo, defaultTimezone: defaultTimezone}
+}
+
+// EstimateOneRM ประมาณค่า 1RM จากน้ำหนักและจำนวนครั้ง
+// Epley: w * (1 + r/30), Brzycki: w * 36 / (37 - r); 1 ครั้ง = น้ำหนักจริง
+func EstimateOneRM(formula string, weightKg float64, reps int) float64 {
+	if weightKg <= 0 || reps <= 0 {
+		return 0
+	}
+	if reps == 1 {
+		return weightKg
+	}
+	switch formula {
+	case FormulaBrzycki:
+		// สูตร Brzycki ใช้ไม่ได้เมื่อ reps >= 37
+		if reps >= 37 {
+			return 0
+		}
+		return weightKg * 36 / float64(37-reps)
+	default:
+		return weightKg * (1 + float64(reps)/30)
+	}
+}
+
+func (s *analyticsService) GetExerciseProgress(clientID int, q ProgressQuery) (*models.ProgressReport, error) {
+	if q.Formula == "" {
+		q.Formula = FormulaEpley
+	}
+	if q.Bucket == "" {
+		q.Bucket = BucketWeek
+	}
+	if q.Formula != FormulaEpley && q.Formula != FormulaBrzycki {
+		return nil, ErrInvalidFormula
+	}
+	if q.Bucket != BucketWeek && q.Bucket != BucketMonth {
+		return nil, ErrInvalidBucket
+	}
+	loc, err := s.Location(q.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	sets, err := s.sessionRepo.GetClientExerciseSets(clientID, q.ExerciseID, q.From, q.To)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ProgressReport{
+		ClientID:   clientID,
+		ExerciseID: q.ExerciseID,
+		Formula:    q.Formula,
+		Bucket:     q.Bucket,
+		Timezone:   loc.String(),
+		Points:     buildProgressPoints(sets, q.Formula, q.Bucket, loc),
+	}
+	return report, nil
+}
+
+func (s *analyticsService) Location(tz string) (*time.Location, error) {
+	if tz == "" {
+		tz = s.defaultTimezone
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTimezone, tz)
+	}
+	return loc, nil
+}
+
+// buildProgressPoints รวมเซต (ที่เรียงตามเวลาแล้ว) เป็นจุดข้อมูลรายสัปดาห์/รายเดือน ตามเวลาท้องถิ่นของ loc
+func buildProgressPoints(sets []models.PerformedSet, formula, bucket string, loc *time.Location) []models.ProgressPoint {
+	points := []models.ProgressPoint{}
+
+	var (
+		current  *models.ProgressPoint
+		sessions map[int]bool
+		rpeSum   int
+		rpeCount int
+	)
+	flush := func() {
+		if current == nil {
+			return
+		}
+		current.Sessions = len(sessions)
+		current.TotalVolumeKg = round2(current.TotalVolumeKg)
+		current.EstimatedOneRM = round2(current.EstimatedOneRM)
+		if rpeCount > 0 {
+			avg := round2(float64(rpeSum) / float64(rpeCount))
+			current.AverageRPE = &avg
+		}
+		points = append(points, *current)
+	}
+
+	for i := range sets {
+		set := sets[i]
+		start := bucketStart(set.PerformedAt, bucket, loc)
+		if current == nil || !current.PeriodStart.Equal(start) {
+			flush()
+			current = &models.ProgressPoint{PeriodStart: start}
+			sessions = map[int]bool{}
+			rpeSum, rpeCount = 0, 0
+		}
+
+		sessions[set.ScheduleID] = true
+		current.Sets++
+		current.TotalVolumeKg += set.WeightKg * float64(set.Reps)
+		// RPE = 0 หมายถึงไม่ได้บันทึก จึงไม่นำมาเฉลี่ย
+		if set.RPE > 0 {
+			rpeSum += set.RPE
+			rpeCount++
+		}
+		if e1rm := EstimateOneRM(formula, set.WeightKg, set.Reps); current.BestSet == nil || e1rm > current.EstimatedOneRM {
+			current.EstimatedOneRM = e1rm
+			current.BestSet = &set
+		}
+	}
+	flush()
+	return points
+}
+
+// bucketStart เที่ยงคืนของวันแรกในช่วง (สัปดาห์เริ่มวันจันทร์) ตามเวลาท้องถิ่นของ loc
+// เช่น Session 06:00 วันจันทร์ที่กรุงเทพฯ (23:00 วันอาทิตย์ UTC) ต้องอยู่ในสัปดาห์ใหม่
+func bucketStart(t time.Time, bucket string, loc *time.Location) time.Time {
+	t = t.In(loc)
+	if bucket == BucketMonth {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	}
+	offset := (int(t.Weekday()) + 6) % 7 // จันทร์ = 0
+	// time.Date ปรับวันที่ให้เอง และคิดเที่ยงคืนตามเขตเวลา (รองรับวันที่เปลี่ยน DST)
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, loc)
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
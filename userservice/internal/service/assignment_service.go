@@ -0,0 +1,193 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"users/internal/models"
+	"users/internal/notify"
+	"users/internal/repository"
+)
+
+// จำนวนไฟล์แนบสูงสุดต่อการส่งงานหนึ่งครั้ง
+const maxAssignmentAttachments = 10
+
+var (
+	ErrInvalidAssignmentTransition = errors.New("invalid assignment status transition")
+	ErrInvalidSubmission           = errors.New("invalid assignment submission")
+)
+
+// assignmentTransitions สถานะถัดไปที่อนุญาตจากแต่ละสถานะ
+//
+//	pending     -> in_progress (ลูกค้าเริ่มทำ), submitted, overdue (ระบบ)
+//	in_progress -> submitted, overdue (ระบบ)
+//	submitted   -> reviewed (ตรวจผ่าน), in_progress (ส่งกลับให้แก้)
+//	overdue     -> in_progress, submitted (ส่งล่าช้า)
+//	reviewed    -> (สิ้นสุด)
+//
+// ตัวตรวจเปลี่ยนเป็น overdue ได้ครั้งเดียวต่อ due_date (overdue_at) และไม่เปลี่ยนงานที่เคยส่งแล้ว
+// งานที่เริ่มทำหลังเลยกำหนด หรือถูกส่งกลับให้แก้ จึงคงเป็น in_progress จนกว่าจะส่งงาน
+var assignmentTransitions = map[string][]string{
+	models.AssignmentPending:    {models.AssignmentInProgress, models.AssignmentSubmitted, models.AssignmentOverdue},
+	models.AssignmentInProgress: {models.AssignmentSubmitted, models.AssignmentOverdue},
+	models.AssignmentSubmitted:  {models.AssignmentReviewed, models.AssignmentInProgress},
+	models.AssignmentOverdue:    {models.AssignmentInProgress, models.AssignmentSubmitted},
+}
+
+// CanTransitionAssignment ตรวจว่าเปลี่ยนสถานะจาก from เป็น to ได้หรือไม่
+func CanTransitionAssignment(from, to string) bool {
+	for _, next := range assignmentTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// SubmitAssignmentRequest ข้อมูลที่ลูกค้าส่งงาน
+type SubmitAssignmentRequest struct {
+	Notes       string                        `json:"notes"`
+	Attachments []models.AssignmentAttachment `json:"attachments"`
+}
+
+// ReviewAssignmentRequest ผลตรวจของเทรนเนอร์ (RequestRevision = ส่งกลับให้ลูกค้าแก้แทนการปิดงาน)
+type ReviewAssignmentRequest struct {
+	Feedback        string `json:"feedback"`
+	RequestRevision bool   `json:"request_revision"`
+}
+
+type AssignmentService interface {
+	GetAssignment(id int) (*models.Assignment, error)
+	// ลูกค้าเริ่มทำงาน (pending/overdue -> in_progress)
+	StartAssignment(id int) (*models.Assignment, error)
+	// ลูกค้าส่งงานพร้อมโน้ตและไฟล์แนบ (-> submitted)
+	SubmitAssignment(id int, req SubmitAssignmentRequest) (*models.Assignment, error)
+	// เทรนเนอร์ตรวจงานที่ส่งแล้ว (submitted -> reviewed หรือ in_progress)
+	ReviewAssignment(id int, req ReviewAssignmentRequest) (*models.Assignment, error)
+	// เปลี่ยนงานที่เลยกำหนดเป็น overdue คืนงานที่ถูกเปลี่ยน
+	MarkOverdue(now time.Time) ([]models.Assignment, error)
+}
+
+type assignmentService struct {
+	repo repository.AssignmentRepository
+}
+
+func NewAssignmentService(repo repository.AssignmentRepository) AssignmentService {
+	return &assignmentService{repo: repo}
+}
+
+func (s *assignmentService) GetAssignment(id int) (*models.Assignment, error) {
+	return s.repo.GetAssignmentByID(id)
+}
+
+func (s *assignmentService) StartAssignment(id int) (*models.Assignment, error) {
+	current, err := s.repo.GetAssignmentByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.apply(current, models.AssignmentInProgress, nil, nil, nil)
+}
+
+func (s *assignmentService) SubmitAssignment(id int, req SubmitAssignmentRequest) (*models.Assignment, error) {
+	if len(req.Attachments) > maxAssignmentAttachments {
+		return nil, fmt.Errorf("%w: at most %d attachments", ErrInvalidSubmission, maxAssignmentAttachments)
+	}
+	for i := range req.Attachments {
+		f := &req.Attachments[i]
+		f.URL = strings.TrimSpace(f.URL)
+		u, err := url.Parse(f.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("%w: attachment url must be an http(s) URL", ErrInvalidSubmission)
+		}
+		f.FileName = strings.TrimSpace(f.FileName)
+		if f.FileName == "" {
+			f.FileName = u.Path[strings.LastIndex(u.Path, "/")+1:]
+		}
+	}
+
+	current, err := s.repo.GetAssignmentByID(id)
+	if err != nil {
+		return nil, err
+	}
+	// แจ้งเทรนเนอร์ว่ามีงานรอตรวจ
+	messages := []models.OutboxMessage{{
+		UserID:   current.TrainerID,
+		Template: notify.TemplateAssignmentSubmitted,
+		Payload:  map[string]any{"title": current.Title, "client_name": current.ClientName},
+	}}
+	return s.apply(current, models.AssignmentSubmitted, func(a *models.Assignment) {
+		now := time.Now()
+		a.SubmissionNotes = strings.TrimSpace(req.Notes)
+		a.SubmittedAt = &now
+	}, req.Attachments, messages)
+}
+
+func (s *assignmentService) ReviewAssignment(id int, req ReviewAssignmentRequest) (*models.Assignment, error) {
+	to := models.AssignmentReviewed
+	if req.RequestRevision {
+		to = models.AssignmentInProgress
+	}
+	current, err := s.repo.GetAssignmentByID(id)
+	if err != nil {
+		return nil, err
+	}
+	// ส่งกลับให้แก้ได้เฉพาะงานที่ส่งแล้ว (in_progress จาก pending/overdue เป็นของลูกค้า)
+	if current.Status != models.AssignmentSubmitted {
+		return nil, fmt.Errorf("%w: only submitted assignments can be reviewed (current: %s)", ErrInvalidAssignmentTransition, current.Status)
+	}
+
+	feedback := strings.TrimSpace(req.Feedback)
+	// แจ้งลูกค้า (ถ้ามีบัญชีเชื่อมอยู่) ว่างานถูกตรวจ/ส่งกลับ
+	var messages []models.OutboxMessage
+	if current.ClientUserID != nil {
+		messages = append(messages, models.OutboxMessage{
+			UserID:   *current.ClientUserID,
+			Template: notify.TemplateAssignmentReviewed,
+			Payload:  map[string]any{"title": current.Title, "feedback": feedback, "revision_requested": req.RequestRevision},
+		})
+	}
+	return s.apply(current, to, func(a *models.Assignment) {
+		now := time.Now()
+		a.Feedback = feedback
+		a.ReviewedAt = &now
+	}, nil, messages)
+}
+
+func (s *assignmentService) MarkOverdue(now time.Time) ([]models.Assignment, error) {
+	return s.repo.MarkOverdueAssignments(now)
+}
+
+// apply ตรวจการเปลี่ยนสถานะกับ assignmentTransitions แล้วบันทึกพร้อมไฟล์แนบและการแจ้งเตือนใน Transaction เดียว
+func (s *assignmentService) apply(a *models.Assignment, to string, update func(a *models.Assignment), attachments []models.AssignmentAttachment, notifications []models.OutboxMessage) (*models.Assignment, error) {
+	from := a.Status
+	if !CanTransitionAssignment(from, to) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidAssignmentTransition, from, to)
+	}
+	a.Status = to
+	if update != nil {
+		update(a)
+	}
+	if err := s.repo.TransitionAssignment(a, from, attachments, notifications); err != nil {
+		return nil, err
+	}
+	a.Attachments = append(a.Attachments, attachments...)
+	return a, nil
+}
+
+// StartOverdueChecker ตรวจงานเลยกำหนดทุก interval (ทำงานทันทีหนึ่งครั้งตอนเริ่ม) คืนฟังก์ชันสำหรับหยุด
+func StartOverdueChecker(s AssignmentService, interval time.Duration) (stop func()) {
+	return runEvery(interval, func() {
+		marked, err := s.MarkOverdue(time.Now())
+		if err != nil {
+			log.Printf("overdue checker: %v", err)
+			return
+		}
+		if len(marked) > 0 {
+			log.Printf("overdue checker: marked %d assignment(s) overdue", len(marked))
+		}
+	})
+}
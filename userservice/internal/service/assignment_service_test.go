@@ -0,0 +1,186 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"users/internal/models"
+	"users/internal/repository"
+)
+
+func TestCanTransitionAssignment(t *testing.T) {
+	const (
+		pending    = models.AssignmentPending
+		inProgress = models.AssignmentInProgress
+		submitted  = models.AssignmentSubmitted
+		reviewed   = models.AssignmentReviewed
+		overdue    = models.AssignmentOverdue
+	)
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{pending, inProgress, true},
+		{pending, submitted, true},
+		{pending, overdue, true},
+		{pending, reviewed, false},
+		{inProgress, submitted, true},
+		{inProgress, overdue, true},
+		{inProgress, pending, false},
+		{inProgress, reviewed, false},
+		{submitted, reviewed, true},
+		{submitted, inProgress, true}, // ส่งกลับให้แก้
+		{submitted, overdue, false},
+		{submitted, pending, false},
+		{overdue, inProgress, true}, // เริ่มทำงานล่าช้า
+		{overdue, submitted, true},  // ส่งล่าช้า
+		{overdue, reviewed, false},
+		{overdue, pending, false},
+		{reviewed, inProgress, false},
+		{reviewed, submitted, false},
+		{reviewed, overdue, false},
+		{pending, pending, false},
+		{"unknown", pending, false},
+	}
+	for _, tt := range tests {
+		if got := CanTransitionAssignment(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransitionAssignment(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+// fakeAssignmentRepo เก็บงานไว้ในหน่วยความจำ และเลือกงานใน MarkOverdueAssignments
+// ตามเงื่อนไขเดียวกับ SQL (pending/in_progress, due_date < now, overdue_at และ submitted_at IS NULL)
+type fakeAssignmentRepo struct {
+	repository.AssignmentRepository
+	assignments map[int]*models.Assignment
+}
+
+func (r *fakeAssignmentRepo) GetAssignmentByID(id int) (*models.Assignment, error) {
+	a, ok := r.assignments[id]
+	if !ok {
+		return nil, repository.ErrAssignmentNotFound
+	}
+	copied := *a
+	return &copied, nil
+}
+
+func (r *fakeAssignmentRepo) TransitionAssignment(a *models.Assignment, from string, attachments []models.AssignmentAttachment, notifications []models.OutboxMessage) error {
+	stored := r.assignments[a.ID]
+	if stored.Status != from {
+		return repository.ErrAssignmentStatusChanged
+	}
+	copied := *a
+	copied.OverdueAt = stored.OverdueAt
+	r.assignments[a.ID] = &copied
+	return nil
+}
+
+func (r *fakeAssignmentRepo) MarkOverdueAssignments(now time.Time) ([]models.Assignment, error) {
+	var marked []models.Assignment
+	for _, a := range r.assignments {
+		if (a.Status == models.AssignmentPending || a.Status == models.AssignmentInProgress) &&
+			a.DueDate.Before(now) && a.OverdueAt == nil && a.SubmittedAt == nil {
+			a.Status = models.AssignmentOverdue
+			a.OverdueAt = &now
+			marked = append(marked, *a)
+		}
+	}
+	return marked, nil
+}
+
+func TestOverdueCheckerDoesNotRevertLateWork(t *testing.T) {
+	due := time.Date(2024, 5, 1, 17, 0, 0, 0, time.UTC)
+	afterDue := due.Add(time.Hour)
+
+	type step struct {
+		name string
+		do   func(s AssignmentService) error
+		want string // สถานะหลังทำขั้นนี้
+	}
+	start := func(s AssignmentService) error { _, err := s.StartAssignment(1); return err }
+	submit := func(s AssignmentService) error {
+		_, err := s.SubmitAssignment(1, SubmitAssignmentRequest{Notes: "done"})
+		return err
+	}
+	revise := func(s AssignmentService) error {
+		_, err := s.ReviewAssignment(1, ReviewAssignmentRequest{RequestRevision: true})
+		return err
+	}
+	check := func(now time.Time) func(s AssignmentService) error {
+		return func(s AssignmentService) error { _, err := s.MarkOverdue(now); return err }
+	}
+
+	tests := []struct {
+		name   string
+		status string
+		steps  []step
+	}{
+		{
+			name:   "late start stays in progress",
+			status: models.AssignmentPending,
+			steps: []step{
+				{"checker", check(afterDue), models.AssignmentOverdue},
+				{"start", start, models.AssignmentInProgress},
+				{"checker again", check(afterDue.Add(time.Minute)), models.AssignmentInProgress},
+				{"submit", submit, models.AssignmentSubmitted},
+			},
+		},
+		{
+			name:   "revision after due date stays in progress",
+			status: models.AssignmentInProgress,
+			steps: []step{
+				{"submit", submit, models.AssignmentSubmitted},
+				{"checker while submitted", check(afterDue), models.AssignmentSubmitted},
+				{"request revision", revise, models.AssignmentInProgress},
+				{"checker", check(afterDue.Add(time.Minute)), models.AssignmentInProgress},
+			},
+		},
+		{
+			name:   "overdue once then revision",
+			status: models.AssignmentInProgress,
+			steps: []step{
+				{"checker", check(afterDue), models.AssignmentOverdue},
+				{"late submit", submit, models.AssignmentSubmitted},
+				{"request revision", revise, models.AssignmentInProgress},
+				{"checker", check(afterDue.Add(time.Minute)), models.AssignmentInProgress},
+			},
+		},
+		{
+			name:   "not yet due",
+			status: models.AssignmentPending,
+			steps: []step{
+				{"checker before due", check(due.Add(-time.Minute)), models.AssignmentPending},
+				{"checker at due", check(due), models.AssignmentPending},
+				{"checker after due", check(afterDue), models.AssignmentOverdue},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeAssignmentRepo{assignments: map[int]*models.Assignment{
+				1: {ID: 1, Title: "Food diary", TrainerID: 2, DueDate: due, Status: tt.status},
+			}}
+			s := NewAssignmentService(repo)
+			for _, st := range tt.steps {
+				if err := st.do(s); err != nil {
+					t.Fatalf("%s: %v", st.name, err)
+				}
+				if got := repo.assignments[1].Status; got != st.want {
+					t.Fatalf("%s: status = %s, want %s", st.name, got, st.want)
+				}
+			}
+		})
+	}
+}
+
+func TestReviewAssignmentRequiresSubmitted(t *testing.T) {
+	for _, status := range []string{models.AssignmentPending, models.AssignmentInProgress, models.AssignmentOverdue, models.AssignmentReviewed} {
+		repo := &fakeAssignmentRepo{assignments: map[int]*models.Assignment{1: {ID: 1, Status: status}}}
+		_, err := NewAssignmentService(repo).ReviewAssignment(1, ReviewAssignmentRequest{RequestRevision: true})
+		if !errors.Is(err, ErrInvalidAssignmentTransition) {
+			t.Errorf("review %s: err = %v, want ErrInvalidAssignmentTransition", status, err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"users/internal/ical"
+	"users/internal/models"
+	"users/internal/repository"
+)
+
+const calendarProdID = "-//Fitness Backend//Schedules//TH"
+
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+type CalendarService interface {
+	// สร้าง (หรือหมุนใหม่) token ของผู้ใช้ คืน token แบบ plain ซึ่งแสดงได้ครั้งเดียว
+	CreateFeedToken(userID int) (*models.CalendarFeed, string, error)
+	GetFeed(userID int) (*models.CalendarFeed, error)
+	RevokeFeed(userID int) error
+	// สร้างไฟล์ ICS จาก token (tz ว่าง = ใช้ค่าเริ่มต้นของระบบ)
+	RenderFeed(token, tz string) ([]byte, error)
+}
+
+type calendarService struct {
+	repo            repository.CalendarFeedRepository
+	trainingRepo    repository.TrainingRepository
+	uidDomain       string
+	defaultTimezone string
+}
+
+// uidDomain ใช้ต่อท้าย UID ของ VEVENT (เช่น schedule-12@fitness.example.com) ห้ามเปลี่ยนหลังเปิดใช้
+// ไม่เช่นนั้นแอปปฏิทินจะมองเป็นนัดใหม่ทั้งหมด
+func NewCalendarService(repo repository.CalendarFeedRepository, trainingRepo repository.TrainingRepository, uidDomain, defaultTimezone string) CalendarService {
+	return &calendarService{repo: repo, trainingRepo: trainingRepo, uidDomain: uidDomain, defaultTimezone: defaultTimezone}
+}
+
+func (s *calendarService) CreateFeedToken(userID int) (*models.CalendarFeed, string, error) {
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return nil, "", errors.New("failed to create calendar token")
+	}
+	feed := &models.CalendarFeed{UserID: userID, TokenHash: hashToken(token)}
+	if err := s.repo.UpsertCalendarFeed(feed); err != nil {
+		return nil, "", err
+	}
+	return feed, token, nil
+}
+
+func (s *calendarService) GetFeed(userID int) (*models.CalendarFeed, error) {
+	return s.repo.GetCalendarFeedByUserID(userID)
+}
+
+func (s *calendarService) RevokeFeed(userID int) error {
+	return s.repo.DeleteCalendarFeed(userID)
+}
+
+// RenderFeed เทรนเนอร์ได้นัดทั้งหมดของตัวเอง ลูกค้าได้เฉพาะนัดของตัวเอง (ตาม GetSchedulesByUserID)
+// เวลาของนัดเขียนเป็น UTC เสมอ (ไม่มี VTIMEZONE) ซึ่งระบุเวลาได้ถูกต้องโดยไม่ขึ้นกับ tz;
+// tz ใช้เป็นเพียง X-WR-TIMEZONE ให้แอปปฏิทินเลือก Time Zone สำหรับแสดงผล
+func (s *calendarService) RenderFeed(token, tz string) ([]byte, error) {
+	if tz == "" {
+		tz = s.defaultTimezone
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTimezone, tz)
+	}
+
+	user, err := s.repo.GetCalendarFeedOwner(hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := s.trainingRepo.GetSchedulesByUserID(user.ID, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := ical.Calendar{
+		ProdID:   calendarProdID,
+		Name:     "ตารางฝึก - " + user.Name,
+		Timezone: tz,
+		Events:   make([]ical.Event, 0, len(schedules)),
+	}
+	for _, sc := range schedules {
+		cal.Events = append(cal.Events, s.scheduleEvent(sc))
+	}
+	return cal.Encode(), nil
+}
+
+func (s *calendarService) scheduleEvent(sc models.Schedule) ical.Event {
+	e := ical.Event{
+		// UID ผูกกับ id ของนัด (คงที่แม้แก้เวลา/ชื่อ) แอปปฏิทินจึงอัปเดตนัดเดิมแทนการสร้างซ้ำ
+		UID:          fmt.Sprintf("schedule-%d@%s", sc.ID, s.uidDomain),
+		Summary:      sc.Title,
+		Start:        sc.StartTime,
+		End:          sc.EndTime,
+		Status:       scheduleEventStatus(sc.Status),
+		Created:      sc.CreatedAt,
+		LastModified: sc.UpdatedAt,
+	}
+	if sc.Status == "completed" {
+		e.Description = "Completed"
+	}
+	return e
+}
+
+// scheduleEventStatus แปลงสถานะนัด -> STATUS ของ VEVENT (cancelled -> CANCELLED, ที่เหลือ -> CONFIRMED)
+func scheduleEventStatus(status string) string {
+	if status == "cancelled" {
+		return ical.StatusCancelled
+	}
+	return ical.StatusConfirmed
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"users/internal/models"
+	"users/internal/repository"
+)
+
+// อายุของลิงก์คำเชิญ
+const InvitationTTL = 7 * 24 * time.Hour
+
+var (
+	ErrInvitationExpired = errors.New("invitation expired")
+	ErrClientNotFound    = errors.New("client not found")
+	ErrInvitationNoEmail = errors.New("email is required to invite a client")
+)
+
+// AcceptInvitationRequest (ข้อมูลที่ลูกค้ากรอกตอนรับคำเชิญ; Email ใช้ตามคำเชิญ)
+type AcceptInvitationRequest struct {
+	FirstName string `json:"firstName" binding:"required"`
+	LastName  string `json:"lastName" binding:"required"`
+	Password  string `json:"password" binding:"required"`
+}
+
+type InvitationService interface {
+	// คืนค่า invitation + token แบบ plain (ส่งให้ลูกค้าได้ครั้งเดียว)
+	CreateInvitation(trainerID, clientID int, email string) (*models.ClientInvitation, string, error)
+	GetInvitation(token string) (*models.ClientInvitation, error)
+	AcceptInvitation(token string, req AcceptInvitationRequest) (*models.User, error)
+}
+
+type invitationService struct {
+	repo       repository.InvitationRepository
+	clientRepo repository.ClientRepository
+	// ส่งอีเมลยืนยันให้บัญชีที่สร้างจากคำเชิญ
+	users UserService
+}
+
+func NewInvitationService(repo repository.InvitationRepository, clientRepo repository.ClientRepository, users UserService) InvitationService {
+	return &invitationService{repo: repo, clientRepo: clientRepo, users: users}
+}
+
+func (s *invitationService) CreateInvitation(trainerID, clientID int, email string) (*models.ClientInvitation, string, error) {
+	// ตรวจว่า Client เป็นของเทรนเนอร์คนนี้
+	client, err := s.clientRepo.GetClientByID(clientID, trainerID)
+	if err != nil {
+		return nil, "", ErrClientNotFound
+	}
+	if client.UserID != nil {
+		return nil, "", repository.ErrClientAlreadyLinked
+	}
+
+	// ถ้าไม่ได้ระบุ Email มา ใช้ Email จากข้อมูล Client
+	email = strings.TrimSpace(email)
+	if email == "" && client.Email != nil {
+		email = strings.TrimSpace(*client.Email)
+	}
+	if email == "" {
+		return nil, "", ErrInvitationNoEmail
+	}
+
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return nil, "", errors.New("failed to create invitation token")
+	}
+
+	inv := &models.ClientInvitation{
+		ClientID:  clientID,
+		TrainerID: trainerID,
+		Email:     email,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(InvitationTTL),
+	}
+	if err := s.repo.CreateInvitation(inv); err != nil {
+		return nil, "", err
+	}
+	return inv, token, nil
+}
+
+func (s *invitationService) GetInvitation(token string) (*models.ClientInvitation, error) {
+	inv, err := s.repo.GetInvitationByTokenHash(hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if inv.AcceptedAt != nil {
+		return nil, repository.ErrInvitationUsed
+	}
+	if time.Now().After(inv.ExpiresAt) {
+		return nil, ErrInvitationExpired
+	}
+	return inv, nil
+}
+
+func (s *invitationService) AcceptInvitation(token string, req AcceptInvitationRequest) (*models.User, error) {
+	inv, err := s.GetInvitation(token)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Password) < minPasswordLength {
+		return nil, ErrPasswordTooShort
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), 10)
+	if err != nil {
+		return nil, errors.New("failed to hash password")
+	}
+
+	newUser := models.User{
+		Name:  strings.TrimSpace(req.FirstName + " " + req.LastName),
+		Email: inv.Email,
+		Role:  "client",
+	}
+	user, err := s.repo.AcceptInvitation(inv.ID, newUser, string(hashedPassword))
+	if err != nil {
+		return nil, err
+	}
+
+	// ลิงก์คำเชิญส่งให้เทรนเนอร์ ไม่ได้พิสูจน์ว่าผู้รับคำเชิญเป็นเจ้าของอีเมล: ต้องยืนยันอีเมลเหมือนการสมัครเอง
+	// (ส่งไม่สำเร็จไม่ทำให้รับคำเชิญไม่สำเร็จ ขอส่งใหม่ได้ภายหลัง)
+	if err := s.users.ResendVerification(user.Email); err != nil {
+		log.Printf("failed to send verification email to user %d: %v", user.ID, err)
+	}
+	return user, nil
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"users/internal/models"
+	"users/internal/notify"
+	"users/internal/repository"
+)
+
+// Mailer ส่งอีเมลเกี่ยวกับบัญชีผู้ใช้ (ยืนยันอีเมล/รีเซ็ตรหัสผ่าน)
+type Mailer interface {
+	SendVerificationEmail(user *models.User, link string) error
+	SendPasswordResetEmail(user *models.User, link string) error
+}
+
+type outboxMailer struct {
+	repo repository.NotificationRepository
+}
+
+// NewOutboxMailer ส่งผ่าน notification outbox ทางอีเมลเท่านั้น (ไม่สนการตั้งค่าการแจ้งเตือนของผู้ใช้)
+// จึงได้การ retry ของ Dispatcher และไม่ทำให้ request ช้าเพราะรอ SMTP
+func NewOutboxMailer(repo repository.NotificationRepository) Mailer {
+	return &outboxMailer{repo: repo}
+}
+
+func (m *outboxMailer) SendVerificationEmail(user *models.User, link string) error {
+	return m.send(user, notify.TemplateVerifyEmail, link, VerifyEmailTokenTTL.Hours())
+}
+
+func (m *outboxMailer) SendPasswordResetEmail(user *models.User, link string) error {
+	return m.send(user, notify.TemplateResetPassword, link, PasswordResetTokenTTL.Hours())
+}
+
+func (m *outboxMailer) send(user *models.User, template, link string, expiresHours float64) error {
+	return m.repo.Enqueue(models.OutboxMessage{
+		UserID:   user.ID,
+		Template: template,
+		Payload:  map[string]any{"link": link, "expires_hours": expiresHours},
+		Channels: []string{notify.ChannelEmail},
+	})
+}
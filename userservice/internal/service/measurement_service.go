@@ -0,0 +1,113 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"users/internal/models"
+	"users/internal/repository"
+)
+
+var ErrInvalidMeasurement = errors.New("invalid measurement")
+
+// key ของชนิดการวัด: ตัวพิมพ์เล็ก ตัวเลข และ _ (เช่น "calf", "neck_cm")
+var measurementKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+type MeasurementService interface {
+	CreateMeasurementType(trainerID int, t *models.MeasurementType) error
+	// trainerID คือเทรนเนอร์ของลูกค้า (ใช้หาชนิดการวัดที่สร้างเอง)
+	CreateMeasurement(trainerID int, m *models.BodyMeasurement) error
+	UpdateMeasurement(trainerID int, m *models.BodyMeasurement) error
+}
+
+type measurementService struct {
+	repo repository.MeasurementRepository
+}
+
+func NewMeasurementService(repo repository.MeasurementRepository) MeasurementService {
+	return &measurementService{repo: repo}
+}
+
+func (s *measurementService) CreateMeasurementType(trainerID int, t *models.MeasurementType) error {
+	t.Key = strings.ToLower(strings.TrimSpace(t.Key))
+	t.Name = strings.TrimSpace(t.Name)
+	t.Unit = strings.TrimSpace(t.Unit)
+	if !measurementKeyPattern.MatchString(t.Key) {
+		return fmt.Errorf("%w: key must start with a letter and contain only a-z, 0-9 and _", ErrInvalidMeasurement)
+	}
+	if t.Name == "" || t.Unit == "" {
+		return fmt.Errorf("%w: name and unit are required", ErrInvalidMeasurement)
+	}
+
+	// ห้ามใช้ key ซ้ำกับชนิดมาตรฐาน (ไม่งั้นค่าในกราฟจะปนกัน)
+	types, err := s.repo.GetMeasurementTypes(trainerID)
+	if err != nil {
+		return err
+	}
+	for _, existing := range types {
+		if existing.Key == t.Key {
+			return repository.ErrMeasurementTypeExists
+		}
+	}
+
+	t.TrainerID = &trainerID
+	return s.repo.CreateMeasurementType(t)
+}
+
+func (s *measurementService) CreateMeasurement(trainerID int, m *models.BodyMeasurement) error {
+	typeIDs, err := s.validateMeasurement(trainerID, m)
+	if err != nil {
+		return err
+	}
+	return s.repo.CreateMeasurement(m, typeIDs)
+}
+
+func (s *measurementService) UpdateMeasurement(trainerID int, m *models.BodyMeasurement) error {
+	typeIDs, err := s.validateMeasurement(trainerID, m)
+	if err != nil {
+		return err
+	}
+	return s.repo.UpdateMeasurement(m, typeIDs)
+}
+
+// validateMeasurement ตรวจว่าทุก key เป็นชนิดที่รู้จัก และค่าอยู่ในช่วงที่เก็บได้ คืน map key -> type id
+func (s *measurementService) validateMeasurement(trainerID int, m *models.BodyMeasurement) (map[string]int, error) {
+	if m.MeasuredAt.IsZero() {
+		return nil, fmt.Errorf("%w: measured_at is required", ErrInvalidMeasurement)
+	}
+	if len(m.Values) == 0 {
+		return nil, fmt.Errorf("%w: at least one value is required", ErrInvalidMeasurement)
+	}
+
+	types, err := s.repo.GetMeasurementTypes(trainerID)
+	if err != nil {
+		return nil, err
+	}
+	known := map[string]int{}
+	for _, t := range types {
+		known[t.Key] = t.ID
+	}
+
+	typeIDs := map[string]int{}
+	for key, value := range m.Values {
+		id, ok := known[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown measurement type %q", ErrInvalidMeasurement, key)
+		}
+		// weight/height ถูกสะท้อนไปที่ clients (NUMERIC(5,2)), ค่าอื่นเก็บได้ถึง NUMERIC(7,2)
+		limit := 99999.99
+		switch key {
+		case models.MeasurementWeight, models.MeasurementHeight:
+			limit = 999.99
+		case models.MeasurementBodyFat:
+			limit = 100
+		}
+		if value < 0 || value > limit {
+			return nil, fmt.Errorf("%w: %s must be between 0 and %g", ErrInvalidMeasurement, key, limit)
+		}
+		typeIDs[key] = id
+	}
+	return typeIDs, nil
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"users/internal/models"
+	"users/internal/notify"
+	"users/internal/repository"
+)
+
+// Dispatcher: จำนวนแถวต่อรอบ, เวลาจองแถว, จำนวนครั้งสูงสุด และช่วง Backoff (30s, 1m, 2m, ... สูงสุด 1h)
+const (
+	outboxBatchSize     = 50
+	outboxLease         = 5 * time.Minute
+	outboxMaxAttempts   = 8
+	outboxBackoffBase   = 30 * time.Second
+	outboxBackoffMax    = time.Hour
+	notificationTimeout = 30 * time.Second
+)
+
+// ขนาดหน้าของ Inbox
+const (
+	defaultInboxLimit = 50
+	maxInboxLimit     = 200
+)
+
+var ErrInvalidPreferences = errors.New("invalid notification preferences")
+
+// UpdatePreferencesRequest แก้เฉพาะฟิลด์ที่ส่งมา
+type UpdatePreferencesRequest struct {
+	Locale       *string `json:"locale"`
+	EmailEnabled *bool   `json:"email_enabled"`
+	SMSEnabled   *bool   `json:"sms_enabled"`
+}
+
+// ReminderWindows เตือนล่วงหน้าก่อนนัดเริ่ม / ก่อนงานครบกำหนด
+type ReminderWindows struct {
+	Schedule   time.Duration
+	Assignment time.Duration
+}
+
+type NotificationService interface {
+	// Inbox ของผู้ใช้ พร้อมจำนวนที่ยังไม่อ่าน
+	GetInbox(userID int, unreadOnly bool, limit int) ([]models.Notification, int, error)
+	MarkRead(userID, id int) error
+	MarkAllRead(userID int) (int64, error)
+	GetPreferences(userID int) (*models.NotificationPreferences, error)
+	UpdatePreferences(userID int, req UpdatePreferencesRequest) (*models.NotificationPreferences, error)
+
+	// Dispatch ส่งแถวใน outbox ที่ถึงเวลา 1 รอบ คืนจำนวนที่ส่งสำเร็จ
+	Dispatch(ctx context.Context, now time.Time) (int, error)
+	// ScanReminders เพิ่มการเตือนนัด/งานที่ใกล้ถึงเวลาลง outbox คืนจำนวนแถวที่เพิ่ม
+	ScanReminders(now time.Time) (int64, error)
+}
+
+type notificationService struct {
+	repo          repository.NotificationRepository
+	renderer      *notify.Renderer
+	channels      map[string]notify.Channel
+	defaultLocale string
+	reminders     ReminderWindows
+}
+
+// channels ช่องทางที่ตั้งค่าไว้ แถวของช่องทางที่ไม่มีจะถูกปิดเป็น skipped
+func NewNotificationService(repo repository.NotificationRepository, renderer *notify.Renderer, channels []notify.Channel, defaultLocale string, reminders ReminderWindows) NotificationService {
+	byName := map[string]notify.Channel{}
+	for _, ch := range channels {
+		byName[ch.Name()] = ch
+	}
+	return &notificationService{repo: repo, renderer: renderer, channels: byName, defaultLocale: defaultLocale, reminders: reminders}
+}
+
+func (s *notificationService) GetInbox(userID int, unreadOnly bool, limit int) ([]models.Notification, int, error) {
+	if limit <= 0 {
+		limit = defaultInboxLimit
+	}
+	if limit > maxInboxLimit {
+		limit = maxInboxLimit
+	}
+	notifications, err := s.repo.GetNotifications(userID, unreadOnly, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	unread, err := s.repo.CountUnreadNotifications(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return notifications, unread, nil
+}
+
+func (s *notificationService) MarkRead(userID, id int) error {
+	return s.repo.MarkNotificationRead(userID, id)
+}
+
+func (s *notificationService) MarkAllRead(userID int) (int64, error) {
+	return s.repo.MarkAllNotificationsRead(userID)
+}
+
+func (s *notificationService) GetPreferences(userID int) (*models.NotificationPreferences, error) {
+	p, err := s.repo.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		// ค่าเริ่มต้น (ตรงกับ outboxFanout ใน repository)
+		p = &models.NotificationPreferences{UserID: userID, Locale: s.defaultLocale, EmailEnabled: true}
+	}
+	return p, nil
+}
+
+func (s *notificationService) UpdatePreferences(userID int, req UpdatePreferencesRequest) (*models.NotificationPreferences, error) {
+	p, err := s.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Locale != nil {
+		if *req.Locale != notify.LocaleTH && *req.Locale != notify.LocaleEN {
+			return nil, fmt.Errorf("%w: locale must be one of: th, en", ErrInvalidPreferences)
+		}
+		p.Locale = *req.Locale
+	}
+	if req.EmailEnabled != nil {
+		p.EmailEnabled = *req.EmailEnabled
+	}
+	if req.SMSEnabled != nil {
+		p.SMSEnabled = *req.SMSEnabled
+	}
+	if err := s.repo.UpsertPreferences(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *notificationService) ScanReminders(now time.Time) (int64, error) {
+	schedules, err := s.repo.EnqueueScheduleReminders(now, now.Add(s.reminders.Schedule))
+	if err != nil {
+		return 0, err
+	}
+	assignments, err := s.repo.EnqueueAssignmentReminders(now, now.Add(s.reminders.Assignment))
+	if err != nil {
+		return schedules, err
+	}
+	return schedules + assignments, nil
+}
+
+func (s *notificationService) Dispatch(ctx context.Context, now time.Time) (int, error) {
+	deliveries, err := s.repo.ClaimOutbox(now, outboxLease, outboxBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, d := range deliveries {
+		err := s.deliver(ctx, d)
+		switch {
+		case err == nil:
+			sent++
+			err = s.repo.MarkOutboxSent(d.ID)
+		case errors.Is(err, errChannelNotConfigured):
+			err = s.repo.MarkOutboxDone(d.ID, "skipped", err.Error())
+		case errors.Is(err, notify.ErrUndeliverable), d.Attempts >= outboxMaxAttempts:
+			log.Printf("notification %d (%s) failed permanently: %v", d.ID, d.Channel, err)
+			err = s.repo.MarkOutboxDone(d.ID, "failed", err.Error())
+		default:
+			err = s.repo.MarkOutboxRetry(d.ID, now.Add(outboxBackoff(d.Attempts)), err.Error())
+		}
+		if err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+var errChannelNotConfigured = errors.New("notification channel is not configured")
+
+func (s *notificationService) deliver(ctx context.Context, d models.OutboxDelivery) error {
+	ch, ok := s.channels[d.Channel]
+	if !ok {
+		return fmt.Errorf("%w: %s", errChannelNotConfigured, d.Channel)
+	}
+
+	locale := d.Locale
+	if locale == "" {
+		locale = s.defaultLocale
+	}
+	data := d.Payload
+	if data == nil {
+		data = map[string]any{}
+	}
+	data["name"] = d.Name
+
+	msg, err := s.renderer.Render(d.Template, locale, data)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notificationTimeout)
+	defer cancel()
+	return ch.Send(ctx, notify.Recipient{UserID: d.UserID, Name: d.Name, Email: d.Email, Phone: d.Phone}, msg)
+}
+
+// outboxBackoff เวลารอก่อนลองครั้งถัดไป หลังพยายามไปแล้ว attempts ครั้ง
+func outboxBackoff(attempts int) time.Duration {
+	d := outboxBackoffBase
+	for i := 1; i < attempts && d < outboxBackoffMax; i++ {
+		d *= 2
+	}
+	return min(d, outboxBackoffMax)
+}
+
+// StartNotificationWorkers ส่ง outbox ทุก dispatchInterval และหาการเตือนใหม่ทุก scanInterval คืนฟังก์ชันสำหรับหยุด
+func StartNotificationWorkers(s NotificationService, dispatchInterval, scanInterval time.Duration) (stop func()) {
+	stopDispatch := runEvery(dispatchInterval, func() {
+		if _, err := s.Dispatch(context.Background(), time.Now()); err != nil {
+			log.Printf("notification dispatcher: %v", err)
+		}
+	})
+	stopScan := runEvery(scanInterval, func() {
+		if _, err := s.ScanReminders(time.Now()); err != nil {
+			log.Printf("reminder scanner: %v", err)
+		}
+	})
+	return func() {
+		stopDispatch()
+		stopScan()
+	}
+}
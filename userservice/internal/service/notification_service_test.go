@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"users/internal/models"
+	"users/internal/notify"
+	"users/internal/repository"
+)
+
+func TestOutboxBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{4, 4 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour}, // 64m ถูกจำกัดที่ 1h
+		{50, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := outboxBackoff(tt.attempts); got != tt.want {
+			t.Errorf("outboxBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+// fakeOutboxRepo เก็บผลการปิดแถวของ Dispatch (เมธอดอื่นของ NotificationRepository ไม่ถูกเรียกในการทดสอบนี้)
+type fakeOutboxRepo struct {
+	repository.NotificationRepository
+
+	deliveries []models.OutboxDelivery
+	claimErr   error
+	markErr    error
+
+	sent    []int
+	retries map[int]time.Time
+	done    map[int]string
+	errs    map[int]string
+}
+
+func newFakeOutboxRepo(deliveries ...models.OutboxDelivery) *fakeOutboxRepo {
+	return &fakeOutboxRepo{deliveries: deliveries, retries: map[int]time.Time{}, done: map[int]string{}, errs: map[int]string{}}
+}
+
+func (r *fakeOutboxRepo) ClaimOutbox(now time.Time, lease time.Duration, limit int) ([]models.OutboxDelivery, error) {
+	return r.deliveries, r.claimErr
+}
+
+func (r *fakeOutboxRepo) MarkOutboxSent(id int) error {
+	r.sent = append(r.sent, id)
+	return r.markErr
+}
+
+func (r *fakeOutboxRepo) MarkOutboxRetry(id int, nextAttempt time.Time, lastError string) error {
+	r.retries[id] = nextAttempt
+	r.errs[id] = lastError
+	return r.markErr
+}
+
+func (r *fakeOutboxRepo) MarkOutboxDone(id int, status, lastError string) error {
+	r.done[id] = status
+	r.errs[id] = lastError
+	return r.markErr
+}
+
+// fakeChannel คืน error ตามผู้รับ (UserID) และเก็บข้อความที่ส่ง
+type fakeChannel struct {
+	name string
+	errs map[int]error
+	sent []notify.Message
+}
+
+func (c *fakeChannel) Name() string { return c.name }
+
+func (c *fakeChannel) Send(_ context.Context, to notify.Recipient, msg notify.Message) error {
+	if err := c.errs[to.UserID]; err != nil {
+		return err
+	}
+	c.sent = append(c.sent, msg)
+	return nil
+}
+
+func newDispatchService(t *testing.T, repo repository.NotificationRepository, channels ...notify.Channel) NotificationService {
+	t.Helper()
+	renderer, err := notify.NewRenderer(notify.LocaleTH, time.UTC)
+	if err != nil {
+		t.Fatalf("NewRenderer: %v", err)
+	}
+	return NewNotificationService(repo, renderer, channels, notify.LocaleTH, ReminderWindows{})
+}
+
+func TestDispatchTransitions(t *testing.T) {
+	now := time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC)
+	transient := errors.New("connection reset")
+	email := &fakeChannel{name: notify.ChannelEmail, errs: map[int]error{
+		3: fmt.Errorf("%w: bounced", notify.ErrUndeliverable),
+		4: transient,
+		5: transient,
+	}}
+	payload := func() map[string]any { return map[string]any{"expires_hours": 24, "link": "https://example.com"} }
+
+	repo := newFakeOutboxRepo(
+		models.OutboxDelivery{ID: 1, UserID: 1, Channel: notify.ChannelEmail, Template: notify.TemplateVerifyEmail, Payload: payload(), Attempts: 1, Name: "Somchai", Locale: notify.LocaleEN},
+		models.OutboxDelivery{ID: 2, UserID: 2, Channel: notify.ChannelSMS, Template: notify.TemplateVerifyEmail, Payload: payload(), Attempts: 1},
+		models.OutboxDelivery{ID: 3, UserID: 3, Channel: notify.ChannelEmail, Template: notify.TemplateVerifyEmail, Payload: payload(), Attempts: 1},
+		models.OutboxDelivery{ID: 4, UserID: 4, Channel: notify.ChannelEmail, Template: notify.TemplateVerifyEmail, Payload: payload(), Attempts: 3},
+		models.OutboxDelivery{ID: 5, UserID: 5, Channel: notify.ChannelEmail, Template: notify.TemplateVerifyEmail, Payload: payload(), Attempts: outboxMaxAttempts},
+		models.OutboxDelivery{ID: 6, UserID: 6, Channel: notify.ChannelEmail, Template: "no_such_template", Attempts: 1},
+		models.OutboxDelivery{ID: 7, UserID: 7, Channel: notify.ChannelEmail, Template: notify.TemplateVerifyEmail, Payload: payload(), Attempts: 1, Name: "สมหญิง"},
+	)
+	s := newDispatchService(t, repo, email)
+
+	sent, err := s.Dispatch(context.Background(), now)
+	if err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if sent != 2 {
+		t.Errorf("sent = %d, want 2", sent)
+	}
+	if fmt.Sprint(repo.sent) != "[1 7]" {
+		t.Errorf("MarkOutboxSent ids = %v, want [1 7]", repo.sent)
+	}
+
+	wantDone := map[int]string{
+		2: "skipped", // ไม่ได้ตั้งค่าช่องทาง SMS
+		3: "failed",  // ErrUndeliverable ไม่ retry
+		5: "failed",  // ครบจำนวนครั้งสูงสุดแล้ว
+		6: "failed",  // Template ที่ไม่รู้จัก
+	}
+	if len(repo.done) != len(wantDone) {
+		t.Errorf("MarkOutboxDone = %v, want %v", repo.done, wantDone)
+	}
+	for id, status := range wantDone {
+		if repo.done[id] != status {
+			t.Errorf("delivery %d status = %q, want %q", id, repo.done[id], status)
+		}
+	}
+
+	if len(repo.retries) != 1 {
+		t.Fatalf("MarkOutboxRetry = %v, want only delivery 4", repo.retries)
+	}
+	if want := now.Add(2 * time.Minute); !repo.retries[4].Equal(want) {
+		t.Errorf("delivery 4 next attempt = %v, want %v", repo.retries[4], want)
+	}
+	if repo.errs[4] != transient.Error() {
+		t.Errorf("delivery 4 last error = %q", repo.errs[4])
+	}
+
+	// Locale ของผู้รับ (en) และค่าเริ่มต้น (th) พร้อมชื่อผู้รับจาก delivery
+	if len(email.sent) != 2 {
+		t.Fatalf("email sent %d messages, want 2", len(email.sent))
+	}
+	if email.sent[0].Subject != "Verify your email address" {
+		t.Errorf("en subject = %q", email.sent[0].Subject)
+	}
+	if email.sent[1].Subject != "ยืนยันอีเมลของคุณ" {
+		t.Errorf("th subject = %q", email.sent[1].Subject)
+	}
+}
+
+func TestDispatchRepositoryErrors(t *testing.T) {
+	t.Run("claim", func(t *testing.T) {
+		repo := newFakeOutboxRepo()
+		repo.claimErr = errors.New("db down")
+		if _, err := newDispatchService(t, repo).Dispatch(context.Background(), time.Now()); err == nil {
+			t.Fatal("expected claim error")
+		}
+	})
+
+	t.Run("mark stops the batch", func(t *testing.T) {
+		repo := newFakeOutboxRepo(
+			models.OutboxDelivery{ID: 1, UserID: 1, Channel: notify.ChannelSMS, Template: notify.TemplateVerifyEmail},
+			models.OutboxDelivery{ID: 2, UserID: 2, Channel: notify.ChannelSMS, Template: notify.TemplateVerifyEmail},
+		)
+		repo.markErr = errors.New("db down")
+		if _, err := newDispatchService(t, repo).Dispatch(context.Background(), time.Now()); err == nil {
+			t.Fatal("expected mark error")
+		}
+		if len(repo.done) != 1 {
+			t.Errorf("marked %d deliveries, want 1", len(repo.done))
+		}
+	})
+}
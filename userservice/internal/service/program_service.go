@@ -0,0 +1,437 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"users/internal/models"
+	"users/internal/repository"
+)
+
+var (
+	ErrProgramNotTemplate     = errors.New("program is not a template")
+	ErrInvalidAssignment      = errors.New("invalid program assignment")
+	ErrInvalidProgramWeek     = errors.New("invalid program week")
+	ErrInvalidProgramExercise = errors.New("invalid program exercise")
+	ErrInvalidProgramBlock    = errors.New("invalid program block")
+)
+
+type ProgramService interface {
+	// copy Template ให้ลูกค้าแต่ละคน คืนโปรแกรมใหม่ตามลำดับ clientIDs
+	AssignProgram(templateID int, clientIDs []int) ([]models.Program, error)
+
+	// โปรแกรมพร้อมโครงสร้าง Phase -> Week -> Day -> Exercises (รวม Override ของสัปดาห์แล้ว)
+	GetProgramDetail(id int) (*models.Program, error)
+	AddExercise(pe *models.ProgramExercise) error
+	UpdateExercise(pe *models.ProgramExercise) error
+	CreateBlock(programID int, b *models.ProgramBlock) error
+	UpdateBlock(programID int, b *models.ProgramBlock) error
+	CreateWeek(w *models.ProgramWeek) error
+	UpdateWeek(w *models.ProgramWeek) error
+	// copy แล้วคืนสัปดาห์/วันใหม่พร้อมเนื้อหา
+	DuplicateWeek(programID, weekID int) (*models.ProgramWeek, error)
+	DuplicateDay(programID, dayID, weekID int) (*models.ProgramDay, error)
+}
+
+type programService struct {
+	repo       repository.ProgramRepository
+	recordRepo repository.PersonalRecordRepository
+}
+
+func NewProgramService(repo repository.ProgramRepository, recordRepo repository.PersonalRecordRepository) ProgramService {
+	return &programService{repo: repo, recordRepo: recordRepo}
+}
+
+func (s *programService) AssignProgram(templateID int, clientIDs []int) ([]models.Program, error) {
+	if len(clientIDs) == 0 {
+		return nil, fmt.Errorf("%w: client_ids is required", ErrInvalidAssignment)
+	}
+	// ตัด id ซ้ำ (ลูกค้าคนเดียวได้ 1 copy ต่อครั้ง)
+	seen := map[int]bool{}
+	unique := make([]int, 0, len(clientIDs))
+	for _, id := range clientIDs {
+		if id <= 0 {
+			return nil, fmt.Errorf("%w: invalid client id %d", ErrInvalidAssignment, id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	template, err := s.repo.GetProgramByID(templateID)
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrProgramNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !template.IsTemplate {
+		return nil, ErrProgramNotTemplate
+	}
+
+	// ลูกค้าต้องเป็นของเทรนเนอร์เจ้าของ Template (ไม่ใช่ผู้เรียก: admin assign แทนเทรนเนอร์ได้)
+	return s.repo.AssignProgram(templateID, template.TrainerID, unique)
+}
+
+func (s *programService) GetProgramDetail(id int) (*models.Program, error) {
+	program, err := s.repo.GetProgramByID(id)
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrProgramNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	phases, err := s.repo.GetPhases(id)
+	if err != nil {
+		return nil, err
+	}
+	weeks, err := s.repo.GetWeeks(id)
+	if err != nil {
+		return nil, err
+	}
+	exercises, err := s.repo.GetExercisesByProgramID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	nestProgram(program, phases, weeks, exercises)
+	if program.ClientID != nil {
+		if err := s.resolveLoads(program, *program.ClientID); err != nil {
+			return nil, err
+		}
+	}
+	return program, nil
+}
+
+// resolveLoads แปลง %1RM (ของท่า หรือ intensity ของสัปดาห์) เป็นน้ำหนักจริง
+// จาก 1RM ประมาณการ (Epley) ที่ดีที่สุดในประวัติของลูกค้า (เหมือนที่ใช้ตรวจ PR)
+func (s *programService) resolveLoads(program *models.Program, clientID int) error {
+	var ids []int
+	seen := map[int]bool{}
+	eachProgramExercise(program, func(pe *models.ProgramExercise) {
+		if targetPercent(pe) != nil && !seen[pe.ExerciseID] {
+			seen[pe.ExerciseID] = true
+			ids = append(ids, pe.ExerciseID)
+		}
+	})
+	if len(ids) == 0 {
+		return nil
+	}
+
+	history, err := s.recordRepo.GetRepMaxes(clientID, ids, nil)
+	if err != nil {
+		return err
+	}
+	oneRM := map[int]float64{}
+	for _, m := range history {
+		oneRM[m.ExerciseID] = math.Max(oneRM[m.ExerciseID], EstimateOneRM(FormulaEpley, m.WeightKg, m.Reps))
+	}
+
+	eachProgramExercise(program, func(pe *models.ProgramExercise) {
+		pct := targetPercent(pe)
+		e1rm, ok := oneRM[pe.ExerciseID]
+		if pct == nil || !ok || e1rm <= 0 {
+			return
+		}
+		est := round2(e1rm)
+		// ปัดเป็นทีละ 0.5 kg (เพิ่มน้ำหนักได้จริง)
+		load := math.Round(e1rm**pct/100*2) / 2
+		pe.Estimated1RM = &est
+		pe.ResolvedLoadKg = &load
+	})
+	return nil
+}
+
+// targetPercent %1RM ของท่า ถ้าไม่ได้กำหนดใช้ intensity ของสัปดาห์
+func targetPercent(pe *models.ProgramExercise) *float64 {
+	if pe.Percent1RM != nil {
+		return pe.Percent1RM
+	}
+	return pe.IntensityPct
+}
+
+// eachProgramExercise เรียก fn กับทุกท่าในโปรแกรมที่ถูก nest แล้ว (ท่าเดี่ยว, ในวันฝึก และใน Block)
+func eachProgramExercise(program *models.Program, fn func(pe *models.ProgramExercise)) {
+	for i := range program.Exercises {
+		fn(&program.Exercises[i])
+	}
+	visitWeeks := func(weeks []models.ProgramWeek) {
+		for wi := range weeks {
+			for di := range weeks[wi].Days {
+				d := &weeks[wi].Days[di]
+				for i := range d.Exercises {
+					fn(&d.Exercises[i])
+				}
+				for bi := range d.Blocks {
+					for i := range d.Blocks[bi].Exercises {
+						fn(&d.Blocks[bi].Exercises[i])
+					}
+				}
+			}
+		}
+	}
+	visitWeeks(program.Weeks)
+	for i := range program.Phases {
+		visitWeeks(program.Phases[i].Weeks)
+	}
+}
+
+func (s *programService) AddExercise(pe *models.ProgramExercise) error {
+	if err := s.validateExercise(pe); err != nil {
+		return err
+	}
+	return s.repo.AddExercise(pe)
+}
+
+func (s *programService) UpdateExercise(pe *models.ProgramExercise) error {
+	if err := s.validateExercise(pe); err != nil {
+		return err
+	}
+	return s.repo.UpdateProgramExercise(pe)
+}
+
+// validateExercise ค่าต้องไม่ติดลบ และวันฝึก/Block (ถ้าระบุ) ต้องอยู่ในโปรแกรมเดียวกัน
+// ระบุแค่ block_id ได้ (day_id = วันฝึกของ Block)
+func (s *programService) validateExercise(pe *models.ProgramExercise) error {
+	switch {
+	case pe.Sets < 0:
+		return fmt.Errorf("%w: sets must not be negative", ErrInvalidProgramExercise)
+	case pe.Reps < 0:
+		return fmt.Errorf("%w: reps must not be negative", ErrInvalidProgramExercise)
+	case pe.DurationSeconds < 0:
+		return fmt.Errorf("%w: duration_seconds must not be negative", ErrInvalidProgramExercise)
+	case pe.RestSeconds < 0:
+		return fmt.Errorf("%w: rest_seconds must not be negative", ErrInvalidProgramExercise)
+	}
+	if err := validatePrescription(pe); err != nil {
+		return err
+	}
+	if pe.BlockID != nil {
+		block, err := s.repo.GetBlock(pe.ProgramID, *pe.BlockID)
+		if err != nil {
+			return err
+		}
+		if pe.DayID == nil {
+			pe.DayID = &block.DayID
+		} else if *pe.DayID != block.DayID {
+			return fmt.Errorf("%w: block_id must belong to day_id", ErrInvalidProgramExercise)
+		}
+	}
+	if pe.DayID != nil {
+		if _, err := s.repo.GetDay(pe.ProgramID, *pe.DayID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tempo 4 ช่วง (ลง-ค้างล่าง-ขึ้น-ค้างบน) แต่ละช่วงเป็นวินาทีหรือ X (ระเบิดแรง) เช่น "3-1-X-0" หรือ "31X0"
+var tempoPattern = regexp.MustCompile(`^[0-9X](-?[0-9X]){3}$`)
+
+// validatePrescription ตรวจช่วงจำนวนครั้ง, RPE/RIR, %1RM, tempo และน้ำหนักเป้าหมาย
+func validatePrescription(pe *models.ProgramExercise) error {
+	if pe.Tempo != nil {
+		t := strings.ToUpper(strings.TrimSpace(*pe.Tempo))
+		if t == "" {
+			pe.Tempo = nil
+		} else {
+			pe.Tempo = &t
+		}
+	}
+
+	switch {
+	case pe.RepsMin != nil && *pe.RepsMin <= 0, pe.RepsMax != nil && *pe.RepsMax <= 0:
+		return fmt.Errorf("%w: reps_min and reps_max must be positive", ErrInvalidProgramExercise)
+	case pe.RepsMin != nil && pe.RepsMax != nil && *pe.RepsMin > *pe.RepsMax:
+		return fmt.Errorf("%w: reps_min must not exceed reps_max", ErrInvalidProgramExercise)
+	case pe.TargetRPE != nil && pe.TargetRIR != nil:
+		return fmt.Errorf("%w: set either target_rpe or target_rir, not both", ErrInvalidProgramExercise)
+	case pe.TargetRPE != nil && (*pe.TargetRPE <= 0 || *pe.TargetRPE > 10):
+		return fmt.Errorf("%w: target_rpe must be between 0 and 10", ErrInvalidProgramExercise)
+	case pe.TargetRIR != nil && (*pe.TargetRIR < 0 || *pe.TargetRIR > 10):
+		return fmt.Errorf("%w: target_rir must be between 0 and 10", ErrInvalidProgramExercise)
+	case pe.Percent1RM != nil && (*pe.Percent1RM <= 0 || *pe.Percent1RM > 150):
+		return fmt.Errorf("%w: percent_1rm must be between 0 and 150", ErrInvalidProgramExercise)
+	case pe.LoadKg != nil && *pe.LoadKg < 0:
+		return fmt.Errorf("%w: load_kg must not be negative", ErrInvalidProgramExercise)
+	case pe.Tempo != nil && !tempoPattern.MatchString(*pe.Tempo):
+		return fmt.Errorf("%w: tempo must have 4 phases of seconds or X, e.g. 3-1-X-0", ErrInvalidProgramExercise)
+	}
+	return nil
+}
+
+func (s *programService) CreateBlock(programID int, b *models.ProgramBlock) error {
+	if err := validateBlock(b); err != nil {
+		return err
+	}
+	return s.repo.CreateBlock(programID, b)
+}
+
+func (s *programService) UpdateBlock(programID int, b *models.ProgramBlock) error {
+	if err := validateBlock(b); err != nil {
+		return err
+	}
+	return s.repo.UpdateBlock(programID, b)
+}
+
+// validateBlock เติมค่า default (straight, 1 รอบ) และตรวจค่า; AMRAP ต้องมีเวลา (duration_seconds)
+func validateBlock(b *models.ProgramBlock) error {
+	if b.BlockType == "" {
+		b.BlockType = models.BlockStraight
+	}
+	if b.Rounds == 0 {
+		b.Rounds = 1
+	}
+	switch {
+	case !models.IsValidBlockType(b.BlockType):
+		return fmt.Errorf("%w: block_type must be one of: %s", ErrInvalidProgramBlock, strings.Join(models.BlockTypes, ", "))
+	case b.Rounds < 0:
+		return fmt.Errorf("%w: rounds must be positive", ErrInvalidProgramBlock)
+	case b.RestSeconds < 0:
+		return fmt.Errorf("%w: rest_seconds must not be negative", ErrInvalidProgramBlock)
+	case b.DurationSeconds != nil && *b.DurationSeconds <= 0:
+		return fmt.Errorf("%w: duration_seconds must be positive", ErrInvalidProgramBlock)
+	case b.BlockType == models.BlockAMRAP && b.DurationSeconds == nil:
+		return fmt.Errorf("%w: amrap requires duration_seconds", ErrInvalidProgramBlock)
+	}
+	return nil
+}
+
+func (s *programService) CreateWeek(w *models.ProgramWeek) error {
+	if err := validateWeek(w); err != nil {
+		return err
+	}
+	return s.repo.CreateWeek(w)
+}
+
+func (s *programService) UpdateWeek(w *models.ProgramWeek) error {
+	if err := validateWeek(w); err != nil {
+		return err
+	}
+	return s.repo.UpdateWeek(w)
+}
+
+func (s *programService) DuplicateWeek(programID, weekID int) (*models.ProgramWeek, error) {
+	newID, err := s.repo.DuplicateWeek(programID, weekID)
+	if err != nil {
+		return nil, err
+	}
+	program, err := s.GetProgramDetail(programID)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range allWeeks(program) {
+		if w.ID == newID {
+			return &w, nil
+		}
+	}
+	return nil, repository.ErrProgramWeekNotFound
+}
+
+func (s *programService) DuplicateDay(programID, dayID, weekID int) (*models.ProgramDay, error) {
+	newID, err := s.repo.DuplicateDay(programID, dayID, weekID)
+	if err != nil {
+		return nil, err
+	}
+	program, err := s.GetProgramDetail(programID)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range allWeeks(program) {
+		for _, d := range w.Days {
+			if d.ID == newID {
+				return &d, nil
+			}
+		}
+	}
+	return nil, repository.ErrProgramDayNotFound
+}
+
+func validateWeek(w *models.ProgramWeek) error {
+	switch {
+	case w.SetsOverride != nil && *w.SetsOverride <= 0:
+		return fmt.Errorf("%w: sets_override must be positive", ErrInvalidProgramWeek)
+	case w.RepsOverride != nil && *w.RepsOverride <= 0:
+		return fmt.Errorf("%w: reps_override must be positive", ErrInvalidProgramWeek)
+	case w.IntensityPct != nil && (*w.IntensityPct <= 0 || *w.IntensityPct > 150):
+		return fmt.Errorf("%w: intensity_pct must be between 0 and 150", ErrInvalidProgramWeek)
+	}
+	return nil
+}
+
+// nestProgram จัดท่าฝึกเข้า Block/วันฝึก วันฝึกเข้าสัปดาห์ สัปดาห์เข้า Phase และคำนวณค่าที่ใช้จริงตาม Override ของสัปดาห์
+// ท่าที่ไม่อยู่ในวันฝึกใดอยู่ใน program.Exercises, สัปดาห์ที่ไม่อยู่ใน Phase ใดอยู่ใน program.Weeks
+func nestProgram(program *models.Program, phases []models.ProgramPhase, weeks []models.ProgramWeek, exercises []models.ProgramExercise) {
+	dayExercises := map[int][]models.ProgramExercise{}
+	program.Exercises = []models.ProgramExercise{}
+	for _, pe := range exercises {
+		if pe.DayID == nil {
+			program.Exercises = append(program.Exercises, pe)
+			continue
+		}
+		dayExercises[*pe.DayID] = append(dayExercises[*pe.DayID], pe)
+	}
+
+	phaseIndex := map[int]int{}
+	for i, ph := range phases {
+		phaseIndex[ph.ID] = i
+	}
+	program.Phases = phases
+	program.Weeks = []models.ProgramWeek{}
+
+	for _, w := range weeks {
+		for i := range w.Days {
+			d := &w.Days[i]
+			blockIndex := map[int]int{}
+			for j, b := range d.Blocks {
+				blockIndex[b.ID] = j
+			}
+			for _, pe := range dayExercises[d.ID] {
+				applyWeekOverrides(&pe, &w)
+				if pe.BlockID != nil {
+					if j, ok := blockIndex[*pe.BlockID]; ok {
+						d.Blocks[j].Exercises = append(d.Blocks[j].Exercises, pe)
+						continue
+					}
+				}
+				d.Exercises = append(d.Exercises, pe)
+			}
+		}
+
+		if w.PhaseID != nil {
+			if i, ok := phaseIndex[*w.PhaseID]; ok {
+				program.Phases[i].Weeks = append(program.Phases[i].Weeks, w)
+				continue
+			}
+		}
+		program.Weeks = append(program.Weeks, w)
+	}
+}
+
+// applyWeekOverrides Override ของสัปดาห์มีค่า = ใช้แทน sets/reps ของท่า
+func applyWeekOverrides(pe *models.ProgramExercise, w *models.ProgramWeek) {
+	sets, reps := pe.Sets, pe.Reps
+	if w.SetsOverride != nil {
+		sets = *w.SetsOverride
+	}
+	if w.RepsOverride != nil {
+		reps = *w.RepsOverride
+	}
+	pe.EffectiveSets = &sets
+	pe.EffectiveReps = &reps
+	pe.IntensityPct = w.IntensityPct
+}
+
+// allWeeks สัปดาห์ทั้งหมดของโปรแกรมที่ถูก nest แล้ว (ทั้งในและนอก Phase)
+func allWeeks(program *models.Program) []models.ProgramWeek {
+	weeks := append([]models.ProgramWeek{}, program.Weeks...)
+	for _, ph := range program.Phases {
+		weeks = append(weeks, ph.Weeks...)
+	}
+	return weeks
+}
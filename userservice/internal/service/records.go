@@ -0,0 +1,214 @@
+package service
+
+import (
+	"math"
+	"time"
+
+	"users/internal/models"
+	"users/internal/repository"
+)
+
+// RecordRebuilder คำนวณ PR ใหม่จากประวัติการฝึก ใช้เมื่อนัดถูกยกเลิก/ย้ายเวลา/ลบ
+// (Session ที่นับเป็น PR และลำดับเวลาเปลี่ยน PR และ previous_value ที่บันทึกไว้จึงไม่ตรงแล้ว)
+type RecordRebuilder interface {
+	// RebuildClient คำนวณ PR ของลูกค้าใหม่ทุกท่าที่มีบันทึกหรือมี PR อยู่
+	RebuildClient(clientID int) error
+}
+
+type recordRebuilder struct {
+	sessions repository.SessionRepository
+	records  repository.PersonalRecordRepository
+}
+
+func NewRecordRebuilder(sessions repository.SessionRepository, records repository.PersonalRecordRepository) RecordRebuilder {
+	return &recordRebuilder{sessions: sessions, records: records}
+}
+
+func (r *recordRebuilder) RebuildClient(clientID int) error {
+	exerciseIDs, err := r.records.GetClientExerciseIDs(clientID)
+	if err != nil {
+		return err
+	}
+	for _, id := range exerciseIDs {
+		if _, err := r.rebuild(clientID, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebuild คำนวณ PR ทั้งหมดของลูกค้าในท่าหนึ่งใหม่จากประวัติ แล้วบันทึกแทนของเดิม
+func (r *recordRebuilder) rebuild(clientID, exerciseID int) ([]models.PersonalRecord, error) {
+	sets, err := r.sessions.GetClientExerciseSets(clientID, exerciseID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	records := replayPersonalRecords(clientID, exerciseID, sets)
+	if err := r.records.ReplaceRecords(clientID, exerciseID, records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// exerciseBests สถิติดีที่สุดของท่าหนึ่ง (จากประวัติ หรือจาก Session ที่กำลังบันทึก)
+type exerciseBests struct {
+	maxWeight float64
+	e1rm      float64
+	repsAt    map[int64]int // น้ำหนัก (หน่วย 0.01 kg) -> จำนวนครั้งสูงสุด
+}
+
+// candidateSet เซตใน WorkoutLog ที่อาจเป็น PR
+type candidateSet struct {
+	logIndex, setIndex int
+	weightKg           float64
+	reps               int
+}
+
+func weightKey(weightKg float64) int64 {
+	return int64(math.Round(weightKg * 100))
+}
+
+// detectPersonalRecords เปรียบเทียบเซตใน w กับประวัติ (history) แล้วคืน PR ใหม่
+//   - max_weight / e1rm: ครั้งแรกที่บันทึกท่านี้ถือเป็น PR แรก (previous_value = null)
+//   - reps_at_weight: ต้องเคยทำน้ำหนักนี้มาก่อน (ไม่งั้นทุกน้ำหนักใหม่จะกลายเป็น PR)
+//
+// แต่ละท่าได้ PR ไม่เกิน 1 รายการต่อประเภท (ต่อน้ำหนักสำหรับ reps_at_weight) ต่อ Session
+func detectPersonalRecords(clientID int, achievedAt time.Time, w *models.WorkoutLog, history []models.RepMax) []models.PersonalRecord {
+	prev := map[int]*exerciseBests{}
+	for _, m := range history {
+		b, ok := prev[m.ExerciseID]
+		if !ok {
+			b = &exerciseBests{repsAt: map[int64]int{}}
+			prev[m.ExerciseID] = b
+		}
+		b.maxWeight = math.Max(b.maxWeight, m.WeightKg)
+		b.e1rm = math.Max(b.e1rm, EstimateOneRM(FormulaEpley, m.WeightKg, m.Reps))
+		if key := weightKey(m.WeightKg); m.Reps > b.repsAt[key] {
+			b.repsAt[key] = m.Reps
+		}
+	}
+
+	type sessionBest struct {
+		maxWeight, e1rm *candidateSet
+		repsAt          map[int64]*candidateSet
+		weightOrder     []int64
+	}
+	var order []int
+	best := map[int]*sessionBest{}
+	for i, l := range w.Exercises {
+		if l.ExerciseID == nil {
+			continue
+		}
+		for j, set := range l.Sets {
+			if set.WeightKg <= 0 || set.Reps <= 0 {
+				continue
+			}
+			b, ok := best[*l.ExerciseID]
+			if !ok {
+				b = &sessionBest{repsAt: map[int64]*candidateSet{}}
+				best[*l.ExerciseID] = b
+				order = append(order, *l.ExerciseID)
+			}
+			cand := &candidateSet{logIndex: i, setIndex: j, weightKg: set.WeightKg, reps: set.Reps}
+			if b.maxWeight == nil || cand.weightKg > b.maxWeight.weightKg {
+				b.maxWeight = cand
+			}
+			if b.e1rm == nil || EstimateOneRM(FormulaEpley, cand.weightKg, cand.reps) > EstimateOneRM(FormulaEpley, b.e1rm.weightKg, b.e1rm.reps) {
+				b.e1rm = cand
+			}
+			key := weightKey(cand.weightKg)
+			if cur, ok := b.repsAt[key]; !ok || cand.reps > cur.reps {
+				if !ok {
+					b.weightOrder = append(b.weightOrder, key)
+				}
+				b.repsAt[key] = cand
+			}
+		}
+	}
+
+	records := []models.PersonalRecord{}
+	newRecord := func(exerciseID int, recordType string, c *candidateSet, value float64, previous *float64) {
+		records = append(records, models.PersonalRecord{
+			ClientID:      clientID,
+			ExerciseID:    exerciseID,
+			RecordType:    recordType,
+			Value:         round2(value),
+			PreviousValue: previous,
+			WeightKg:      c.weightKg,
+			Reps:          c.reps,
+			AchievedAt:    achievedAt,
+			LogIndex:      c.logIndex,
+			SetIndex:      c.setIndex,
+		})
+	}
+
+	for _, exerciseID := range order {
+		b := best[exerciseID]
+		p, hasHistory := prev[exerciseID]
+
+		if !hasHistory {
+			newRecord(exerciseID, models.RecordMaxWeight, b.maxWeight, b.maxWeight.weightKg, nil)
+			newRecord(exerciseID, models.RecordE1RM, b.e1rm, EstimateOneRM(FormulaEpley, b.e1rm.weightKg, b.e1rm.reps), nil)
+			continue
+		}
+
+		if b.maxWeight.weightKg > p.maxWeight {
+			previous := round2(p.maxWeight)
+			newRecord(exerciseID, models.RecordMaxWeight, b.maxWeight, b.maxWeight.weightKg, &previous)
+		}
+		if e1rm := EstimateOneRM(FormulaEpley, b.e1rm.weightKg, b.e1rm.reps); round2(e1rm) > round2(p.e1rm) {
+			previous := round2(p.e1rm)
+			newRecord(exerciseID, models.RecordE1RM, b.e1rm, e1rm, &previous)
+		}
+		for _, key := range b.weightOrder {
+			c := b.repsAt[key]
+			if prevReps, ok := p.repsAt[key]; ok && c.reps > prevReps {
+				previous := float64(prevReps)
+				newRecord(exerciseID, models.RecordRepsAtWeight, c, float64(c.reps), &previous)
+			}
+		}
+	}
+	return records
+}
+
+// replayPersonalRecords คำนวณ PR ของท่าหนึ่งใหม่ทั้งหมดจากเซตที่เคยทำ (เรียงตามเวลาแล้ว ดู GetClientExerciseSets)
+// ทีละ Session ตามลำดับเวลา: แต่ละ Session เทียบกับ Session ก่อนหน้าเท่านั้น เหมือนตอนบันทึกครั้งแรก
+func replayPersonalRecords(clientID, exerciseID int, sets []models.PerformedSet) []models.PersonalRecord {
+	type session struct {
+		scheduleID  int
+		performedAt time.Time
+		sets        []models.PerformedSet
+	}
+	var sessions []*session
+	byID := map[int]*session{}
+	for _, set := range sets {
+		ss, ok := byID[set.ScheduleID]
+		if !ok {
+			ss = &session{scheduleID: set.ScheduleID, performedAt: set.PerformedAt}
+			byID[set.ScheduleID] = ss
+			sessions = append(sessions, ss)
+		}
+		ss.sets = append(ss.sets, set)
+	}
+
+	records := []models.PersonalRecord{}
+	var history []models.RepMax
+	for _, ss := range sessions {
+		log := models.SessionLog{ExerciseID: &exerciseID}
+		for _, set := range ss.sets {
+			log.Sets = append(log.Sets, models.SessionLogSet{ID: set.SetID, WeightKg: set.WeightKg, Reps: set.Reps})
+		}
+		w := &models.WorkoutLog{Exercises: []models.SessionLog{log}}
+		for _, pr := range detectPersonalRecords(clientID, ss.performedAt, w, history) {
+			pr.SessionLogSetID = log.Sets[pr.SetIndex].ID
+			pr.ScheduleID = ss.scheduleID
+			records = append(records, pr)
+		}
+		for _, set := range ss.sets {
+			if set.WeightKg > 0 && set.Reps > 0 {
+				history = append(history, models.RepMax{ExerciseID: exerciseID, WeightKg: set.WeightKg, Reps: set.Reps})
+			}
+		}
+	}
+	return records
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"users/internal/models"
+)
+
+// formatRecords แสดง PR แบบสั้นเพื่อเทียบในตาราง: ประเภท=ค่า(ค่าเดิม)@น้ำหนักxครั้ง
+func formatRecords(records []models.PersonalRecord) []string {
+	out := []string{}
+	for _, pr := range records {
+		prev := "-"
+		if pr.PreviousValue != nil {
+			prev = fmt.Sprint(*pr.PreviousValue)
+		}
+		s := fmt.Sprintf("%s=%v(%s)@%vx%d", pr.RecordType, pr.Value, prev, pr.WeightKg, pr.Reps)
+		if pr.ScheduleID != 0 {
+			s = fmt.Sprintf("#%d %s", pr.ScheduleID, s)
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func workout(exerciseID int, sets ...[2]float64) *models.WorkoutLog {
+	l := models.SessionLog{ExerciseID: &exerciseID}
+	for _, s := range sets {
+		l.Sets = append(l.Sets, models.SessionLogSet{WeightKg: s[0], Reps: int(s[1])})
+	}
+	return &models.WorkoutLog{Exercises: []models.SessionLog{l}}
+}
+
+func TestDetectPersonalRecords(t *testing.T) {
+	const squat = 1
+	tests := []struct {
+		name    string
+		history []models.RepMax
+		w       *models.WorkoutLog
+		want    []string
+	}{
+		{
+			// ครั้งแรก: max_weight/e1rm ไม่มีค่าเดิม และไม่มี reps_at_weight
+			name: "first session",
+			w:    workout(squat, [2]float64{60, 10}, [2]float64{80, 5}),
+			want: []string{"max_weight=80(-)@80x5", "e1rm=93.33(-)@80x5"},
+		},
+		{
+			name:    "tie is not a record",
+			history: []models.RepMax{{ExerciseID: squat, WeightKg: 100, Reps: 5}},
+			w:       workout(squat, [2]float64{100, 5}),
+			want:    []string{},
+		},
+		{
+			// น้ำหนักน้อยลงแต่ครั้งมากขึ้น: e1rm เท่านั้น (ยังไม่เคยทำ 90 kg จึงไม่มี reps_at_weight)
+			name:    "e1rm without max weight",
+			history: []models.RepMax{{ExerciseID: squat, WeightKg: 100, Reps: 1}},
+			w:       workout(squat, [2]float64{90, 8}),
+			want:    []string{"e1rm=114(100)@90x8"},
+		},
+		{
+			name:    "max weight without e1rm",
+			history: []models.RepMax{{ExerciseID: squat, WeightKg: 100, Reps: 10}},
+			w:       workout(squat, [2]float64{105, 1}),
+			want:    []string{"max_weight=105(100)@105x1"},
+		},
+		{
+			// e1rm เทียบหลังปัดทศนิยม 2 ตำแหน่ง: 75x12 = 105 เท่ากับ 90x5 = 105
+			name:    "rounded e1rm tie",
+			history: []models.RepMax{{ExerciseID: squat, WeightKg: 90, Reps: 5}},
+			w:       workout(squat, [2]float64{75, 12}),
+			want:    []string{},
+		},
+		{
+			name: "reps at a weight done before",
+			history: []models.RepMax{
+				{ExerciseID: squat, WeightKg: 80, Reps: 8},
+				{ExerciseID: squat, WeightKg: 100, Reps: 3},
+			},
+			w: workout(squat, [2]float64{80, 10}, [2]float64{70, 15}),
+			// 80x10 = 106.67 ไม่เกิน e1rm เดิม 100x3 = 110
+			want: []string{"reps_at_weight=10(8)@80x10"},
+		},
+		{
+			// เซตที่เท่ากันใน Session เดียว: ใช้เซตแรก
+			name:    "ties within a session keep the first set",
+			history: []models.RepMax{{ExerciseID: squat, WeightKg: 60, Reps: 5}},
+			w:       workout(squat, [2]float64{70, 5}, [2]float64{70, 5}, [2]float64{60, 6}, [2]float64{60, 6}),
+			want:    []string{"max_weight=70(60)@70x5", "e1rm=81.67(70)@70x5", "reps_at_weight=6(5)@60x6"},
+		},
+		{
+			name: "warm-up and empty sets are ignored",
+			w:    workout(squat, [2]float64{0, 10}, [2]float64{40, 0}),
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := detectPersonalRecords(7, time.Now(), tt.w, tt.history)
+			if f := formatRecords(got); !reflect.DeepEqual(f, tt.want) {
+				t.Errorf("records = %v, want %v", f, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectPersonalRecordsSetPosition(t *testing.T) {
+	bench, row := 2, 3
+	w := &models.WorkoutLog{Exercises: []models.SessionLog{
+		{ExerciseID: nil, Sets: []models.SessionLogSet{{WeightKg: 20, Reps: 10}}},
+		{ExerciseID: &bench, Sets: []models.SessionLogSet{{WeightKg: 40, Reps: 15}, {WeightKg: 50, Reps: 5}}},
+		{ExerciseID: &row, Sets: []models.SessionLogSet{{WeightKg: 30, Reps: 12}}},
+	}}
+	got := detectPersonalRecords(7, time.Now(), w, nil)
+	if len(got) != 4 {
+		t.Fatalf("got %d records %v, want 4", len(got), formatRecords(got))
+	}
+	// bench max_weight = 50x5 (เซตที่ 2), e1rm = 40x15 = 60 (เซตที่ 1) มากกว่า 50x5 = 58.33
+	want := []struct{ exercise, log, set int }{{bench, 1, 1}, {bench, 1, 0}, {row, 2, 0}, {row, 2, 0}}
+	for i, w := range want {
+		if got[i].ExerciseID != w.exercise || got[i].LogIndex != w.log || got[i].SetIndex != w.set {
+			t.Errorf("record %d = exercise %d log %d set %d, want %+v", i, got[i].ExerciseID, got[i].LogIndex, got[i].SetIndex, w)
+		}
+	}
+}
+
+func TestReplayPersonalRecords(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 8, 0, 0, 0, time.UTC) }
+	set := func(setID, scheduleID, d int, weight float64, reps int) models.PerformedSet {
+		return models.PerformedSet{SetID: setID, ScheduleID: scheduleID, PerformedAt: day(d), WeightKg: weight, Reps: reps}
+	}
+
+	tests := []struct {
+		name string
+		sets []models.PerformedSet // เรียงตามเวลาแล้ว เหมือน GetClientExerciseSets
+		want []string
+	}{
+		{
+			name: "progression",
+			sets: []models.PerformedSet{
+				set(1, 10, 1, 80, 5),
+				set(2, 11, 3, 85, 5),
+				set(3, 12, 5, 85, 6),
+			},
+			want: []string{
+				"#10 max_weight=80(-)@80x5", "#10 e1rm=93.33(-)@80x5",
+				"#11 max_weight=85(80)@85x5", "#11 e1rm=99.17(93.33)@85x5",
+				"#12 e1rm=102(99.17)@85x6", "#12 reps_at_weight=6(5)@85x6",
+			},
+		},
+		{
+			// Session #20 (10 ม.ค.) บันทึกก่อน แล้วบันทึกย้อนหลัง #21 (3 ม.ค.):
+			// #21 เป็น PR แรก และ #20 เทียบกับ #21 (ไม่ใช่ลำดับที่บันทึก)
+			name: "out-of-order backfill",
+			sets: []models.PerformedSet{
+				set(5, 21, 3, 90, 5),
+				set(1, 20, 10, 100, 5),
+			},
+			want: []string{
+				"#21 max_weight=90(-)@90x5", "#21 e1rm=105(-)@90x5",
+				"#20 max_weight=100(90)@100x5", "#20 e1rm=116.67(105)@100x5",
+			},
+		},
+		{
+			// บันทึกย้อนหลังที่ทำได้เท่ากัน: Session หลังไม่เป็น PR อีก
+			name: "backfilled tie",
+			sets: []models.PerformedSet{
+				set(5, 21, 3, 100, 5),
+				set(1, 20, 10, 100, 5),
+			},
+			want: []string{"#21 max_weight=100(-)@100x5", "#21 e1rm=116.67(-)@100x5"},
+		},
+		{
+			// max_weight กับ e1rm มาจากคนละเซต และคนละ Session
+			name: "e1rm versus weight",
+			sets: []models.PerformedSet{
+				set(1, 30, 1, 100, 3),
+				set(2, 31, 8, 110, 1),
+				set(3, 32, 15, 90, 10),
+			},
+			want: []string{
+				"#30 max_weight=100(-)@100x3", "#30 e1rm=110(-)@100x3",
+				"#31 max_weight=110(100)@110x1",
+				"#32 e1rm=120(110)@90x10",
+			},
+		},
+		{
+			name: "no sets",
+			sets: nil,
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := replayPersonalRecords(7, 1, tt.sets)
+			if f := formatRecords(got); !reflect.DeepEqual(f, tt.want) {
+				t.Errorf("records = %v, want %v", f, tt.want)
+			}
+			// PR ต้องอ้างเซตและเวลาของ Session ที่ทำได้
+			bySet := map[int]models.PerformedSet{}
+			for _, s := range tt.sets {
+				bySet[s.SetID] = s
+			}
+			for _, pr := range got {
+				s, ok := bySet[pr.SessionLogSetID]
+				if !ok || s.ScheduleID != pr.ScheduleID || !s.PerformedAt.Equal(pr.AchievedAt) || s.WeightKg != pr.WeightKg {
+					t.Errorf("record %+v does not point at its set", pr)
+				}
+			}
+		})
+	}
+}
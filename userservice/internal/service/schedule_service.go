@@ -0,0 +1,402 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	_ "time/tzdata" // ฝังฐานข้อมูล Time Zone ไว้ใน Binary (Image alpine ไม่มี tzdata)
+
+	"users/internal/models"
+	"users/internal/recurrence"
+	"users/internal/repository"
+)
+
+// ขอบเขตของการแก้ไข/ยกเลิกนัดที่อยู่ใน series
+const (
+	ScopeThis      = "this"      // เฉพาะครั้งนี้
+	ScopeFollowing = "following" // ครั้งนี้และครั้งถัดไป
+	ScopeAll       = "all"       // ทั้ง series
+)
+
+var (
+	ErrInvalidScope     = errors.New("scope must be one of: this, following, all")
+	ErrInvalidSchedule  = errors.New("invalid schedule")
+	ErrSeriesNotFound   = errors.New("schedule series not found")
+	ErrScheduleConflict = errors.New("schedule conflicts with existing sessions")
+)
+
+// ScheduleConflictError นัดที่ชนกับนัดเดิม (errors.Is(err, ErrScheduleConflict) = true)
+type ScheduleConflictError struct {
+	Conflicts []models.ScheduleConflict
+}
+
+func (e *ScheduleConflictError) Error() string {
+	return fmt.Sprintf("%s (%d conflicting)", ErrScheduleConflict, len(e.Conflicts))
+}
+
+func (e *ScheduleConflictError) Unwrap() error { return ErrScheduleConflict }
+
+// ทุกเมธอดที่สร้าง/ย้ายนัดจะตรวจการชนกับนัด scheduled ของเทรนเนอร์และลูกค้า
+// allowConflicts=false: ชนแล้วคืน *ScheduleConflictError, true: บันทึกได้และแนบรายการที่ชนไว้ใน Conflicts
+type ScheduleService interface {
+	CreateSchedule(s *models.Schedule, allowConflicts bool) error
+	CreateSeries(s *models.ScheduleSeries, allowConflicts bool) error
+	GetSeries(id int) (*models.ScheduleSeries, error)
+	// scope=this คืนนัดที่แก้แล้ว, scope=following|all คืน series (พร้อมนัดทั้งหมด)
+	UpdateSchedule(id int, req *models.ScheduleUpdate, scope string, allowConflicts bool) (*models.Schedule, *models.ScheduleSeries, error)
+	DeleteSchedule(id int, scope string) error
+}
+
+type scheduleService struct {
+	repo    repository.ScheduleRepository
+	records RecordRebuilder
+	buffer  time.Duration // เวลาพักขั้นต่ำระหว่างนัด
+}
+
+// records ใช้คำนวณ PR ของลูกค้าใหม่เมื่อสถานะ/เวลา/การมีอยู่ของนัดเปลี่ยน
+func NewScheduleService(repo repository.ScheduleRepository, records RecordRebuilder, buffer time.Duration) ScheduleService {
+	return &scheduleService{repo: repo, records: records, buffer: buffer}
+}
+
+func (s *scheduleService) CreateSchedule(schedule *models.Schedule, allowConflicts bool) error {
+	if !schedule.EndTime.After(schedule.StartTime) {
+		return fmt.Errorf("%w: end_time must be after start_time", ErrInvalidSchedule)
+	}
+	conflicts, err := s.checkConflicts(schedule.TrainerID, schedule.ClientID,
+		[]time.Time{schedule.StartTime}, []time.Time{schedule.EndTime}, 0, nil, allowConflicts)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.CreateSchedule(schedule); err != nil {
+		return err
+	}
+	schedule.Conflicts = conflicts
+	return nil
+}
+
+func (s *scheduleService) CreateSeries(series *models.ScheduleSeries, allowConflicts bool) error {
+	occurrences, err := expandSeries(series)
+	if err != nil {
+		return err
+	}
+	conflicts, err := s.checkSeriesConflicts(series, occurrences, 0, allowConflicts)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.CreateSeries(series, occurrences); err != nil {
+		return err
+	}
+	series.Conflicts = conflicts
+	return nil
+}
+
+func (s *scheduleService) GetSeries(id int) (*models.ScheduleSeries, error) {
+	series, err := s.repo.GetSeriesByID(id)
+	if err == sql.ErrNoRows {
+		return nil, ErrSeriesNotFound
+	}
+	return series, err
+}
+
+func (s *scheduleService) UpdateSchedule(id int, req *models.ScheduleUpdate, scope string, allowConflicts bool) (*models.Schedule, *models.ScheduleSeries, error) {
+	existing, series, err := s.load(id, scope)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !req.EndTime.After(req.StartTime) {
+		return nil, nil, fmt.Errorf("%w: end_time must be after start_time", ErrInvalidSchedule)
+	}
+	// ไม่ส่ง client_id = ลูกค้าเดิม (Handler ตรวจความเป็นเจ้าของของ client_id ใหม่แล้ว)
+	if req.ClientID == 0 {
+		req.ClientID = existing.ClientID
+	}
+
+	// นัดเดี่ยว หรือแก้เฉพาะครั้งนี้
+	if series == nil || scope == ScopeThis {
+		req.ID = id
+		if req.Status == "" {
+			req.Status = existing.Status
+		}
+		var conflicts []models.ScheduleConflict
+		if req.Status == "scheduled" {
+			conflicts, err = s.checkConflicts(existing.TrainerID, req.ClientID,
+				[]time.Time{req.StartTime}, []time.Time{req.EndTime}, 0, []int{id}, allowConflicts)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		if err := s.repo.UpdateOccurrence(&req.Schedule); err != nil {
+			return nil, nil, err
+		}
+		// แก้แค่ชื่อ/เวลาจบ ไม่กระทบ PR
+		if req.Status != existing.Status || !req.StartTime.Equal(existing.StartTime) || req.ClientID != existing.ClientID {
+			if err := s.rebuildRecords(existing.ClientID, req.ClientID); err != nil {
+				return nil, nil, err
+			}
+		}
+		req.Conflicts = conflicts
+		return &req.Schedule, nil, nil
+	}
+
+	anchor := occurrenceStart(existing)
+	before, err := occurrencesBefore(series, anchor)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// ตัวแปรของ series ใหม่: เวลาเริ่มใหม่ของครั้งนี้ / ความยาว / Rule / Time Zone
+	next := *series
+	next.Title = req.Title
+	next.ClientID = req.ClientID
+	if req.RRule != "" {
+		next.RRule = req.RRule
+	}
+	if req.Timezone != "" {
+		next.Timezone = req.Timezone
+	}
+
+	if scope == ScopeAll || before == 0 {
+		// เลื่อนทั้ง series เท่ากับที่ครั้งนี้ถูกเลื่อน
+		shift := req.StartTime.Sub(anchor)
+		next.StartTime = series.StartTime.Add(shift)
+		next.EndTime = next.StartTime.Add(req.EndTime.Sub(req.StartTime))
+		occurrences, err := expandSeries(&next)
+		if err != nil {
+			return nil, nil, err
+		}
+		conflicts, err := s.checkSeriesConflicts(&next, occurrences, series.ID, allowConflicts)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := s.repo.UpdateSeries(&next, time.Time{}, id, occurrences); err != nil {
+			return nil, nil, err
+		}
+		if err := s.rebuildRecords(existing.ClientID, series.ClientID, next.ClientID); err != nil {
+			return nil, nil, err
+		}
+		return s.seriesWithConflicts(next.ID, conflicts)
+	}
+
+	// scope=following: ตัด series เดิมก่อนครั้งนี้ แล้วสร้าง series ใหม่เริ่มที่ครั้งนี้
+	old := *series
+	old.RRule, err = truncateRule(series.RRule, before, anchor)
+	if err != nil {
+		return nil, nil, err
+	}
+	next.StartTime = req.StartTime
+	next.EndTime = req.EndTime
+	if req.RRule == "" {
+		if next.RRule, err = remainingRule(series.RRule, before); err != nil {
+			return nil, nil, err
+		}
+	}
+	occurrences, err := expandSeries(&next)
+	if err != nil {
+		return nil, nil, err
+	}
+	conflicts, err := s.checkSeriesConflicts(&next, occurrences, series.ID, allowConflicts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.repo.SplitSeries(&old, anchor, id, &next, occurrences); err != nil {
+		return nil, nil, err
+	}
+	if err := s.rebuildRecords(existing.ClientID, series.ClientID, next.ClientID); err != nil {
+		return nil, nil, err
+	}
+	return s.seriesWithConflicts(next.ID, conflicts)
+}
+
+func (s *scheduleService) seriesWithConflicts(id int, conflicts []models.ScheduleConflict) (*models.Schedule, *models.ScheduleSeries, error) {
+	updated, err := s.GetSeries(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	updated.Conflicts = conflicts
+	return nil, updated, nil
+}
+
+func (s *scheduleService) DeleteSchedule(id int, scope string) error {
+	existing, series, err := s.load(id, scope)
+	if err != nil {
+		return err
+	}
+	if err := s.delete(id, existing, series, scope); err != nil {
+		return err
+	}
+	// Session ที่ถูกลบ/ยกเลิกไม่นับในประวัติแล้ว
+	if series != nil {
+		return s.rebuildRecords(existing.ClientID, series.ClientID)
+	}
+	return s.rebuildRecords(existing.ClientID)
+}
+
+// delete ลบ/ยกเลิกนัดตาม scope (series = nil คือนัดเดี่ยว)
+func (s *scheduleService) delete(id int, existing *models.Schedule, series *models.ScheduleSeries, scope string) error {
+	switch {
+	case series == nil:
+		return s.repo.DeleteSchedule(id)
+	case scope == ScopeThis:
+		// ยกเลิกเฉพาะครั้งนี้ (เก็บแถวไว้เป็น exception เพื่อไม่ให้ถูกสร้างซ้ำ)
+		return s.repo.CancelOccurrence(id)
+	case scope == ScopeAll:
+		return s.repo.DeleteSeries(series.ID)
+	}
+
+	anchor := occurrenceStart(existing)
+	before, err := occurrencesBefore(series, anchor)
+	if err != nil {
+		return err
+	}
+	if before == 0 {
+		return s.repo.DeleteSeries(series.ID)
+	}
+	if series.RRule, err = truncateRule(series.RRule, before, anchor); err != nil {
+		return err
+	}
+	return s.repo.TruncateSeries(series, anchor, id)
+}
+
+// rebuildRecords คำนวณ PR ของลูกค้าที่ได้รับผลกระทบใหม่ (ลูกค้าซ้ำกันคำนวณครั้งเดียว)
+func (s *scheduleService) rebuildRecords(clientIDs ...int) error {
+	done := map[int]bool{}
+	for _, id := range clientIDs {
+		if id == 0 || done[id] {
+			continue
+		}
+		done[id] = true
+		if err := s.records.RebuildClient(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// load ดึงนัด และ series ของนัดนั้น (nil = นัดเดี่ยว)
+func (s *scheduleService) load(id int, scope string) (*models.Schedule, *models.ScheduleSeries, error) {
+	if scope != ScopeThis && scope != ScopeFollowing && scope != ScopeAll {
+		return nil, nil, ErrInvalidScope
+	}
+	existing, err := s.repo.GetScheduleByID(id)
+	if err == sql.ErrNoRows {
+		return nil, nil, ErrScheduleNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if existing.SeriesID == nil {
+		return existing, nil, nil
+	}
+	series, err := s.GetSeries(*existing.SeriesID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return existing, series, nil
+}
+
+// checkConflicts หานัดที่ชนกับช่วงเวลา starts[i]..ends[i] (คืน error ถ้าชนและไม่อนุญาต)
+func (s *scheduleService) checkConflicts(trainerID, clientID int, starts, ends []time.Time, excludeSeriesID int, excludeIDs []int, allowConflicts bool) ([]models.ScheduleConflict, error) {
+	found, err := s.repo.FindConflicts(trainerID, clientID, starts, ends, s.buffer, excludeSeriesID, excludeIDs)
+	if err != nil {
+		return nil, err
+	}
+	conflicts := make([]models.ScheduleConflict, len(found))
+	for i := range found {
+		conflicts[i] = models.ScheduleConflict{StartTime: found[i].StartTime, EndTime: found[i].EndTime}
+		// นัดของเทรนเนอร์คนอื่น (ชนเพราะเป็นลูกค้าคนเดียวกัน): ไม่เปิดเผยรายละเอียด
+		if found[i].TrainerID == trainerID {
+			conflicts[i].Schedule = &found[i]
+		}
+	}
+	if len(conflicts) > 0 && !allowConflicts {
+		return nil, &ScheduleConflictError{Conflicts: conflicts}
+	}
+	return conflicts, nil
+}
+
+func (s *scheduleService) checkSeriesConflicts(series *models.ScheduleSeries, occurrences []time.Time, excludeSeriesID int, allowConflicts bool) ([]models.ScheduleConflict, error) {
+	duration := series.EndTime.Sub(series.StartTime)
+	ends := make([]time.Time, len(occurrences))
+	for i, start := range occurrences {
+		ends[i] = start.Add(duration)
+	}
+	return s.checkConflicts(series.TrainerID, series.ClientID, occurrences, ends, excludeSeriesID, nil, allowConflicts)
+}
+
+// expandSeries ตรวจ series แล้วขยาย Rule เป็นเวลาเริ่มของแต่ละครั้ง (ตาม Time Zone ของ series)
+func expandSeries(series *models.ScheduleSeries) ([]time.Time, error) {
+	if series.Timezone == "" {
+		series.Timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(series.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidSchedule, series.Timezone)
+	}
+	if !series.EndTime.After(series.StartTime) {
+		return nil, fmt.Errorf("%w: end_time must be after start_time", ErrInvalidSchedule)
+	}
+	rule, err := recurrence.Parse(series.RRule)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+	series.RRule = rule.String()
+
+	occurrences := rule.All(series.StartTime.In(loc))
+	if len(occurrences) == 0 {
+		return nil, fmt.Errorf("%w: recurrence rule produces no occurrences", ErrInvalidSchedule)
+	}
+	return occurrences, nil
+}
+
+// occurrencesBefore จำนวนครั้งตาม Rule ที่เริ่มก่อน anchor
+func occurrencesBefore(series *models.ScheduleSeries, anchor time.Time) (int, error) {
+	occurrences, err := expandSeries(series)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, t := range occurrences {
+		if t.Before(anchor) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// truncateRule ให้ Rule จบก่อน anchor: COUNT -> จำนวนครั้งที่เหลือก่อนหน้า, UNTIL -> 1 วินาทีก่อน anchor
+func truncateRule(rrule string, before int, anchor time.Time) (string, error) {
+	rule, err := recurrence.Parse(rrule)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+	if rule.Count > 0 {
+		rule.Count = before
+	} else {
+		until := anchor.Add(-time.Second).UTC()
+		rule.Until = &until
+	}
+	return rule.String(), nil
+}
+
+// remainingRule Rule ของส่วนที่เหลือหลังแยก series (COUNT ลดลงตามจำนวนครั้งที่อยู่ใน series เดิม)
+func remainingRule(rrule string, before int) (string, error) {
+	rule, err := recurrence.Parse(rrule)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+	if rule.Count > 0 {
+		rule.Count -= before
+		if rule.Count < 1 {
+			rule.Count = 1
+		}
+	}
+	return rule.String(), nil
+}
+
+func occurrenceStart(s *models.Schedule) time.Time {
+	if s.OriginalStart != nil {
+		return *s.OriginalStart
+	}
+	return s.StartTime
+}
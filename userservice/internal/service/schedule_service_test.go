@@ -0,0 +1,488 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"users/internal/models"
+	"users/internal/recurrence"
+	"users/internal/repository"
+)
+
+const testSeriesID = 10
+
+func bangkokTime(t *testing.T, month time.Month, day, hour int) time.Time {
+	t.Helper()
+	loc, err := time.LoadLocation("Asia/Bangkok")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	return time.Date(2024, month, day, hour, 0, 0, 0, loc)
+}
+
+func expand(t *testing.T, rrule string, dtstart time.Time) []time.Time {
+	t.Helper()
+	rule, err := recurrence.Parse(rrule)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", rrule, err)
+	}
+	return rule.All(dtstart)
+}
+
+func equalTimes(a, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// ตัด series ที่ครั้งที่ i: series เดิม (truncateRule) + series ใหม่ที่เริ่มครั้งที่ i (remainingRule)
+// ต้องได้ครั้งเดียวกับ series ก่อนแยกทุกครั้ง
+func TestTruncateAndRemainingRule(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		rrule         string
+		dtstart       time.Time
+		split         int
+		wantTruncated string
+		wantRemaining string
+	}{
+		{
+			name:          "weekly byday count",
+			rrule:         "FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=6",
+			dtstart:       bangkokTime(t, time.January, 1, 8),
+			split:         3,
+			wantTruncated: "FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=3",
+			wantRemaining: "FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=3",
+		},
+		{
+			// UNTIL = 1 วินาทีก่อนครั้งที่แยก (08:00 +07 = 01:00 UTC)
+			name:          "weekly byday until",
+			rrule:         "FREQ=WEEKLY;BYDAY=MO,WE,FR;UNTIL=20240131",
+			dtstart:       bangkokTime(t, time.January, 1, 8),
+			split:         4,
+			wantTruncated: "FREQ=WEEKLY;BYDAY=MO,WE,FR;UNTIL=20240110T005959Z",
+			wantRemaining: "FREQ=WEEKLY;BYDAY=MO,WE,FR;UNTIL=20240131T235959Z",
+		},
+		{
+			name:          "monthly bymonthday 31",
+			rrule:         "FREQ=MONTHLY;BYMONTHDAY=31;COUNT=4",
+			dtstart:       bangkokTime(t, time.January, 31, 9),
+			split:         1,
+			wantTruncated: "FREQ=MONTHLY;BYMONTHDAY=31;COUNT=1",
+			wantRemaining: "FREQ=MONTHLY;BYMONTHDAY=31;COUNT=3",
+		},
+		{
+			name:          "daily across dst start",
+			rrule:         "FREQ=DAILY;COUNT=5",
+			dtstart:       time.Date(2024, 3, 8, 9, 0, 0, 0, newYork),
+			split:         3,
+			wantTruncated: "FREQ=DAILY;COUNT=3",
+			wantRemaining: "FREQ=DAILY;COUNT=2",
+		},
+		{
+			name:          "weekly interval until across dst end",
+			rrule:         "FREQ=WEEKLY;INTERVAL=2;UNTIL=20241231",
+			dtstart:       time.Date(2024, 10, 1, 18, 0, 0, 0, newYork),
+			split:         2,
+			wantTruncated: "FREQ=WEEKLY;INTERVAL=2;UNTIL=20241029T215959Z",
+			wantRemaining: "FREQ=WEEKLY;INTERVAL=2;UNTIL=20241231T235959Z",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			all := expand(t, tt.rrule, tt.dtstart)
+			anchor := all[tt.split]
+
+			truncated, err := truncateRule(tt.rrule, tt.split, anchor)
+			if err != nil {
+				t.Fatalf("truncateRule: %v", err)
+			}
+			remaining, err := remainingRule(tt.rrule, tt.split)
+			if err != nil {
+				t.Fatalf("remainingRule: %v", err)
+			}
+			if truncated != tt.wantTruncated {
+				t.Errorf("truncateRule = %q, want %q", truncated, tt.wantTruncated)
+			}
+			if remaining != tt.wantRemaining {
+				t.Errorf("remainingRule = %q, want %q", remaining, tt.wantRemaining)
+			}
+
+			if got := expand(t, truncated, tt.dtstart); !equalTimes(got, all[:tt.split]) {
+				t.Errorf("truncated series = %v, want %v", got, all[:tt.split])
+			}
+			if got := expand(t, remaining, anchor); !equalTimes(got, all[tt.split:]) {
+				t.Errorf("remaining series = %v, want %v", got, all[tt.split:])
+			}
+		})
+	}
+}
+
+func TestRemainingRuleKeepsAtLeastOne(t *testing.T) {
+	got, err := remainingRule("FREQ=DAILY;COUNT=3", 5)
+	if err != nil {
+		t.Fatalf("remainingRule: %v", err)
+	}
+	if got != "FREQ=DAILY;COUNT=1" {
+		t.Errorf("remainingRule = %q, want COUNT=1", got)
+	}
+	if _, err := truncateRule("FREQ=DAILY", 1, time.Now()); !errors.Is(err, ErrInvalidSchedule) {
+		t.Errorf("truncateRule with invalid rule: err = %v, want ErrInvalidSchedule", err)
+	}
+}
+
+// fakeScheduleRepo series เดียว (testSeriesID) และเก็บการเรียกที่แก้ข้อมูล
+type fakeScheduleRepo struct {
+	repository.ScheduleRepository
+
+	schedules map[int]*models.Schedule
+	series    *models.ScheduleSeries
+	conflicts []models.Schedule
+
+	updatedOccurrence *models.Schedule
+	cancelled         int
+	deletedSeries     int
+
+	updated            *models.ScheduleSeries
+	updatedOccurrences []time.Time
+
+	splitOld, splitNext *models.ScheduleSeries
+	splitFrom           time.Time
+	splitOccurrences    []time.Time
+
+	truncated     *models.ScheduleSeries
+	truncatedFrom time.Time
+}
+
+func (r *fakeScheduleRepo) GetScheduleByID(id int) (*models.Schedule, error) {
+	s, ok := r.schedules[id]
+	if !ok {
+		return nil, errors.New("unexpected schedule id")
+	}
+	copied := *s
+	return &copied, nil
+}
+
+func (r *fakeScheduleRepo) GetSeriesByID(id int) (*models.ScheduleSeries, error) {
+	if id == testSeriesID {
+		copied := *r.series
+		return &copied, nil
+	}
+	return &models.ScheduleSeries{ID: id}, nil
+}
+
+func (r *fakeScheduleRepo) FindConflicts(trainerID, clientID int, starts, ends []time.Time, buffer time.Duration, excludeSeriesID int, excludeIDs []int) ([]models.Schedule, error) {
+	return r.conflicts, nil
+}
+
+func (r *fakeScheduleRepo) CreateSchedule(s *models.Schedule) error { return nil }
+
+func (r *fakeScheduleRepo) UpdateOccurrence(s *models.Schedule) error {
+	r.updatedOccurrence = s
+	return nil
+}
+
+func (r *fakeScheduleRepo) CancelOccurrence(id int) error {
+	r.cancelled = id
+	return nil
+}
+
+func (r *fakeScheduleRepo) DeleteSeries(id int) error {
+	r.deletedSeries = id
+	return nil
+}
+
+func (r *fakeScheduleRepo) UpdateSeries(s *models.ScheduleSeries, from time.Time, anchorID int, occurrences []time.Time) error {
+	r.updated, r.updatedOccurrences = s, occurrences
+	return nil
+}
+
+func (r *fakeScheduleRepo) SplitSeries(old *models.ScheduleSeries, from time.Time, anchorID int, next *models.ScheduleSeries, occurrences []time.Time) error {
+	next.ID = testSeriesID + 1
+	r.splitOld, r.splitFrom, r.splitNext, r.splitOccurrences = old, from, next, occurrences
+	return nil
+}
+
+func (r *fakeScheduleRepo) TruncateSeries(s *models.ScheduleSeries, from time.Time, anchorID int) error {
+	r.truncated, r.truncatedFrom = s, from
+	return nil
+}
+
+// fakeRecordRebuilder เก็บลูกค้าที่ถูกคำนวณ PR ใหม่
+type fakeRecordRebuilder struct {
+	clients []int
+}
+
+func (r *fakeRecordRebuilder) RebuildClient(clientID int) error {
+	r.clients = append(r.clients, clientID)
+	return nil
+}
+
+// newSeriesFixture series จ/พ/ศ 08:00-09:00 (กรุงเทพฯ) 6 ครั้ง เริ่ม 1 ม.ค. 2024 นัดมี id = 100 + ลำดับ
+func newSeriesFixture(t *testing.T, rrule string) (*fakeScheduleRepo, []time.Time) {
+	t.Helper()
+	start := bangkokTime(t, time.January, 1, 8)
+	series := &models.ScheduleSeries{
+		ID: testSeriesID, Title: "Strength", TrainerID: 1, ClientID: 2, RRule: rrule,
+		StartTime: start, EndTime: start.Add(time.Hour), Timezone: "Asia/Bangkok",
+	}
+	occurrences := expand(t, rrule, start)
+	repo := &fakeScheduleRepo{schedules: map[int]*models.Schedule{}, series: series}
+	seriesID := testSeriesID
+	for i, at := range occurrences {
+		original := at
+		repo.schedules[100+i] = &models.Schedule{
+			ID: 100 + i, Title: series.Title, TrainerID: 1, ClientID: 2, Status: "scheduled",
+			StartTime: at, EndTime: at.Add(time.Hour), SeriesID: &seriesID, OriginalStart: &original,
+		}
+	}
+	return repo, occurrences
+}
+
+func TestUpdateScheduleScopes(t *testing.T) {
+	const rrule = "FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=6"
+
+	t.Run("this", func(t *testing.T) {
+		repo, occ := newSeriesFixture(t, rrule)
+		s := NewScheduleService(repo, &fakeRecordRebuilder{}, 0)
+		req := &models.ScheduleUpdate{Schedule: models.Schedule{Title: "Moved", StartTime: occ[3].Add(2 * time.Hour), EndTime: occ[3].Add(3 * time.Hour)}}
+
+		schedule, series, err := s.UpdateSchedule(103, req, ScopeThis, false)
+		if err != nil {
+			t.Fatalf("UpdateSchedule: %v", err)
+		}
+		if series != nil || schedule == nil || repo.updatedOccurrence == nil {
+			t.Fatalf("scope=this must update only the occurrence (schedule %v, series %v)", schedule, series)
+		}
+		if repo.updatedOccurrence.ID != 103 || repo.updatedOccurrence.ClientID != 2 || repo.updatedOccurrence.Status != "scheduled" {
+			t.Errorf("updated occurrence = %+v", repo.updatedOccurrence)
+		}
+		if repo.updated != nil || repo.splitNext != nil {
+			t.Error("scope=this must not touch the series")
+		}
+	})
+
+	t.Run("following with count", func(t *testing.T) {
+		repo, occ := newSeriesFixture(t, rrule)
+		s := NewScheduleService(repo, &fakeRecordRebuilder{}, 0)
+		// ครั้งที่ 4 (8 ม.ค.) เป็นต้นไปย้ายไป 18:00
+		req := &models.ScheduleUpdate{Schedule: models.Schedule{Title: "Evening", StartTime: occ[3].Add(10 * time.Hour), EndTime: occ[3].Add(11 * time.Hour)}}
+
+		_, series, err := s.UpdateSchedule(103, req, ScopeFollowing, false)
+		if err != nil {
+			t.Fatalf("UpdateSchedule: %v", err)
+		}
+		if series == nil || series.ID != testSeriesID+1 {
+			t.Fatalf("series = %+v, want the new series", series)
+		}
+		if repo.splitOld.RRule != "FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=3" {
+			t.Errorf("old rule = %q", repo.splitOld.RRule)
+		}
+		if repo.splitNext.RRule != "FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=3" || repo.splitNext.Title != "Evening" {
+			t.Errorf("next series = %+v", repo.splitNext)
+		}
+		if !repo.splitFrom.Equal(occ[3]) {
+			t.Errorf("split from = %v, want %v", repo.splitFrom, occ[3])
+		}
+		want := []time.Time{occ[3].Add(10 * time.Hour), occ[4].Add(10 * time.Hour), occ[5].Add(10 * time.Hour)}
+		if !equalTimes(repo.splitOccurrences, want) {
+			t.Errorf("new occurrences = %v, want %v", repo.splitOccurrences, want)
+		}
+	})
+
+	t.Run("following with until", func(t *testing.T) {
+		repo, occ := newSeriesFixture(t, "FREQ=WEEKLY;BYDAY=MO,WE,FR;UNTIL=20240112")
+		s := NewScheduleService(repo, &fakeRecordRebuilder{}, 0)
+		req := &models.ScheduleUpdate{Schedule: models.Schedule{Title: "Strength", StartTime: occ[3], EndTime: occ[3].Add(90 * time.Minute)}}
+
+		if _, _, err := s.UpdateSchedule(103, req, ScopeFollowing, false); err != nil {
+			t.Fatalf("UpdateSchedule: %v", err)
+		}
+		if repo.splitOld.RRule != "FREQ=WEEKLY;BYDAY=MO,WE,FR;UNTIL=20240108T005959Z" {
+			t.Errorf("old rule = %q", repo.splitOld.RRule)
+		}
+		if repo.splitNext.RRule != "FREQ=WEEKLY;BYDAY=MO,WE,FR;UNTIL=20240112T235959Z" {
+			t.Errorf("next rule = %q", repo.splitNext.RRule)
+		}
+		if !equalTimes(repo.splitOccurrences, occ[3:]) {
+			t.Errorf("new occurrences = %v, want %v", repo.splitOccurrences, occ[3:])
+		}
+		if got := repo.splitNext.EndTime.Sub(repo.splitNext.StartTime); got != 90*time.Minute {
+			t.Errorf("new duration = %v, want 90m", got)
+		}
+	})
+
+	t.Run("following from the first occurrence updates the whole series", func(t *testing.T) {
+		repo, occ := newSeriesFixture(t, rrule)
+		s := NewScheduleService(repo, &fakeRecordRebuilder{}, 0)
+		req := &models.ScheduleUpdate{Schedule: models.Schedule{Title: "Strength", StartTime: occ[0], EndTime: occ[0].Add(time.Hour)}}
+
+		if _, _, err := s.UpdateSchedule(100, req, ScopeFollowing, false); err != nil {
+			t.Fatalf("UpdateSchedule: %v", err)
+		}
+		if repo.splitNext != nil || repo.updated == nil {
+			t.Fatal("expected UpdateSeries instead of SplitSeries")
+		}
+	})
+
+	t.Run("all shifts every occurrence", func(t *testing.T) {
+		repo, occ := newSeriesFixture(t, rrule)
+		s := NewScheduleService(repo, &fakeRecordRebuilder{}, 0)
+		// เลื่อนครั้งที่ 4 ไป 1 ชั่วโมง: ทุกครั้งเลื่อนตาม
+		req := &models.ScheduleUpdate{Schedule: models.Schedule{Title: "Strength", StartTime: occ[3].Add(time.Hour), EndTime: occ[3].Add(2 * time.Hour)}}
+
+		if _, _, err := s.UpdateSchedule(103, req, ScopeAll, false); err != nil {
+			t.Fatalf("UpdateSchedule: %v", err)
+		}
+		if repo.updated == nil || repo.splitNext != nil {
+			t.Fatal("expected UpdateSeries")
+		}
+		if !repo.updated.StartTime.Equal(occ[0].Add(time.Hour)) {
+			t.Errorf("series start = %v, want %v", repo.updated.StartTime, occ[0].Add(time.Hour))
+		}
+		if len(repo.updatedOccurrences) != len(occ) {
+			t.Fatalf("got %d occurrences, want %d", len(repo.updatedOccurrences), len(occ))
+		}
+		for i := range occ {
+			if !repo.updatedOccurrences[i].Equal(occ[i].Add(time.Hour)) {
+				t.Errorf("occurrence %d = %v, want %v", i, repo.updatedOccurrences[i], occ[i].Add(time.Hour))
+			}
+		}
+	})
+
+	t.Run("invalid scope", func(t *testing.T) {
+		repo, occ := newSeriesFixture(t, rrule)
+		req := &models.ScheduleUpdate{Schedule: models.Schedule{StartTime: occ[0], EndTime: occ[0].Add(time.Hour)}}
+		if _, _, err := NewScheduleService(repo, &fakeRecordRebuilder{}, 0).UpdateSchedule(100, req, "some", false); !errors.Is(err, ErrInvalidScope) {
+			t.Fatalf("err = %v, want ErrInvalidScope", err)
+		}
+	})
+}
+
+func TestDeleteScheduleScopes(t *testing.T) {
+	const rrule = "FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=6"
+
+	repo, _ := newSeriesFixture(t, rrule)
+	if err := NewScheduleService(repo, &fakeRecordRebuilder{}, 0).DeleteSchedule(103, ScopeThis); err != nil || repo.cancelled != 103 {
+		t.Errorf("scope=this: err %v, cancelled %d", err, repo.cancelled)
+	}
+
+	repo, _ = newSeriesFixture(t, rrule)
+	if err := NewScheduleService(repo, &fakeRecordRebuilder{}, 0).DeleteSchedule(103, ScopeAll); err != nil || repo.deletedSeries != testSeriesID {
+		t.Errorf("scope=all: err %v, deleted series %d", err, repo.deletedSeries)
+	}
+
+	repo, occ := newSeriesFixture(t, rrule)
+	if err := NewScheduleService(repo, &fakeRecordRebuilder{}, 0).DeleteSchedule(103, ScopeFollowing); err != nil {
+		t.Fatalf("scope=following: %v", err)
+	}
+	if repo.truncated == nil || repo.truncated.RRule != "FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=3" || !repo.truncatedFrom.Equal(occ[3]) {
+		t.Errorf("scope=following: truncated %+v from %v", repo.truncated, repo.truncatedFrom)
+	}
+
+	repo, _ = newSeriesFixture(t, rrule)
+	if err := NewScheduleService(repo, &fakeRecordRebuilder{}, 0).DeleteSchedule(100, ScopeFollowing); err != nil || repo.deletedSeries != testSeriesID {
+		t.Errorf("scope=following from the first occurrence: err %v, deleted series %d", err, repo.deletedSeries)
+	}
+}
+
+// นัดที่สถานะ/เวลา/ลูกค้า/การมีอยู่เปลี่ยน ต้องคำนวณ PR ของลูกค้าที่เกี่ยวข้องใหม่ (ลูกค้าละครั้ง)
+func TestScheduleChangesRebuildRecords(t *testing.T) {
+	const rrule = "FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=6"
+	update := func(id int, scope string, change func(req *models.ScheduleUpdate, occ []time.Time)) func(s ScheduleService, occ []time.Time) error {
+		return func(s ScheduleService, occ []time.Time) error {
+			req := &models.ScheduleUpdate{Schedule: models.Schedule{Title: "Strength", StartTime: occ[id-100], EndTime: occ[id-100].Add(time.Hour)}}
+			change(req, occ)
+			_, _, err := s.UpdateSchedule(id, req, scope, true)
+			return err
+		}
+	}
+	remove := func(id int, scope string) func(s ScheduleService, occ []time.Time) error {
+		return func(s ScheduleService, occ []time.Time) error { return s.DeleteSchedule(id, scope) }
+	}
+	noChange := func(req *models.ScheduleUpdate, occ []time.Time) {}
+
+	tests := []struct {
+		name string
+		do   func(s ScheduleService, occ []time.Time) error
+		want []int
+	}{
+		{"rename only", update(103, ScopeThis, func(req *models.ScheduleUpdate, occ []time.Time) { req.Title = "Renamed" }), nil},
+		{"longer session", update(103, ScopeThis, func(req *models.ScheduleUpdate, occ []time.Time) { req.EndTime = occ[3].Add(2 * time.Hour) }), nil},
+		{"same values", update(103, ScopeThis, noChange), nil},
+		{"reschedule", update(103, ScopeThis, func(req *models.ScheduleUpdate, occ []time.Time) {
+			req.StartTime, req.EndTime = occ[3].Add(-48*time.Hour), occ[3].Add(-47*time.Hour)
+		}), []int{2}},
+		{"cancel", update(103, ScopeThis, func(req *models.ScheduleUpdate, occ []time.Time) { req.Status = "cancelled" }), []int{2}},
+		{"move to another client", update(103, ScopeThis, func(req *models.ScheduleUpdate, occ []time.Time) { req.ClientID = 5 }), []int{2, 5}},
+		{"shift all", update(103, ScopeAll, func(req *models.ScheduleUpdate, occ []time.Time) {
+			req.StartTime, req.EndTime = occ[3].Add(time.Hour), occ[3].Add(2*time.Hour)
+		}), []int{2}},
+		{"split following", update(103, ScopeFollowing, func(req *models.ScheduleUpdate, occ []time.Time) { req.ClientID = 5 }), []int{2, 5}},
+		{"delete this", remove(103, ScopeThis), []int{2}},
+		{"delete following", remove(103, ScopeFollowing), []int{2}},
+		{"delete all", remove(103, ScopeAll), []int{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, occ := newSeriesFixture(t, rrule)
+			records := &fakeRecordRebuilder{}
+			if err := tt.do(NewScheduleService(repo, records, 0), occ); err != nil {
+				t.Fatalf("%v", err)
+			}
+			if len(records.clients) != len(tt.want) {
+				t.Fatalf("rebuilt clients %v, want %v", records.clients, tt.want)
+			}
+			for i := range tt.want {
+				if records.clients[i] != tt.want[i] {
+					t.Errorf("rebuilt clients %v, want %v", records.clients, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestConflictsHideOtherTrainersSchedules(t *testing.T) {
+	start := bangkokTime(t, time.January, 1, 8)
+	repo := &fakeScheduleRepo{conflicts: []models.Schedule{
+		{ID: 1, Title: "Own session", TrainerID: 1, ClientID: 3, StartTime: start, EndTime: start.Add(time.Hour)},
+		{ID: 2, Title: "Other trainer's session", TrainerID: 9, ClientID: 2, StartTime: start.Add(30 * time.Minute), EndTime: start.Add(90 * time.Minute)},
+	}}
+	s := NewScheduleService(repo, &fakeRecordRebuilder{}, 0)
+
+	err := s.CreateSchedule(&models.Schedule{TrainerID: 1, ClientID: 2, StartTime: start, EndTime: start.Add(time.Hour)}, false)
+	var conflictErr *ScheduleConflictError
+	if !errors.As(err, &conflictErr) || !errors.Is(err, ErrScheduleConflict) {
+		t.Fatalf("err = %v, want *ScheduleConflictError", err)
+	}
+	if len(conflictErr.Conflicts) != 2 {
+		t.Fatalf("got %d conflicts, want 2", len(conflictErr.Conflicts))
+	}
+	if own := conflictErr.Conflicts[0]; own.Schedule == nil || own.Schedule.Title != "Own session" {
+		t.Errorf("own conflict = %+v, want full details", own)
+	}
+	if other := conflictErr.Conflicts[1]; other.Schedule != nil || !other.StartTime.Equal(start.Add(30*time.Minute)) {
+		t.Errorf("other trainer's conflict = %+v, want only the time slot", other)
+	}
+
+	// conflicts=warn บันทึกได้และแนบรายการที่ชน
+	schedule := &models.Schedule{TrainerID: 1, ClientID: 2, StartTime: start, EndTime: start.Add(time.Hour)}
+	if err := s.CreateSchedule(schedule, true); err != nil {
+		t.Fatalf("CreateSchedule with allowConflicts: %v", err)
+	}
+	if len(schedule.Conflicts) != 2 {
+		t.Errorf("got %d conflicts attached, want 2", len(schedule.Conflicts))
+	}
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"errors"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"users/internal/models"
+	"users/internal/repository"
+)
+
+const (
+	SearchDefaultLimit = 5
+	SearchMaxLimit     = 20
+
+	searchMinQueryLength = 2
+	searchMaxQueryLength = 200
+	// ความยาว (ตัวอักษร) ของ snippet รอบคำที่ตรง
+	searchSnippetLength = 160
+)
+
+var (
+	ErrSearchQueryLength = errors.New("q must be between 2 and 200 characters")
+	ErrInvalidSearchType = errors.New("types must be one or more of: clients, notes, programs, exercises")
+)
+
+// SearchQuery คำค้นของผู้ใช้ที่ Login อยู่ (Types = ชนิดที่ผู้ใช้มีสิทธิ์ค้น ตรวจสิทธิ์แล้วที่ Handler)
+type SearchQuery struct {
+	UserID int
+	Role   string
+	Text   string
+	Types  []string
+	Limit  int // ต่อกลุ่ม
+}
+
+type SearchService interface {
+	Search(q SearchQuery) (*models.SearchResponse, error)
+}
+
+type searchService struct {
+	repo repository.SearchRepository
+}
+
+func NewSearchService(repo repository.SearchRepository) SearchService {
+	return &searchService{repo: repo}
+}
+
+// Search ค้นทุกชนิดที่ขอ แยกกลุ่มตามชนิด (ลำดับตาม models.SearchTypes) แต่ละกลุ่มเรียงตามความเกี่ยวข้อง
+func (s *searchService) Search(q SearchQuery) (*models.SearchResponse, error) {
+	text := strings.Join(strings.Fields(q.Text), " ")
+	if n := utf8.RuneCountInString(text); n < searchMinQueryLength || n > searchMaxQueryLength {
+		return nil, ErrSearchQueryLength
+	}
+	for _, t := range q.Types {
+		if !containsString(models.SearchTypes, t) {
+			return nil, ErrInvalidSearchType
+		}
+	}
+	limit := q.Limit
+	if limit <= 0 || limit > SearchMaxLimit {
+		limit = SearchDefaultLimit
+	}
+
+	terms := searchTerms(text)
+	resp := &models.SearchResponse{Query: text, Groups: []models.SearchGroup{}}
+	for _, t := range models.SearchTypes {
+		if !containsString(q.Types, t) {
+			continue
+		}
+		results, total, err := s.repo.Search(t, q.UserID, q.Role, text, limit)
+		if err != nil {
+			return nil, err
+		}
+		for i := range results {
+			results[i].Highlight = models.SearchHighlight{
+				Title:   highlight(results[i].Title, terms, 0),
+				Snippet: highlight(results[i].Body, terms, searchSnippetLength),
+			}
+		}
+		resp.Groups = append(resp.Groups, models.SearchGroup{Type: t, Total: total, Results: results})
+	}
+	return resp, nil
+}
+
+// searchTerms คำที่ใช้ Highlight: แยกตามช่องว่าง ข้ามคำที่ขึ้นต้นด้วย - (ไม่เอาคำนี้) และ or ของ websearch
+func searchTerms(text string) []string {
+	var terms []string
+	for _, f := range strings.Fields(strings.ReplaceAll(text, `"`, " ")) {
+		if strings.HasPrefix(f, "-") || strings.EqualFold(f, "or") {
+			continue
+		}
+		terms = append(terms, strings.ToLower(f))
+	}
+	return terms
+}
+
+// highlight escape HTML แล้วครอบคำที่ตรงด้วย <mark> (ไม่สนตัวพิมพ์เล็ก/ใหญ่)
+// width > 0 ตัดเหลือช่วงรอบคำแรกที่ตรง (หรือต้นข้อความ) พร้อม … ด้านที่ถูกตัด
+func highlight(text string, terms []string, width int) string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	// ช่วง [start, end) ที่ตรงกับคำค้น รวมช่วงที่ซ้อนกัน
+	type span struct{ start, end int }
+	var spans []span
+	for _, t := range terms {
+		term := []rune(t)
+		for i := 0; i+len(term) <= len(lower); i++ {
+			if runesEqual(lower[i:i+len(term)], term) {
+				spans = append(spans, span{i, i + len(term)})
+			}
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var merged []span
+	for _, s := range spans {
+		if n := len(merged); n > 0 && s.start <= merged[n-1].end {
+			if s.end > merged[n-1].end {
+				merged[n-1].end = s.end
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+
+	from, to := 0, len(runes)
+	if width > 0 && len(runes) > width {
+		if len(merged) > 0 {
+			from = merged[0].start - width/3
+			if from < 0 {
+				from = 0
+			}
+		}
+		to = from + width
+		if to > len(runes) {
+			to = len(runes)
+			from = to - width
+		}
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	pos := from
+	for _, s := range merged {
+		start, end := max(s.start, from), min(s.end, to)
+		if start >= end {
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[pos:start])))
+		b.WriteString("<mark>" + html.EscapeString(string(runes[start:end])) + "</mark>")
+		pos = end
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:to])))
+	if to < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+func runesEqual(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
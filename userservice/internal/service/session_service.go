@@ -0,0 +1,339 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"users/internal/models"
+	"users/internal/repository"
+)
+
+var (
+	ErrScheduleNotFound  = errors.New("schedule not found")
+	ErrScheduleCancelled = errors.New("cannot log a cancelled session")
+	ErrInvalidWorkoutLog = errors.New("invalid workout log")
+)
+
+type SessionService interface {
+	// สิทธิ์เข้าถึง Schedule ตรวจที่ Route แล้ว (owns.Require: เทรนเนอร์เจ้าของ หรือ role ที่ bypass ได้)
+	CreateWorkoutLog(scheduleID int, w *models.WorkoutLog) error
+	// แก้ไข Log (ถ้าส่ง sets มาจะแทนที่เซตเดิมทั้งหมด) แล้วคืน Log ล่าสุดพร้อมเซต
+	UpdateSessionLog(l *models.SessionLog) (*models.SessionLog, error)
+	UpdateSessionLogSet(scheduleID int, set *models.SessionLogSet) error
+	DeleteSessionLog(scheduleID, logID int) error
+	DeleteSessionLogSet(scheduleID, logID, setID int) error
+}
+
+type sessionService struct {
+	repo         repository.SessionRepository
+	exerciseRepo repository.ExerciseRepository
+	recordRepo   repository.PersonalRecordRepository
+	programRepo  repository.ProgramRepository
+	records      *recordRebuilder
+}
+
+func NewSessionService(repo repository.SessionRepository, exerciseRepo repository.ExerciseRepository, recordRepo repository.PersonalRecordRepository, programRepo repository.ProgramRepository) SessionService {
+	return &sessionService{repo: repo, exerciseRepo: exerciseRepo, recordRepo: recordRepo, programRepo: programRepo,
+		records: &recordRebuilder{sessions: repo, records: recordRepo}}
+}
+
+// CreateWorkoutLog ตรวจสอบ Schedule + ข้อมูลทุกเซต ตรวจหา PR ใหม่ แล้วบันทึกทั้งหมดใน Transaction เดียว
+func (s *sessionService) CreateWorkoutLog(scheduleID int, w *models.WorkoutLog) error {
+	schedule, err := s.repo.GetScheduleByID(scheduleID)
+	if err == sql.ErrNoRows {
+		return ErrScheduleNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if schedule.Status == "cancelled" {
+		return ErrScheduleCancelled
+	}
+
+	// ท่า/Block ต้องเป็นของเทรนเนอร์ของ Session (ผู้บันทึกอาจเป็นแอดมิน)
+	if err := s.validateWorkoutLog(schedule.TrainerID, w); err != nil {
+		return err
+	}
+
+	w.ScheduleID = scheduleID
+
+	// ตรวจหา PR ใหม่เทียบกับประวัติของลูกค้า (บันทึกพร้อม Log ใน Transaction เดียวกัน)
+	var exerciseIDs []int
+	for _, l := range w.Exercises {
+		if l.ExerciseID != nil {
+			exerciseIDs = append(exerciseIDs, *l.ExerciseID)
+		}
+	}
+	// นับเฉพาะ Session ก่อนหน้า: บันทึกย้อนหลังต้องไม่เทียบกับ Session ที่เกิดทีหลัง
+	history, err := s.recordRepo.GetRepMaxes(schedule.ClientID, exerciseIDs, &schedule.StartTime)
+	if err != nil {
+		return err
+	}
+	w.NewPRs = detectPersonalRecords(schedule.ClientID, schedule.StartTime, w, history)
+
+	// ท่าที่มีบันทึกตั้งแต่เวลานี้เป็นต้นไปอยู่แล้ว (บันทึกย้อนหลัง หรือ Log ซ้ำใน Session เดียวกัน)
+	// PR ของ Session เหล่านั้นอาจไม่ใช่ PR แล้ว ต้องคำนวณใหม่ทั้งท่าหลังบันทึก
+	rebuild := map[int]bool{}
+	for _, id := range exerciseIDs {
+		if rebuild[id] {
+			continue
+		}
+		later, err := s.repo.GetClientExerciseSets(schedule.ClientID, id, &schedule.StartTime, nil)
+		if err != nil {
+			return err
+		}
+		if len(later) > 0 {
+			rebuild[id] = true
+		}
+	}
+
+	if err := s.repo.CreateWorkoutLog(w); err != nil {
+		return err
+	}
+	if len(rebuild) == 0 {
+		return nil
+	}
+
+	prs := []models.PersonalRecord{}
+	for _, pr := range w.NewPRs {
+		if !rebuild[pr.ExerciseID] {
+			prs = append(prs, pr)
+		}
+	}
+	for _, id := range exerciseIDs {
+		if !rebuild[id] {
+			continue
+		}
+		delete(rebuild, id)
+		records, err := s.records.rebuild(schedule.ClientID, id)
+		if err != nil {
+			return err
+		}
+		for _, pr := range records {
+			if pr.ScheduleID == scheduleID {
+				prs = append(prs, pr)
+			}
+		}
+	}
+	w.NewPRs = prs
+	return nil
+}
+
+// UpdateSessionLog แก้ไขท่า/โน้ต/เซตของ Log ที่มีอยู่แล้วใน Schedule (l.ScheduleID, l.ID ต้องถูกกำหนดมาแล้ว)
+func (s *sessionService) UpdateSessionLog(l *models.SessionLog) (*models.SessionLog, error) {
+	old, err := s.repo.GetLogByID(l.ScheduleID, l.ID)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := s.repo.GetScheduleByID(l.ScheduleID)
+	if err == sql.ErrNoRows {
+		return nil, ErrScheduleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// เหมือน CreateWorkoutLog: ตรวจท่า/Block กับเทรนเนอร์ของ Session ไม่ใช่ผู้แก้ไข (อาจเป็นแอดมิน)
+	if err := s.checkExercise(schedule.TrainerID, l.ExerciseID); err != nil {
+		return nil, err
+	}
+	if l.Sets != nil {
+		if err := validateSets(l.Sets, ""); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkBlock(schedule.TrainerID, l.BlockID, l.Sets, ""); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateSessionLog(l); err != nil {
+		return nil, err
+	}
+	// ท่าเดิมและท่าใหม่ (ถ้าเปลี่ยนท่า) ต้องคำนวณ PR ใหม่ทั้งคู่
+	if err := s.refreshRecords(l.ScheduleID, old.ExerciseID, l.ExerciseID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetLogByID(l.ScheduleID, l.ID)
+}
+
+func (s *sessionService) UpdateSessionLogSet(scheduleID int, set *models.SessionLogSet) error {
+	if set.SetNumber == 0 {
+		return fmt.Errorf("%w: set_number is required", ErrInvalidWorkoutLog)
+	}
+	if msg := validateSet(set); msg != "" {
+		return fmt.Errorf("%w: %s", ErrInvalidWorkoutLog, msg)
+	}
+	if set.Round != nil {
+		l, err := s.repo.GetLogByID(scheduleID, set.SessionLogID)
+		if err != nil {
+			return err
+		}
+		if err := s.checkRounds(l.BlockID, []models.SessionLogSet{*set}, ""); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.UpdateSessionLogSet(scheduleID, set); err != nil {
+		return err
+	}
+	l, err := s.repo.GetLogByID(scheduleID, set.SessionLogID)
+	if err != nil {
+		return err
+	}
+	return s.refreshRecords(scheduleID, l.ExerciseID)
+}
+
+func (s *sessionService) DeleteSessionLog(scheduleID, logID int) error {
+	l, err := s.repo.GetLogByID(scheduleID, logID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteSessionLog(scheduleID, logID); err != nil {
+		return err
+	}
+	return s.refreshRecords(scheduleID, l.ExerciseID)
+}
+
+func (s *sessionService) DeleteSessionLogSet(scheduleID, logID, setID int) error {
+	l, err := s.repo.GetLogByID(scheduleID, logID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteSessionLogSet(scheduleID, logID, setID); err != nil {
+		return err
+	}
+	return s.refreshRecords(scheduleID, l.ExerciseID)
+}
+
+// refreshRecords คำนวณ PR ของลูกค้าใน Schedule นี้ใหม่สำหรับท่าที่ระบุ (nil = Log ที่ไม่ระบุท่า ข้ามไป)
+// ใช้หลังแก้/ลบเซต: PR เดิมอาจอ้างเซตที่ถูกลบ หรือ previous_value ของ PR ใน Session ถัดไปไม่ถูกต้องแล้ว
+func (s *sessionService) refreshRecords(scheduleID int, exerciseIDs ...*int) error {
+	schedule, err := s.repo.GetScheduleByID(scheduleID)
+	if err != nil {
+		return err
+	}
+	done := map[int]bool{}
+	for _, id := range exerciseIDs {
+		if id == nil || done[*id] {
+			continue
+		}
+		done[*id] = true
+		if _, err := s.records.rebuild(schedule.ClientID, *id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sessionService) validateWorkoutLog(trainerID int, w *models.WorkoutLog) error {
+	if len(w.Exercises) == 0 {
+		return fmt.Errorf("%w: at least one exercise is required", ErrInvalidWorkoutLog)
+	}
+
+	checked := map[int]bool{}
+	for i := range w.Exercises {
+		l := &w.Exercises[i]
+
+		if l.ExerciseID != nil && !checked[*l.ExerciseID] {
+			if err := s.checkExercise(trainerID, l.ExerciseID); err != nil {
+				return err
+			}
+			checked[*l.ExerciseID] = true
+		}
+
+		if l.Sets == nil {
+			l.Sets = []models.SessionLogSet{}
+		}
+		prefix := fmt.Sprintf("exercise #%d ", i+1)
+		if err := validateSets(l.Sets, prefix); err != nil {
+			return err
+		}
+		if err := s.checkBlock(trainerID, l.BlockID, l.Sets, prefix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkExercise ท่าฝึกต้องมีอยู่จริง และเป็นท่ากลางหรือท่าของเทรนเนอร์คนนี้ (nil = ไม่ระบุท่า)
+func (s *sessionService) checkExercise(trainerID int, exerciseID *int) error {
+	if exerciseID == nil {
+		return nil
+	}
+	if _, err := s.exerciseRepo.GetExerciseByID(*exerciseID, trainerID); err != nil {
+		if errors.Is(err, repository.ErrExerciseNotFound) {
+			return fmt.Errorf("%w: exercise %d not found", ErrInvalidWorkoutLog, *exerciseID)
+		}
+		return err
+	}
+	return nil
+}
+
+// checkBlock Block (ถ้าระบุ) ต้องอยู่ในโปรแกรมของเทรนเนอร์คนนี้ และรอบของทุกเซตต้องอยู่ในจำนวนรอบของ Block
+func (s *sessionService) checkBlock(trainerID int, blockID *int, sets []models.SessionLogSet, prefix string) error {
+	if blockID != nil {
+		_, owner, err := s.programRepo.GetBlockWithTrainer(*blockID)
+		if errors.Is(err, repository.ErrProgramBlockNotFound) || (err == nil && owner != trainerID) {
+			return fmt.Errorf("%w: %sblock %d not found", ErrInvalidWorkoutLog, prefix, *blockID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return s.checkRounds(blockID, sets, prefix)
+}
+
+// checkRounds round ใช้ได้เฉพาะ Log ที่มี Block และต้องไม่เกินจำนวนรอบ (AMRAP ไม่จำกัดรอบ)
+func (s *sessionService) checkRounds(blockID *int, sets []models.SessionLogSet, prefix string) error {
+	var block *models.ProgramBlock
+	for j, set := range sets {
+		if set.Round == nil {
+			continue
+		}
+		if blockID == nil {
+			return fmt.Errorf("%w: %sset #%d: round requires block_id", ErrInvalidWorkoutLog, prefix, j+1)
+		}
+		if *set.Round < 1 {
+			return fmt.Errorf("%w: %sset #%d: round must be positive", ErrInvalidWorkoutLog, prefix, j+1)
+		}
+		if block == nil {
+			b, _, err := s.programRepo.GetBlockWithTrainer(*blockID)
+			if err != nil {
+				return err
+			}
+			block = b
+		}
+		if block.BlockType != models.BlockAMRAP && *set.Round > block.Rounds {
+			return fmt.Errorf("%w: %sset #%d: round must not exceed %d", ErrInvalidWorkoutLog, prefix, j+1, block.Rounds)
+		}
+	}
+	return nil
+}
+
+// validateSets เติม set_number ที่ไม่ได้ส่งมาตามลำดับ และตรวจค่าของทุกเซต
+func validateSets(sets []models.SessionLogSet, prefix string) error {
+	for j := range sets {
+		set := &sets[j]
+		if set.SetNumber == 0 {
+			set.SetNumber = j + 1
+		}
+		if msg := validateSet(set); msg != "" {
+			return fmt.Errorf("%w: %sset #%d: %s", ErrInvalidWorkoutLog, prefix, j+1, msg)
+		}
+	}
+	return nil
+}
+
+func validateSet(set *models.SessionLogSet) string {
+	switch {
+	case set.SetNumber < 0:
+		return "set_number must be positive"
+	case set.WeightKg < 0:
+		return "weight_kg must not be negative"
+	case set.Reps < 0:
+		return "reps must not be negative"
+	case set.RPE < 0 || set.RPE > 10:
+		return "rpe must be between 0 and 10"
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"net/mail"
+	"strings"
+
+	"users/internal/models"
+	"users/internal/oidc"
+	"users/internal/repository"
+)
+
+var (
+	ErrIdentityEmailRequired = errors.New("identity provider did not return a valid email address")
+	// มีบัญชีอีเมลนี้อยู่แล้ว แต่ผู้ให้บริการไม่ได้ยืนยันว่าเป็นเจ้าของอีเมล (เชื่อมให้อัตโนมัติไม่ได้)
+	ErrIdentityConflict = errors.New("an account with this email already exists")
+)
+
+func (s *userService) LoginWithIdentity(identity oidc.Identity) (*TokenPair, error) {
+	user, err := s.identityRepo.GetUserByIdentity(identity.Provider, identity.Subject)
+	if errors.Is(err, repository.ErrIdentityNotFound) {
+		user, err = s.linkOrCreateIdentityUser(identity)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.requireVerification && !user.Verified {
+		return nil, ErrEmailNotVerified
+	}
+
+	familyID, err := generateOpaqueToken()
+	if err != nil {
+		return nil, errors.New("failed to create refresh token")
+	}
+	return s.issueTokens(user, familyID, nil)
+}
+
+// linkOrCreateIdentityUser Login ครั้งแรกด้วย identity นี้: เชื่อมกับบัญชีที่มีอีเมลเดียวกัน หรือสร้างบัญชีใหม่
+func (s *userService) linkOrCreateIdentityUser(identity oidc.Identity) (*models.User, error) {
+	if addr, err := mail.ParseAddress(identity.Email); err != nil || addr.Address != identity.Email {
+		return nil, ErrIdentityEmailRequired
+	}
+	link := &models.UserIdentity{Provider: identity.Provider, Subject: identity.Subject, Email: identity.Email}
+
+	existing, err := s.lookupByEmail(identity.Email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		// เชื่อมเฉพาะเมื่อผู้ให้บริการยืนยันอีเมลแล้ว ไม่อย่างนั้นใครก็สร้างบัญชีที่ issuer ด้วยอีเมลคนอื่นมายึดบัญชีได้
+		if !identity.EmailVerified {
+			return nil, ErrIdentityConflict
+		}
+		link.UserID = existing.ID
+		if err := s.identityRepo.LinkIdentity(link, true); err != nil {
+			return nil, err
+		}
+		existing.Verified = true
+		return existing, nil
+	}
+
+	name := identity.Name
+	if name == "" {
+		name = identity.Email[:strings.Index(identity.Email, "@")]
+	}
+	user, err := s.identityRepo.CreateUserWithIdentity(models.User{
+		Name:     name,
+		Email:    identity.Email,
+		Role:     "trainer", // Default role (เหมือน RegisterUser)
+		Verified: identity.EmailVerified,
+	}, link)
+	if err != nil {
+		return nil, err
+	}
+	if !user.Verified {
+		if err := s.sendVerification(user); err != nil {
+			log.Printf("failed to send verification email to user %d: %v", user.ID, err)
+		}
+	}
+	return user, nil
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"users/internal/models"
+	"users/internal/oidc"
+	"users/internal/repository"
+)
+
+// fakeUserRepo มีผู้ใช้ตามอีเมล (เมธอดอื่นของ UserRepository ไม่ถูกเรียกในการทดสอบนี้)
+type fakeUserRepo struct {
+	repository.UserRepository
+	byEmail map[string]*models.User
+}
+
+func (r *fakeUserRepo) GetUserByEmail(email string) (*models.User, error) {
+	if u, ok := r.byEmail[email]; ok {
+		copied := *u
+		return &copied, nil
+	}
+	return nil, errors.New("user not found")
+}
+
+type fakeIdentityRepo struct {
+	linked  []*models.UserIdentity
+	created []models.User
+}
+
+func (r *fakeIdentityRepo) GetUserByIdentity(provider, subject string) (*models.User, error) {
+	return nil, repository.ErrIdentityNotFound
+}
+
+func (r *fakeIdentityRepo) LinkIdentity(identity *models.UserIdentity, emailVerified bool) error {
+	r.linked = append(r.linked, identity)
+	return nil
+}
+
+func (r *fakeIdentityRepo) CreateUserWithIdentity(user models.User, identity *models.UserIdentity) (*models.User, error) {
+	user.ID = 100 + len(r.created)
+	r.created = append(r.created, user)
+	return &user, nil
+}
+
+func TestLinkOrCreateIdentityUser(t *testing.T) {
+	existing := &models.User{ID: 7, Name: "Somchai", Email: "somchai@example.com", Role: "trainer"}
+
+	tests := []struct {
+		name       string
+		identity   oidc.Identity
+		wantErr    error
+		wantLinked bool
+		wantUserID int
+	}{
+		{
+			name:     "existing email not verified by provider is not linked",
+			identity: oidc.Identity{Provider: "mock", Subject: "s1", Email: "somchai@example.com", EmailVerified: false},
+			wantErr:  ErrIdentityConflict,
+		},
+		{
+			name:       "existing email verified by provider is linked",
+			identity:   oidc.Identity{Provider: "mock", Subject: "s1", Email: "somchai@example.com", EmailVerified: true},
+			wantLinked: true,
+			wantUserID: 7,
+		},
+		{
+			name:       "new verified email creates an account",
+			identity:   oidc.Identity{Provider: "mock", Subject: "s2", Email: "new@example.com", EmailVerified: true, Name: "New"},
+			wantUserID: 100,
+		},
+		{
+			name:     "missing email",
+			identity: oidc.Identity{Provider: "mock", Subject: "s3"},
+			wantErr:  ErrIdentityEmailRequired,
+		},
+		{
+			name:     "display name instead of email",
+			identity: oidc.Identity{Provider: "mock", Subject: "s3", Email: "Somchai <somchai@example.com>", EmailVerified: true},
+			wantErr:  ErrIdentityEmailRequired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identities := &fakeIdentityRepo{}
+			s := &userService{
+				repo:         &fakeUserRepo{byEmail: map[string]*models.User{existing.Email: existing}},
+				identityRepo: identities,
+			}
+
+			user, err := s.linkOrCreateIdentityUser(tt.identity)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				if len(identities.linked) != 0 || len(identities.created) != 0 {
+					t.Fatalf("identity was stored despite error: linked %v, created %v", identities.linked, identities.created)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("linkOrCreateIdentityUser: %v", err)
+			}
+			if user.ID != tt.wantUserID {
+				t.Errorf("user id = %d, want %d", user.ID, tt.wantUserID)
+			}
+			if !user.Verified {
+				t.Error("user should be verified when the provider verified the email")
+			}
+			if linked := len(identities.linked) == 1; linked != tt.wantLinked {
+				t.Errorf("linked = %v, want %v", identities.linked, tt.wantLinked)
+			}
+			if tt.wantLinked && identities.linked[0].UserID != existing.ID {
+				t.Errorf("linked to user %d, want %d", identities.linked[0].UserID, existing.ID)
+			}
+		})
+	}
+}
@@ -2,6 +2,9 @@ package service
 
 import (
 	"errors"
+	"log"
+	"net/mail"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -9,7 +12,9 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 
+	"users/internal/listing"
 	"users/internal/models"
+	"users/internal/oidc"
 	"users/internal/repository"
 )
 
@@ -26,8 +31,34 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+// TokenPair คือ Access Token (JWT อายุสั้น) + Refresh Token (อายุยาว ใช้ขอ Access Token ใหม่)
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+
+	// อายุลิงก์ยืนยันอีเมล / รีเซ็ตรหัสผ่าน
+	VerifyEmailTokenTTL   = 24 * time.Hour
+	PasswordResetTokenTTL = time.Hour
+
+	// ความยาวรหัสผ่านขั้นต่ำ (สมัครเอง/รับคำเชิญ/รีเซ็ตรหัสผ่าน)
+	minPasswordLength = 8
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+	ErrInvalidEmail        = errors.New("invalid email address")
+	ErrEmailNotVerified    = errors.New("email address is not verified")
+	ErrPasswordTooShort    = errors.New("password must be at least 8 characters")
+)
+
 type UserService interface {
-	GetAllUsers() ([]models.User, error)
+	GetAllUsers(p *listing.Params) (*listing.Page[models.User], error)
 	GetUserByID(id int) (*models.User, error)
 	CreateUser(name, email string) (*models.User, error)
 	UpdateUser(id int, name, email string) (*models.User, error)
@@ -35,18 +66,40 @@ type UserService interface {
 
 	// Auth Methods
 	RegisterUser(req RegisterRequest) (*models.User, error)
-	LoginUser(req LoginRequest) (string, error)
+	LoginUser(req LoginRequest) (*TokenPair, error)
 	GetUserByEmail(email string) (*models.User, error)
+	RefreshTokens(refreshToken string) (*TokenPair, error)
+	Logout(refreshToken string) error
+
+	// ยืนยันอีเมล / รีเซ็ตรหัสผ่านด้วย token จากลิงก์ในอีเมล (ใช้ได้ครั้งเดียว)
+	VerifyEmail(token string) error
+	ResetPassword(token, newPassword string) error
+	// ส่งอีเมลใหม่ ไม่บอกว่ามีอีเมลนี้ในระบบหรือไม่ (ไม่พบ/ยืนยันแล้ว = สำเร็จเงียบๆ)
+	ResendVerification(email string) error
+	RequestPasswordReset(email string) error
+
+	// Login ผ่านผู้ให้บริการ OIDC (ID Token ตรวจแล้ว): เชื่อมบัญชีเดิมหรือสร้างบัญชีใหม่แบบไม่มีรหัสผ่าน
+	LoginWithIdentity(identity oidc.Identity) (*TokenPair, error)
 }
 
 type userService struct {
-	repo      repository.UserRepository
-	jwtSecret []byte
+	repo          repository.UserRepository
+	tokenRepo     repository.RefreshTokenRepository
+	userTokenRepo repository.UserTokenRepository
+	identityRepo  repository.IdentityRepository
+	mailer        Mailer
+	jwtSecret     []byte
+
+	// สำหรับสร้างลิงก์ในอีเมล
+	frontendURL string
+	// ต้องยืนยันอีเมลก่อน Login ด้วยรหัสผ่าน
+	requireVerification bool
 }
 
 // NewUserService สร้าง Service ใหม่ พร้อมรับ JWT Secret
 // (ในโค้ดจริงควรอ่าน JWT_SECRET จาก Config แต่นี่เรา Hardcode ไว้ก่อนเพื่อความง่าย)
-func NewUserService(repo repository.UserRepository) UserService {
+func NewUserService(repo repository.UserRepository, tokenRepo repository.RefreshTokenRepository, userTokenRepo repository.UserTokenRepository,
+	identityRepo repository.IdentityRepository, mailer Mailer, frontendURL string, requireVerification bool) UserService {
 	// อ่านค่า JWT_SECRET จาก Environment Variable
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
@@ -54,13 +107,19 @@ func NewUserService(repo repository.UserRepository) UserService {
 	}
 
 	return &userService{
-		repo:      repo,
-		jwtSecret: []byte(secret), // ใช้ค่าที่ได้จาก .env
+		repo:                repo,
+		tokenRepo:           tokenRepo,
+		userTokenRepo:       userTokenRepo,
+		identityRepo:        identityRepo,
+		mailer:              mailer,
+		jwtSecret:           []byte(secret), // ใช้ค่าที่ได้จาก .env
+		frontendURL:         strings.TrimRight(frontendURL, "/"),
+		requireVerification: requireVerification,
 	}
 }
 
-func (s *userService) GetAllUsers() ([]models.User, error) {
-	return s.repo.GetAll()
+func (s *userService) GetAllUsers(p *listing.Params) (*listing.Page[models.User], error) {
+	return s.repo.List(p)
 }
 
 func (s *userService) GetUserByID(id int) (*models.User, error) {
@@ -94,6 +153,15 @@ func (s *userService) DeleteUser(id int) error {
 // ---------------------------------------------------------
 
 func (s *userService) RegisterUser(req RegisterRequest) (*models.User, error) {
+	// 0. ตรวจรูปแบบอีเมล (ต้องเป็นที่อยู่ล้วนๆ ไม่มีชื่อนำหน้า)
+	req.Email = strings.TrimSpace(req.Email)
+	if addr, err := mail.ParseAddress(req.Email); err != nil || addr.Address != req.Email {
+		return nil, ErrInvalidEmail
+	}
+	if len(req.Password) < minPasswordLength {
+		return nil, ErrPasswordTooShort
+	}
+
 	// 1. Hash รหัสผ่าน
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), 10)
 	if err != nil {
@@ -115,6 +183,11 @@ func (s *userService) RegisterUser(req RegisterRequest) (*models.User, error) {
 		return nil, errors.New("failed to create user (email might already exist)")
 	}
 
+	// 4. ส่งอีเมลยืนยัน (ส่งไม่สำเร็จไม่ทำให้สมัครไม่สำเร็จ ขอส่งใหม่ได้ภายหลัง)
+	if err := s.sendVerification(createdUser); err != nil {
+		log.Printf("failed to send verification email to user %d: %v", createdUser.ID, err)
+	}
+
 	return createdUser, nil
 }
 
@@ -122,29 +195,169 @@ func (s *userService) GetUserByEmail(email string) (*models.User, error) {
 	return s.repo.GetUserByEmail(email)
 }
 
-func (s *userService) LoginUser(req LoginRequest) (string, error) {
+func (s *userService) LoginUser(req LoginRequest) (*TokenPair, error) {
 	// 1. ดึง User จาก DB
 	user, err := s.repo.GetUserByEmail(req.Email)
 	if err != nil {
-		return "", errors.New("invalid email or password")
+		return nil, errors.New("invalid email or password")
+	}
+
+	// 2. เทียบรหัสผ่าน (บัญชีที่สมัครผ่าน Google/OIDC ไม่มีรหัสผ่าน: hash ว่าง เทียบไม่ผ่านเสมอ)
+	if req.Password == "" || user.PasswordHash == "" {
+		return nil, errors.New("invalid email or password")
+	}
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
+	if err != nil {
+		return nil, errors.New("invalid email or password")
+	}
+	// ตรวจหลังรหัสผ่านถูกต้องแล้ว (ไม่บอกสถานะบัญชีกับคนที่ไม่รู้รหัสผ่าน)
+	if s.requireVerification && !user.Verified {
+		return nil, ErrEmailNotVerified
+	}
+
+	// 3. สร้าง Access Token + Refresh Token (family ใหม่ต่อการ Login หนึ่งครั้ง)
+	familyID, err := generateOpaqueToken()
+	if err != nil {
+		return nil, errors.New("failed to create refresh token")
 	}
+	return s.issueTokens(user, familyID, nil)
+}
 
-	// 2. เทียบรหัสผ่าน
-	// กรณีที่ 1: Login ปกติ (มี Password ส่งมา) -> ต้องเช็ค bcrypt
-	if req.Password != "" {
-		err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
-		if err != nil {
-			return "", errors.New("invalid email or password")
+// RefreshTokens หมุน (rotate) Refresh Token: ยกเลิกตัวเดิม แล้วออกคู่ใหม่ใน family เดิม
+// ถ้ามีการนำ token ที่ถูกยกเลิกไปแล้วกลับมาใช้ จะถือว่าถูกขโมย และยกเลิกทั้ง family
+func (s *userService) RefreshTokens(refreshToken string) (*TokenPair, error) {
+	stored, err := s.tokenRepo.GetRefreshTokenByHash(hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+			return nil, ErrInvalidRefreshToken
 		}
+		return nil, err
 	}
-	// กรณีที่ 2: Google Login (Password เป็น "") -> ข้ามการเช็ค (ถือว่า Google ยืนยันมาแล้ว)
 
-	// 3. สร้าง Access Token (JWT)
+	if stored.RevokedAt != nil {
+		if err := s.tokenRepo.RevokeRefreshTokenFamily(stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// ดึง User ใหม่ทุกครั้ง เพื่อให้ role/name ใน Access Token เป็นค่าล่าสุด
+	user, err := s.repo.GetByID(stored.UserID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	pair, err := s.issueTokens(user, stored.FamilyID, &stored.ID)
+	if errors.Is(err, repository.ErrRefreshTokenRevoked) {
+		// มี request อื่นใช้ token นี้ไปก่อนหน้าเพียงเสี้ยววินาที -> ถือเป็นการใช้ซ้ำเช่นกัน
+		if err := s.tokenRepo.RevokeRefreshTokenFamily(stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	return pair, err
+}
+
+// Logout ยกเลิก Refresh Token ฝั่ง Server
+func (s *userService) Logout(refreshToken string) error {
+	if refreshToken == "" {
+		return nil
+	}
+	return s.tokenRepo.RevokeRefreshToken(hashToken(refreshToken))
+}
+
+func (s *userService) VerifyEmail(token string) error {
+	if token == "" {
+		return repository.ErrUserTokenInvalid
+	}
+	_, err := s.userTokenRepo.VerifyEmail(hashToken(token))
+	return err
+}
+
+func (s *userService) ResetPassword(token, newPassword string) error {
+	if len(newPassword) < minPasswordLength {
+		return ErrPasswordTooShort
+	}
+	if token == "" {
+		return repository.ErrUserTokenInvalid
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), 10)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+	_, err = s.userTokenRepo.ResetPassword(hashToken(token), string(hashedPassword))
+	return err
+}
+
+func (s *userService) ResendVerification(email string) error {
+	user, err := s.lookupByEmail(email)
+	if err != nil || user == nil || user.Verified {
+		return err
+	}
+	return s.sendVerification(user)
+}
+
+func (s *userService) RequestPasswordReset(email string) error {
+	user, err := s.lookupByEmail(email)
+	if err != nil || user == nil {
+		return err
+	}
+	token, err := s.createUserToken(user.ID, models.TokenPurposeResetPassword, PasswordResetTokenTTL)
+	if err != nil {
+		return err
+	}
+	return s.mailer.SendPasswordResetEmail(user, s.frontendURL+"/reset-password?token="+url.QueryEscape(token))
+}
+
+// lookupByEmail คืน nil (ไม่ใช่ error) เมื่อไม่พบผู้ใช้
+func (s *userService) lookupByEmail(email string) (*models.User, error) {
+	user, err := s.repo.GetUserByEmail(strings.TrimSpace(email))
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *userService) sendVerification(user *models.User) error {
+	token, err := s.createUserToken(user.ID, models.TokenPurposeVerifyEmail, VerifyEmailTokenTTL)
+	if err != nil {
+		return err
+	}
+	return s.mailer.SendVerificationEmail(user, s.frontendURL+"/verify-email?token="+url.QueryEscape(token))
+}
+
+// createUserToken สร้าง token ใหม่ (เก็บเฉพาะ hash) คืนค่า token ดิบสำหรับใส่ในลิงก์
+func (s *userService) createUserToken(userID int, purpose string, ttl time.Duration) (string, error) {
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	err = s.userTokenRepo.CreateUserToken(&models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// issueTokens สร้าง Access Token (JWT) และ Refresh Token ใหม่
+// ถ้า rotateFrom ไม่เป็น nil จะยกเลิก Refresh Token เดิมใน Transaction เดียวกัน
+func (s *userService) issueTokens(user *models.User, familyID string, rotateFrom *int) (*TokenPair, error) {
 	accessClaims := jwt.MapClaims{
 		"user_id": user.ID,
 		"name":    user.Name,
 		"role":    user.Role,
-		"exp":     time.Now().Add(time.Minute * 15).Unix(), // หมดอายุใน 15 นาที
+		"exp":     time.Now().Add(AccessTokenTTL).Unix(), // หมดอายุใน 15 นาที
 	}
 
 	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
@@ -152,8 +365,27 @@ func (s *userService) LoginUser(req LoginRequest) (string, error) {
 	// ใช้ s.jwtSecret ที่อ่านมาจาก .env ใน NewUserService
 	accessString, err := accessToken.SignedString(s.jwtSecret)
 	if err != nil {
-		return "", errors.New("failed to create access token")
+		return nil, errors.New("failed to create access token")
+	}
+
+	refreshString, err := generateOpaqueToken()
+	if err != nil {
+		return nil, errors.New("failed to create refresh token")
+	}
+	next := &models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refreshString),
+		ExpiresAt: time.Now().Add(RefreshTokenTTL),
+	}
+	if rotateFrom != nil {
+		err = s.tokenRepo.RotateRefreshToken(*rotateFrom, next)
+	} else {
+		err = s.tokenRepo.CreateRefreshToken(next)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return accessString, nil
+	return &TokenPair{AccessToken: accessString, RefreshToken: refreshString}, nil
 }
@@ -0,0 +1,23 @@
+package service
+
+import "time"
+
+// runEvery เรียก fn ทันทีหนึ่งครั้ง แล้วทุก interval ใน goroutine แยก คืนฟังก์ชันสำหรับหยุด
+func runEvery(interval time.Duration, fn func()) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		defer ticker.Stop()
+		fn()
+		for {
+			select {
+			case <-ticker.C:
+				fn()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}